@@ -1,14 +1,17 @@
 #!/bin/sh
 set -e
 
-# マイグレーション
-echo "=== Running goose migration ==="
-/goose -dir /migrations mysql "$DB_USER:$DB_PASSWORD@tcp($DB_HOST:$DB_PORT)/$DB_NAME?parseTime=true" up
+# ワーカー（/main worker）はマイグレーション・シーダーをAPI側に任せる
+if [ "$1" != "worker" ]; then
+  # マイグレーション
+  echo "=== Running goose migration ==="
+  /goose -dir /migrations mysql "$DB_USER:$DB_PASSWORD@tcp($DB_HOST:$DB_PORT)/$DB_NAME?parseTime=true" up
 
-# シーダー
-echo "=== Running seeder ==="
-/seeder
+  # シーダー
+  echo "=== Running seeder ==="
+  /seeder
+fi
 
 # 本体起動
 echo "=== Starting main app ==="
-exec /main
\ No newline at end of file
+exec /main "$@"
\ No newline at end of file
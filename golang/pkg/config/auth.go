@@ -0,0 +1,57 @@
+// 認証まわり（メールアドレスの確認・パスワードの再設定・ログイン試行の制限・2段階認証等）の設定の読み込み
+package config
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+type AuthConfig struct {
+	Auth struct {
+		// メールアドレス確認等のトークンの署名鍵（十分に長いランダム文字列）
+		TokenSigningKey string `yaml:"token_signing_key"`
+		// ログインで発行するJWT（HS256）の署名鍵（32文字以上のランダム文字列。未設定では起動しない）
+		JWTSigningKey string `yaml:"jwt_signing_key"`
+		// X-Real-IPを信用するリバースプロキシ（CIDRまたはIPアドレス）。空なら接続元のアドレスをクライアントのIPアドレスとする
+		TrustedProxies    []string `yaml:"trusted_proxies"`
+		EmailVerification struct {
+			// 確認メールのリンク先（?token=<トークン>を付与。フロントエンドからPOST /api/v1/signup/verifyを呼び出す）
+			URL                   string `yaml:"url"`
+			TTLHours              int    `yaml:"ttl_hours"`
+			ResendCooldownSeconds int    `yaml:"resend_cooldown_seconds"`
+		} `yaml:"email_verification"`
+		PasswordReset struct {
+			// パスワード再設定メールのリンク先（?token=<トークン>を付与。フロントエンドからPOST /api/v1/password/resetを呼び出す）
+			URL        string `yaml:"url"`
+			TTLMinutes int    `yaml:"ttl_minutes"`
+		} `yaml:"password_reset"`
+		LoginThrottle struct {
+			// 失敗回数・禁止状態の保存先: "memory"（既定。単一インスタンス用）/ "db"（複数インスタンス構成用）
+			Store              string `yaml:"store"`
+			WindowMinutes      int    `yaml:"window_minutes"`
+			AccountMaxFailures int    `yaml:"account_max_failures"`
+			IPMaxFailures      int    `yaml:"ip_max_failures"`
+			LockoutSeconds     int    `yaml:"lockout_seconds"`
+			MaxLockoutMinutes  int    `yaml:"max_lockout_minutes"`
+		} `yaml:"login_throttle"`
+		MFA struct {
+			// 認証アプリに表示する発行者名
+			Issuer string `yaml:"issuer"`
+			// ログインの2段階目（MFAトークン）の有効期限（分）
+			ChallengeTTLMinutes int `yaml:"challenge_ttl_minutes"`
+		} `yaml:"mfa"`
+	} `yaml:"auth"`
+}
+
+func LoadAuthConfig(path string) (*AuthConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg AuthConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
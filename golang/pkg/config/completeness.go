@@ -0,0 +1,34 @@
+// 職務経歴書の充実度スコアのルール設定の読み込み
+package config
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CompletenessConfigはルールIDごとの上書き設定（未指定の項目は既定値のまま）
+type CompletenessConfig struct {
+	Completeness struct {
+		Rules map[string]CompletenessRuleConfig `yaml:"rules"`
+	} `yaml:"completeness"`
+}
+
+type CompletenessRuleConfig struct {
+	Enabled   *bool `yaml:"enabled"`
+	Weight    *int  `yaml:"weight"`
+	MinLength *int  `yaml:"min_length"`
+	MinCount  *int  `yaml:"min_count"`
+}
+
+func LoadCompletenessConfig(path string) (*CompletenessConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg CompletenessConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
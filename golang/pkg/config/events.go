@@ -0,0 +1,27 @@
+// イベント配信（SSE）の設定の読み込み
+package config
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+type EventsConfig struct {
+	Events struct {
+		// 0より大きければ、その秒数ごとにDBを確認して他のインスタンスのイベントも配信する（複数インスタンス構成用）
+		PollIntervalSeconds int `yaml:"poll_interval_seconds"`
+	} `yaml:"events"`
+}
+
+func LoadEventsConfig(path string) (*EventsConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg EventsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,38 @@
+// メール送信設定の読み込み
+package config
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+type MailConfig struct {
+	Mail struct {
+		Driver string `yaml:"driver"` // "file"（開発用）または "smtp"
+		From   string `yaml:"from"`
+		File   struct {
+			Dir string `yaml:"dir"`
+		} `yaml:"file"`
+		SMTP struct {
+			Host           string `yaml:"host"`
+			Port           int    `yaml:"port"`
+			Username       string `yaml:"username"`
+			Password       string `yaml:"password"`
+			RequireTLS     bool   `yaml:"require_tls"`
+			TimeoutSeconds int    `yaml:"timeout_seconds"`
+		} `yaml:"smtp"`
+	} `yaml:"mail"`
+}
+
+func LoadMailConfig(path string) (*MailConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg MailConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
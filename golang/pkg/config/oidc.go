@@ -0,0 +1,42 @@
+// OpenID Connectによる外部IdPでのログインの設定の読み込み
+package config
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+type OIDCConfig struct {
+	OIDC struct {
+		Providers []OIDCProviderConfig `yaml:"providers"`
+	} `yaml:"oidc"`
+}
+
+type OIDCProviderConfig struct {
+	// URLに使用する名前（/api/v1/oidc/<name>/...）
+	Name        string `yaml:"name"`
+	DisplayName string `yaml:"display_name"`
+	// IdPのIssuer（<issuer>/.well-known/openid-configuration からエンドポイントを取得）
+	Issuer       string `yaml:"issuer"`
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	// IdPに登録したリダイレクト先（GET /api/v1/oidc/<name>/callback、またはそこに?code=&state=を渡すフロントエンドのページ）
+	RedirectURL string `yaml:"redirect_url"`
+	// 未指定なら openid email profile
+	Scopes []string `yaml:"scopes"`
+	// IdPがemail_verifiedを返さない場合に、IdPのメールアドレスを確認済みとして扱う（社内IdP等、信頼できる場合のみ）
+	TrustEmail bool `yaml:"trust_email"`
+}
+
+func LoadOIDCConfig(path string) (*OIDCConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg OIDCConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
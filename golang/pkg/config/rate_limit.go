@@ -0,0 +1,45 @@
+// APIのレート制限の設定の読み込み
+package config
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+type RateLimitConfig struct {
+	RateLimit struct {
+		// falseならレート制限を行わない
+		Enabled *bool `yaml:"enabled"`
+		// バケットの保存先: "memory"（既定。単一インスタンス用）/ "db"（複数インスタンス構成用）
+		Store string `yaml:"store"`
+		// policiesに該当しないルートの制限
+		Default RateLimitPolicyConfig `yaml:"default"`
+		// ルートごとの制限（上から順に最初に一致したもの）
+		Policies []RateLimitPolicyConfig `yaml:"policies"`
+	} `yaml:"rate_limit"`
+}
+
+type RateLimitPolicyConfig struct {
+	// バケットの名前（ポリシーごとに一意）
+	Name string `yaml:"name"`
+	// HTTPメソッド（未指定なら全て）
+	Method string `yaml:"method"`
+	// ルートのパス（/api/v1/resume/:id のように登録時のパターンで指定。末尾の*は前方一致）
+	Path string `yaml:"path"`
+	// 1分あたりのリクエスト数（トークンの補充の速さ）と、連続して受け付けるリクエスト数（バケットの容量）
+	RequestsPerMinute float64 `yaml:"requests_per_minute"`
+	Burst             int     `yaml:"burst"`
+}
+
+func LoadRateLimitConfig(path string) (*RateLimitConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg RateLimitConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
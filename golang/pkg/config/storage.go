@@ -0,0 +1,41 @@
+// 添付ファイルの保存先（オブジェクトストレージ）設定の読み込み
+package config
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+type StorageConfig struct {
+	Storage struct {
+		Driver        string   `yaml:"driver"` // "local" または "s3"
+		MaxSizeMB     int      `yaml:"max_size_mb"`
+		AllowedTypes  []string `yaml:"allowed_types"`
+		URLTTLMinutes int      `yaml:"url_ttl_minutes"`
+		SigningKey    string   `yaml:"signing_key"` // ローカル保存時のダウンロードURL署名鍵
+		Local         struct {
+			Root string `yaml:"root"`
+		} `yaml:"local"`
+		S3 struct {
+			Endpoint     string `yaml:"endpoint"`
+			Region       string `yaml:"region"`
+			Bucket       string `yaml:"bucket"`
+			AccessKey    string `yaml:"access_key"`
+			SecretKey    string `yaml:"secret_key"`
+			UsePathStyle bool   `yaml:"use_path_style"`
+		} `yaml:"s3"`
+	} `yaml:"storage"`
+}
+
+func LoadStorageConfig(path string) (*StorageConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg StorageConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,27 @@
+// access_token_dto.go: パーソナルアクセストークンの入出力用DTO
+package dto
+
+// パーソナルアクセストークンの発行
+type AccessTokenCreateRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"` // "resume:read" / "resume:write"
+	// 有効期限（日数、1〜365）。省略時は30日
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+// AccessTokenDTOは、パーソナルアクセストークンをAPI層でやり取りするためのDTOです。
+// ドメイン層の [`PersonalAccessToken`](services/hidden_waza/internal/domain/personal_access_token.go) から変換されます。
+type AccessTokenDTO struct {
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	TokenPrefix string   `json:"token_prefix"`
+	Scopes      []string `json:"scopes"`
+	// トークン。発行時のみ返却
+	Token      string  `json:"token,omitempty"`
+	ExpiresAt  string  `json:"expires_at"`
+	LastUsedAt *string `json:"last_used_at"`
+	LastUsedIP string  `json:"last_used_ip"`
+	RevokedAt  *string `json:"revoked_at"`
+	Active     bool    `json:"active"`
+	CreatedAt  string  `json:"created_at"`
+}
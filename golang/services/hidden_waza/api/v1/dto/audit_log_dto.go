@@ -0,0 +1,37 @@
+// audit_log_dto.go: 監査ログの入出力用DTO
+package dto
+
+import "encoding/json"
+
+// AuditLogDTOは、監査ログのエントリーをAPI層で返すためのDTOです。
+// ドメイン層の [`AuditLog`](services/hidden_waza/internal/domain/audit_log.go) から変換されます。
+type AuditLogDTO struct {
+	ID           uint            `json:"id"`
+	Seq          int64           `json:"seq"`
+	Action       string          `json:"action"`
+	Outcome      string          `json:"outcome"`
+	ActorUserID  *uint           `json:"actor_user_id"`
+	ActorTokenID *uint           `json:"actor_token_id,omitempty"`
+	TargetType   string          `json:"target_type"`
+	TargetID     string          `json:"target_id"`
+	IP           string          `json:"ip"`
+	UserAgent    string          `json:"user_agent"`
+	RequestID    string          `json:"request_id"`
+	BeforeHash   string          `json:"before_hash,omitempty"`
+	AfterHash    string          `json:"after_hash,omitempty"`
+	Details      json.RawMessage `json:"details"`
+	PrevHash     string          `json:"prev_hash"`
+	Hash         string          `json:"hash"`
+	CreatedAt    string          `json:"created_at"`
+}
+
+// 監査ログの一覧（next_before_idを次のページのbefore_idに指定する。最後のページでは省略）
+type AuditLogListResponse struct {
+	Entries      []AuditLogDTO `json:"entries"`
+	NextBeforeID *uint         `json:"next_before_id,omitempty"`
+}
+
+// ユーザーの権限の変更
+type UserRoleRequest struct {
+	Role string `json:"role"`
+}
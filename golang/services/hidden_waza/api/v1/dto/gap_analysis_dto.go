@@ -0,0 +1,16 @@
+// gap_analysis_dto.go: スキルギャップ分析の入力用DTO
+package dto
+
+// SkillTargetDTOは、目標ロールが求めるスキル1件をAPI層でやり取りするためのDTOです。
+// MinLevelはSkillDTO.Levelと同様に別名（"上級"等）も受け付けます。
+type SkillTargetDTO struct {
+	Type     string `json:"type"`
+	MasterID uint   `json:"master_id"`
+	MinYears int    `json:"min_years"`
+	MinLevel string `json:"min_level"`
+}
+
+// GapAnalysisRequestは、スキルギャップ分析の目標スキルプロファイルです。
+type GapAnalysisRequest struct {
+	Targets []SkillTargetDTO `json:"targets"`
+}
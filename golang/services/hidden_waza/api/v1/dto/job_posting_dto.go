@@ -0,0 +1,26 @@
+// job_posting_dto.go: 求人の入出力用DTO
+package dto
+
+// JobRequirementDTOは、求人が求めるスキル要件をAPI層でやり取りするためのDTOです。
+// MinLevelはSkillDTO.Levelと同様に別名（"上級"等）も受け付けます。
+type JobRequirementDTO struct {
+	Type     string `json:"type"`
+	MasterID uint   `json:"master_id"`
+	MinYears int    `json:"min_years"`
+	MinLevel string `json:"min_level"`
+	Required bool   `json:"required"`
+}
+
+// JobPostingDTOは、求人をAPI層でやり取りするためのDTOです。
+// ドメイン層の [`JobPosting`](services/hidden_waza/internal/domain/job_posting.go) と相互変換されます。
+type JobPostingDTO struct {
+	ID           uint                `json:"id"`
+	PosterID     uint                `json:"poster_id"`
+	Title        string              `json:"title"`
+	Company      string              `json:"company"`
+	Description  string              `json:"description"`
+	Status       string              `json:"status"`
+	Requirements []JobRequirementDTO `json:"requirements"`
+	CreatedAt    string              `json:"created_at"`
+	UpdatedAt    string              `json:"updated_at"`
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+
 // DTO（Data Transfer Object）は、API層とドメイン層（ビジネスロジック層）の間でデータを受け渡すための構造体です。
 // このファイルのDTOは、主にHTTPリクエスト/レスポンスのJSONデータとGoのドメインモデル（domainパッケージの構造体）の変換に利用されます。
 // 例えば、APIで受け取ったJSONをDTOにデコードし、DTOからドメインモデル（services/hidden_waza/internal/domain/resume.go等）へ変換してDB操作やビジネスロジックに渡します。
@@ -8,35 +10,71 @@ package dto
 
 // SkillDTOは、スキル情報をAPI層でやり取りするためのDTOです。
 // ドメイン層の [`Skill`](services/hidden_waza/internal/domain/resume.go:11) と相互変換されます。
+// Levelは入力時に別名（"上級"・"senior"等）も受け付け、レスポンスでは正規コード（beginner/intermediate/advanced/expert）を返します。
+// LevelRank（1〜4）・LevelLabel（表示言語のラベル）はレスポンスのみです。
 type SkillDTO struct {
-	Type     string `json:"type"`
-	MasterID uint   `json:"master_id"`
-	Level    string `json:"level"`
-	Years    int    `json:"years"`
+	Type       string `json:"type"`
+	MasterID   uint   `json:"master_id"`
+	Level      string `json:"level"`
+	Years      int    `json:"years"`
+	LevelRank  int    `json:"level_rank,omitempty"`
+	LevelLabel string `json:"level_label,omitempty"`
 }
 
 // ExperienceDTOは、職務経歴情報をAPI層でやり取りするためのDTOです。
 // ドメイン層の [`Experience`](services/hidden_waza/internal/domain/resume.go:12) と相互変換されます。
+// 日付は "YYYY-MM"（年月）または "YYYY-MM-DD"、在職中の終了日は "present"（入力時は空文字も可）です。
+// Translationsは言語コード（"ja"/"en"）をキーとした翻訳です。
 type ExperienceDTO struct {
-	Company      string `json:"company"`
-	Position     string `json:"position"`
-	StartDate    string `json:"start_date"`
-	EndDate      string `json:"end_date"`
-	Description  string `json:"description"`
-	PortfolioURL string `json:"portfolio_url"`
+	ID           uint                                `json:"id,omitempty"`
+	Company      string                              `json:"company"`
+	Position     string                              `json:"position"`
+	StartDate    string                              `json:"start_date"`
+	EndDate      string                              `json:"end_date"`
+	Description  string                              `json:"description"`
+	PortfolioURL string                              `json:"portfolio_url"`
+	Translations map[string]ExperienceTranslationDTO `json:"translations,omitempty"`
+	Skills       []ExperienceSkillDTO                `json:"skills,omitempty"`
+	// ポートフォリオURLの確認結果（レスポンスのみ、未確認ならなし）
+	LinkCheck *domain.LinkCheck `json:"link_check,omitempty"`
+}
+
+// ExperienceSkillDTOは、職歴で使用したスキル（マスタ参照）をAPI層でやり取りするためのDTOです。
+// ドメイン層の [`ExperienceSkill`](services/hidden_waza/internal/domain/experience_skill.go) と相互変換されます。
+type ExperienceSkillDTO struct {
+	Type     string `json:"type"`
+	MasterID uint   `json:"master_id"`
 }
 
 // ResumeDTOは、職務経歴書全体をAPI層でやり取りするためのDTOです。
 // ドメイン層の [`Resume`](services/hidden_waza/internal/domain/resume.go:6) と相互変換されます。
 // 変換処理は [`resume_handler.go`](services/hidden_waza/internal/handler/resume_handler.go) のconvertSkillDTOs/convertExperienceDTOs等で実装されています。
+// レスポンスのTitle/Summary等はLangの言語で解決済みの値、Translationsは言語別の登録値です。
 type ResumeDTO struct {
-	ID          uint            `json:"id"`
-	UserID      uint            `json:"user_id"`
-	Title       string          `json:"title"`
-	Summary     string          `json:"summary"`
-	Skills      []SkillDTO      `json:"skills"`
-	Experiences []ExperienceDTO `json:"experiences"`
-	CreatedAt   string          `json:"created_at"`
-	UpdatedAt   string          `json:"updated_at"`
-	Verified    bool            `json:"verified"`
+	ID           uint                            `json:"id"`
+	UserID       uint                            `json:"user_id"`
+	Title        string                          `json:"title"`
+	Summary      string                          `json:"summary"`
+	Skills       []SkillDTO                      `json:"skills"`
+	Experiences  []ExperienceDTO                 `json:"experiences"`
+	CreatedAt    string                          `json:"created_at"`
+	UpdatedAt    string                          `json:"updated_at"`
+	Verified     bool                            `json:"verified"`
+	Lang         string                          `json:"lang,omitempty"`
+	Translations map[string]ResumeTranslationDTO `json:"translations,omitempty"`
+	// ?include=metrics 指定時のみ付与される職歴の集計結果
+	Metrics *domain.CareerMetrics `json:"metrics,omitempty"`
+	// 充実度スコアと改善提案（登録・更新時は記録したリビジョン、取得時は ?include=completeness 指定時のみ）
+	Completeness *domain.CompletenessScore `json:"completeness,omitempty"`
+}
+
+// 職務経歴書の検証済みの変更（検証担当者・管理者のみ）
+type ResumeVerifyRequest struct {
+	Verified *bool `json:"verified"`
+}
+
+// 職務経歴書の検証済みの変更の結果
+type ResumeVerifyResponse struct {
+	ID       uint `json:"id"`
+	Verified bool `json:"verified"`
 }
@@ -0,0 +1,8 @@
+// skill_level_dto.go: スキルレベル段階一覧用DTO
+package dto
+
+type SkillLevelDTO struct {
+	Code  string `json:"code"`
+	Rank  int    `json:"rank"`
+	Label string `json:"label"`
+}
@@ -0,0 +1,35 @@
+// translation_dto.go: 多言語（ja/en）翻訳の入出力用DTO
+package dto
+
+// ResumeTranslationDTOは、ResumeのTitle/Summaryの1言語分の翻訳です。
+// ResumeDTO.Translationsに言語コードをキーとして格納されます。
+type ResumeTranslationDTO struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+}
+
+// ExperienceTranslationDTOは、職歴のPosition/Descriptionの1言語分の翻訳です。
+type ExperienceTranslationDTO struct {
+	Position    string `json:"position"`
+	Description string `json:"description"`
+}
+
+// ExperienceLocaleDTOは、言語単位の翻訳更新時に職歴IDを指定して翻訳を渡すためのDTOです。
+type ExperienceLocaleDTO struct {
+	ExperienceID uint   `json:"experience_id"`
+	Position     string `json:"position"`
+	Description  string `json:"description"`
+}
+
+// ResumeLocaleRequestは、PUT /api/v1/resume/:id/translations/:lang のリクエストです。
+// 指定言語の翻訳のみを登録/更新し、他言語の翻訳には影響しません。
+type ResumeLocaleRequest struct {
+	Title       string                `json:"title"`
+	Summary     string                `json:"summary"`
+	Experiences []ExperienceLocaleDTO `json:"experiences"`
+}
+
+// MasterTranslationRequestは、PUT /api/v1/admin/masters/:type/:id/translations/:lang のリクエストです。
+type MasterTranslationRequest struct {
+	Name string `json:"name"`
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+import (
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+)
 
 type UserRegisterRequest struct {
 	Username string       `json:"username"`
@@ -9,9 +13,10 @@ type UserRegisterRequest struct {
 }
 
 type UserRegisterResponse struct {
-	ID       uint         `json:"id"`
-	Username string       `json:"username"`
-	Email    domain.Email `json:"email"`
+	ID          uint         `json:"id"`
+	Username    string       `json:"username"`
+	Email       domain.Email `json:"email"`
+	EmailStatus string       `json:"email_status"` // "pending" / "verified"
 }
 
 type UserLoginRequest struct {
@@ -20,8 +25,79 @@ type UserLoginRequest struct {
 }
 
 type UserLoginResponse struct {
-	ID       uint         `json:"id"`
-	Username string       `json:"username"`
-	Email    domain.Email `json:"email"`
-	Token    string       `json:"token"`
+	ID          uint         `json:"id"`
+	Username    string       `json:"username"`
+	Email       domain.Email `json:"email"`
+	EmailStatus string       `json:"email_status"`
+	Token       string       `json:"token"`
+}
+
+// メールアドレスの確認（確認メールのリンクのトークン）
+type EmailVerifyRequest struct {
+	Token string `json:"token"`
+}
+
+// パスワード再設定メールの送信
+type PasswordForgotRequest struct {
+	Email domain.Email `json:"email"`
+}
+
+// パスワードの再設定（再設定メールのリンクのトークンと新しいパスワード）
+type PasswordResetRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// ログインの禁止の解除（管理API。指定したもののみ解除）
+type LoginUnlockRequest struct {
+	Email domain.Email `json:"email"`
+	IP    string       `json:"ip"`
+}
+
+// パスワードの照合後、2段階目が必要な場合のログインのレスポンス（JWTの代わりにMFAトークンを返す）
+type MFAChallengeResponse struct {
+	MFARequired bool `json:"mfa_required"`
+	// POST /api/v1/login/mfa・2段階認証の登録（X-MFA-Tokenヘッダー）に使用
+	MFAToken string `json:"mfa_token"`
+	// 2段階認証が登録済みか（falseなら権限により必須のため、登録してからログインする）
+	MFAEnrolled bool      `json:"mfa_enrolled"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// ログインの2段階目（MFAトークンとTOTPのコードまたはリカバリーコード）
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+// TOTPのコード（またはリカバリーコード）
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// 2段階認証の状態
+type MFAStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"` // 権限により必須
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// 2段階認証の登録の開始（認証アプリにsecretを入力するか、uri・QRコードを読み取る）
+type MFAEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	// QRコードのPNG（data:image/png;base64,...）
+	QRCode string `json:"qr_code"`
+}
+
+// リカバリーコード（発行時のみ表示）
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	// MFAトークンで登録した場合のみ、ログインのJWT
+	Token string `json:"token,omitempty"`
+}
+
+// 外部IdPのアカウントの紐付けの開始（IdPのログイン画面のURL）
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
 }
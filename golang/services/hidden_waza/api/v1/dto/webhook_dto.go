@@ -0,0 +1,16 @@
+// webhook_dto.go: Webhook購読の入出力用DTO
+package dto
+
+// WebhookSubscriptionDTOは、Webhookの購読をAPI層でやり取りするためのDTOです。
+// ドメイン層の [`WebhookSubscription`](services/hidden_waza/internal/domain/webhook_subscription.go) と相互変換されます。
+type WebhookSubscriptionDTO struct {
+	ID          uint     `json:"id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"` // "*"は全イベント
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"` // 省略時は有効
+	// 署名の鍵。作成時のみ返却（作成時に指定しなければ生成）
+	Secret    string `json:"secret,omitempty"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
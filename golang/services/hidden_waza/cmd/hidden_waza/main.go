@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
 	"github.com/requohylla/hidden-waza/pkg/config"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
 	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/handler"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/mail"
 	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/storage"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
@@ -31,46 +39,389 @@ func main() {
 		log.Fatal("DB接続失敗: ", err)
 	}
 
+	// hidden_waza worker: バックグラウンドジョブのワーカーとして起動
+	if len(os.Args) > 1 && os.Args[1] == "worker" {
+		runWorker(db)
+		return
+	}
+
+	// 充実度スコアのルール設定（ファイルがなければ既定のルール）
+	completenessCfg, err := config.LoadCompletenessConfig("services/hidden_waza/config/completeness.yaml")
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Fatal("充実度スコア設定読み込み失敗: ", err)
+		}
+	}
+	completenessRules, err := service.CompletenessRulesFromConfig(completenessCfg)
+	if err != nil {
+		log.Fatal("充実度スコア設定が不正です: ", err)
+	}
+
+	// 添付ファイルの保存先（設定ファイルがなければローカル保存）
+	storageCfg, err := config.LoadStorageConfig("services/hidden_waza/config/storage.yaml")
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Fatal("ストレージ設定読み込み失敗: ", err)
+		}
+	}
+	store, err := newStorage(storageCfg)
+	if err != nil {
+		log.Fatal("ストレージ初期化失敗: ", err)
+	}
+	attachmentOpts := service.AttachmentOptionsFromConfig(storageCfg)
+	if len(attachmentOpts.SigningKey) == 0 {
+		// 再起動で発行済みのダウンロードURLは無効になる
+		log.Print("storage.signing_key が未設定のため一時的な署名鍵を使用します")
+		attachmentOpts.SigningKey = make([]byte, 32)
+		if _, err := rand.Read(attachmentOpts.SigningKey); err != nil {
+			log.Fatal("署名鍵の生成失敗: ", err)
+		}
+	}
+
+	// イベント配信（SSE）の設定（ファイルがなければプロセス内のみ）
+	eventsCfg, err := config.LoadEventsConfig("services/hidden_waza/config/events.yaml")
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Fatal("イベント配信設定読み込み失敗: ", err)
+		}
+	}
+
+	// 認証まわりの設定（ファイルがなければ既定値）
+	authCfg, err := config.LoadAuthConfig("services/hidden_waza/config/auth.yaml")
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Fatal("認証設定読み込み失敗: ", err)
+		}
+	}
+	// JWTの署名鍵（公開されている既定値で署名すると誰でもJWTを偽造できるため、未設定では起動しない）
+	var jwtKey string
+	if authCfg != nil {
+		jwtKey = authCfg.Auth.JWTSigningKey
+	}
+	if err := handler.SetJWTSecret([]byte(jwtKey)); err != nil {
+		log.Fatal("auth.jwt_signing_key が不正です: ", err)
+	}
+	// クライアントのIPアドレスの取得方法（信用するプロキシ以外からのX-Forwarded-For・X-Real-IPは無視する）
+	var trustedProxies []string
+	if authCfg != nil {
+		trustedProxies = authCfg.Auth.TrustedProxies
+	}
+	ipExtractor, err := handler.NewIPExtractor(trustedProxies)
+	if err != nil {
+		log.Fatal("auth.trusted_proxies が不正です: ", err)
+	}
+	verificationOpts := service.EmailVerificationOptionsFromConfig(authCfg)
+	if len(verificationOpts.SigningKey) == 0 {
+		// 再起動で送信済みの確認メールのリンクは無効になる（POST /api/v1/signup/resendで再送）
+		log.Print("auth.token_signing_key が未設定のため一時的な署名鍵を使用します")
+		verificationOpts.SigningKey = make([]byte, 32)
+		if _, err := rand.Read(verificationOpts.SigningKey); err != nil {
+			log.Fatal("署名鍵の生成失敗: ", err)
+		}
+	}
+
+	// 外部IdP（OpenID Connect）の設定（ファイルがなければ外部IdPでのログインは無効）
+	oidcCfg, err := config.LoadOIDCConfig("services/hidden_waza/config/oidc.yaml")
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Fatal("OIDC設定読み込み失敗: ", err)
+		}
+	}
+	oidcProviders, err := service.OIDCProvidersFromConfig(oidcCfg)
+	if err != nil {
+		log.Fatal("OIDC設定が不正です: ", err)
+	}
+
+	// APIのレート制限の設定（ファイルがなければ既定値）
+	rateLimitCfg, err := config.LoadRateLimitConfig("services/hidden_waza/config/rate_limit.yaml")
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Fatal("レート制限設定読み込み失敗: ", err)
+		}
+	}
+	rateLimitOpts, err := service.RateLimitOptionsFromConfig(rateLimitCfg)
+	if err != nil {
+		log.Fatal("レート制限設定が不正です: ", err)
+	}
+
 	// DI
 	repo := repository.NewResumeRepository(db)
-	h := handler.NewResumeHandler(repo)
-
+	careerSvc := service.NewCareerService(repo)
+	similaritySvc := service.NewSimilarityService(repo)
+	completenessRepo := repository.NewCompletenessScoreRepository(db)
+	completenessSvc := service.NewCompletenessService(repo, completenessRepo, completenessRules)
+	completenessHandler := handler.NewCompletenessHandler(completenessSvc)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	attachmentSvc := service.NewAttachmentService(attachmentRepo, repo, store, attachmentOpts)
+	linkCheckRepo := repository.NewLinkCheckRepository(db)
+	linkCheckSvc := service.NewLinkCheckService(linkCheckRepo, service.DefaultLinkCheckOptions())
+	linkCheckHandler := handler.NewLinkCheckHandler(linkCheckSvc)
+	jobQueueRepo := repository.NewJobRepository(db)
+	jobQueue := service.NewJobQueue(jobQueueRepo)
+	jobQueueHandler := handler.NewJobHandler(jobQueue)
+	mailTemplates, err := mail.LoadTemplates()
+	if err != nil {
+		log.Fatal("メールテンプレート読み込み失敗: ", err)
+	}
 	userRepo := &repository.UserRepository{DB: db}
-	userHandler := &handler.UserHandler{Repo: userRepo}
+	notificationSvc := service.NewNotificationService(jobQueue, mailTemplates, userRepo)
+	verificationSvc := service.NewEmailVerificationService(repository.NewEmailVerificationRepository(db), userRepo, notificationSvc, verificationOpts)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentSvc, repo, verificationSvc)
+	passwordResetSvc := service.NewPasswordResetService(repository.NewPasswordResetRepository(db), userRepo, notificationSvc, service.PasswordResetOptionsFromConfig(authCfg))
+	var throttleStore string
+	if authCfg != nil {
+		throttleStore = authCfg.Auth.LoginThrottle.Store
+	}
+	loginAttempts, err := newLoginAttemptStore(throttleStore, db)
+	if err != nil {
+		log.Fatal("ログイン試行の制限の初期化失敗: ", err)
+	}
+	loginThrottle := service.NewLoginThrottle(loginAttempts, repository.NewLoginLockoutRepository(db), service.LoginThrottleOptionsFromConfig(authCfg))
+	loginThrottleHandler := handler.NewLoginThrottleHandler(loginThrottle)
+	mfaOpts := service.MFAOptionsFromConfig(authCfg)
+	mfaOpts.SigningKey = verificationOpts.SigningKey
+	mfaSvc := service.NewMFAService(repository.NewMFARepository(db), userRepo, mfaOpts)
+	mfaPolicyHandler := handler.NewMFAPolicyHandler(mfaSvc)
+	auditSvc := service.NewAuditService(repository.NewAuditLogRepository(db))
+	auditHandler := handler.NewAuditHandler(auditSvc)
+	userAdminHandler := handler.NewUserAdminHandler(userRepo, auditSvc)
+	oidcSvc := service.NewOIDCService(repository.NewOIDCRepository(db), userRepo, oidcProviders, nil)
+	accessTokenSvc := service.NewAccessTokenService(repository.NewAccessTokenRepository(db), userRepo)
+	accessTokenHandler := handler.NewAccessTokenHandler(accessTokenSvc, auditSvc)
+	var rateLimitStore string
+	if rateLimitCfg != nil {
+		rateLimitStore = rateLimitCfg.RateLimit.Store
+	}
+	rateLimitBuckets, err := newRateLimitStore(rateLimitStore, db)
+	if err != nil {
+		log.Fatal("レート制限の初期化失敗: ", err)
+	}
+	rateLimiter := service.NewRateLimiter(rateLimitBuckets, rateLimitOpts)
+	userHandler := &handler.UserHandler{
+		Repo:          userRepo,
+		Notifications: notificationSvc,
+		Verification:  verificationSvc,
+		PasswordReset: passwordResetSvc,
+		Throttle:      loginThrottle,
+		MFA:           mfaSvc,
+		OIDC:          oidcSvc,
+		Audit:         auditSvc,
+	}
+	h := handler.NewResumeHandler(repo, careerSvc, similaritySvc, completenessSvc, attachmentSvc, linkCheckSvc, verificationSvc, auditSvc)
+	similarityHandler := handler.NewSimilarityHandler(similaritySvc)
+	careerHandler := handler.NewCareerHandler(careerSvc)
 
+	trRepo := repository.NewTranslationRepository(db)
 	osRepo := repository.NewOSRepository(db)
-	osHandler := handler.NewOSHandler(osRepo)
+	osHandler := handler.NewOSHandler(osRepo, trRepo)
 	langRepo := repository.NewLanguageRepository(db)
-	langHandler := handler.NewLanguageHandler(langRepo)
+	langHandler := handler.NewLanguageHandler(langRepo, trRepo)
 	toolRepo := repository.NewToolRepository(db)
-	toolHandler := handler.NewToolHandler(toolRepo)
+	toolHandler := handler.NewToolHandler(toolRepo, trRepo)
+	skillLevelHandler := handler.NewSkillLevelHandler()
+
+	jobRepo := repository.NewJobPostingRepository(db)
+	matchingSvc := service.NewMatchingService(jobRepo, repo)
+	jobHandler := handler.NewJobPostingHandler(jobRepo, matchingSvc)
+
+	masterNames := service.NewMasterNameResolver(langRepo, toolRepo, osRepo, trRepo)
+	exportSvc := service.NewExportService(repo, masterNames)
+	gapSvc := service.NewGapAnalysisService(repo, masterNames)
+	gapHandler := handler.NewGapAnalysisHandler(gapSvc)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	analyticsSvc := service.NewAnalyticsService(analyticsRepo, masterNames)
+	analyticsHandler := handler.NewAnalyticsHandler(analyticsSvc)
+	exportHandler := handler.NewExportHandler(exportSvc)
+	trSvc := service.NewTranslationService(repo, trRepo)
+	trHandler := handler.NewTranslationHandler(trSvc, repo, verificationSvc)
+
+	webhookSvc := service.NewWebhookService(repository.NewWebhookRepository(db), service.DefaultWebhookOptions())
+	webhookHandler := handler.NewWebhookHandler(webhookSvc)
+	eventStreamSvc := service.NewEventStreamService(repository.NewEventRepository(db))
+	repo.OnEvents(eventStreamSvc.Publish)
+	if eventsCfg != nil && eventsCfg.Events.PollIntervalSeconds > 0 {
+		go eventStreamSvc.RunPoller(context.Background(), time.Duration(eventsCfg.Events.PollIntervalSeconds)*time.Second)
+	}
+	eventHandler := handler.NewEventHandler(eventStreamSvc)
+
+	// 要認証のルート用（パスワードの再設定前に発行したJWTは無効）
+	requireAuth := handler.RequireAuth(userRepo)
+	// 2段階認証の登録用（JWTの取得前に、ログインの1段階目で発行したMFAトークンでも登録できる）
+	requireAuthOrMFA := handler.RequireAuthOrMFAToken(requireAuth, mfaSvc)
+	// JWTに加えてパーソナルアクセストークン（権限が必要）でも認証できるルート用
+	readAuth := handler.RequireAuthOrAccessToken(requireAuth, accessTokenSvc, domain.ScopeResumeRead)
+	// 職務経歴書の参照は未認証でも利用できる（互換性のため）。Authorizationヘッダーがあれば検証し、トークンの権限を確認する
+	resumeRead := handler.OptionalAuth(readAuth)
+	// 作成・更新・削除は要認証
+	resumeWrite := handler.RequireAuthOrAccessToken(requireAuth, accessTokenSvc, domain.ScopeResumeWrite)
 
 	e := echo.New()
+	e.IPExtractor = ipExtractor
 
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	// URLの末尾のスラッシュを削除するミドルウェア
 	// これにより、`/api/v1/resume/post/` のようなリクエストも `/api/v1/resume/post` として処理される
 	e.Pre(middleware.RemoveTrailingSlash())
+	// リクエストID（X-Request-ID。指定がなければ生成）と監査ログの行為者
+	e.Use(middleware.RequestID())
+	e.Use(handler.AuditContext())
+	// クライアントごと・ルートごとのレート制限（ルートの決定後に適用されるため、ルートのパターンでポリシーを選ぶ）
+	if rateLimiter.Enabled() {
+		e.Use(handler.RateLimit(rateLimiter, accessTokenSvc))
+	}
 
 	e.GET("/", hello)
-	e.POST("/api/v1/resume", wrapHTTPHandler(h.CreateResume))
-	e.GET("/api/v1/resume", wrapHTTPHandler(h.GetResumes))
-	e.GET("/api/v1/resume/:id", h.GetResumeByID)
-	e.GET("/api/v1/resume/user/:user_id", h.GetResumesByUserID)
-	e.PUT("/api/v1/resume/:id", h.UpdateResume)
-	e.DELETE("/api/v1/resume/:id", h.DeleteResume)
+	e.POST("/api/v1/resume", h.CreateResume, resumeWrite)
+	e.GET("/api/v1/resume", wrapHTTPHandler(h.GetResumes), resumeRead)
+	e.GET("/api/v1/resume/:id", h.GetResumeByID, resumeRead)
+	e.GET("/api/v1/resume/user/:user_id", h.GetResumesByUserID, resumeRead)
+	e.PUT("/api/v1/resume/:id", h.UpdateResume, resumeWrite)
+	e.DELETE("/api/v1/resume/:id", h.DeleteResume, resumeWrite)
+	e.GET("/api/v1/resume/:id/export/docx", exportHandler.ExportDocx, resumeRead)
+	e.GET("/api/v1/resume/:id/metrics", careerHandler.GetMetrics, resumeRead)
+	e.GET("/api/v1/resume/:id/consistency", careerHandler.GetConsistency, resumeRead)
+	e.GET("/api/v1/resume/:id/job-matches", jobHandler.GetJobMatches, resumeRead)
+	e.POST("/api/v1/resume/:id/gap-analysis", gapHandler.AnalyzeGap, resumeRead)
+	e.GET("/api/v1/resume/:id/similar", similarityHandler.GetSimilar, resumeRead)
+	e.GET("/api/v1/resume/:id/completeness", completenessHandler.GetCompleteness, resumeRead)
+	e.GET("/api/v1/resume/:id/completeness/history", completenessHandler.GetCompletenessHistory, resumeRead)
+	e.POST("/api/v1/resume/:id/attachments", attachmentHandler.UploadAttachment, resumeWrite)
+	e.GET("/api/v1/resume/:id/attachments", attachmentHandler.GetAttachments, resumeRead)
+	e.PUT("/api/v1/resume/:id/translations/:lang", trHandler.PutResumeTranslation, resumeWrite)
+	e.DELETE("/api/v1/resume/:id/translations/:lang", trHandler.DeleteResumeTranslation, resumeWrite)
+	e.PUT("/api/v1/resume/:id/verified", h.VerifyResume, requireAuth, handler.RequireRole(domain.RoleVerifier, domain.RoleAdmin))
+
+	e.GET("/api/v1/attachments/:id/url", attachmentHandler.GetAttachmentURL, resumeRead)
+	e.GET("/api/v1/attachments/:id/download", attachmentHandler.DownloadAttachment, resumeRead)
+	e.DELETE("/api/v1/attachments/:id", attachmentHandler.DeleteAttachment, resumeWrite)
+
+	e.POST("/api/v1/jobs", jobHandler.CreateJob, requireAuth)
+	e.GET("/api/v1/jobs", jobHandler.GetJobs)
+	e.GET("/api/v1/jobs/:id", jobHandler.GetJobByID)
+	e.PUT("/api/v1/jobs/:id", jobHandler.UpdateJob, requireAuth)
+	e.DELETE("/api/v1/jobs/:id", jobHandler.DeleteJob, requireAuth)
+	e.GET("/api/v1/jobs/:id/matches", jobHandler.GetResumeMatches)
+
+	e.GET("/api/v1/analytics/skills/top", analyticsHandler.GetTopSkills)
+	e.GET("/api/v1/analytics/skills/co-occurrence", analyticsHandler.GetCoOccurrenceMatrix)
+	e.GET("/api/v1/analytics/skills/histogram", analyticsHandler.GetHistogram)
+	e.GET("/api/v1/analytics/skills/:type/:id/co-occurring", analyticsHandler.GetCoOccurring)
+	e.GET("/api/v1/analytics/resumes/trend", analyticsHandler.GetTrend)
+
+	e.GET("/api/v1/events", eventHandler.StreamEvents, readAuth)
+	e.GET("/api/v1/me/broken-links", linkCheckHandler.GetMyBrokenLinks, readAuth)
+
+	// トークンの管理・アカウントの紐付けはJWTのみ
+	me := e.Group("/api/v1/me", requireAuth)
+	me.GET("/identities", userHandler.GetIdentities)
+	me.POST("/identities/:provider", userHandler.LinkIdentity)
+	me.DELETE("/identities/:id", userHandler.UnlinkIdentity)
+	me.GET("/tokens", accessTokenHandler.GetAccessTokens)
+	me.POST("/tokens", accessTokenHandler.CreateAccessToken)
+	me.DELETE("/tokens/:id", accessTokenHandler.RevokeAccessToken)
+
+	admin := e.Group("/api/v1/admin", requireAuth, handler.RequireRole(domain.RoleAdmin))
+	admin.GET("/jobs", jobQueueHandler.GetJobs)
+	admin.GET("/jobs/stats", jobQueueHandler.GetJobStats)
+	admin.GET("/jobs/:id", jobQueueHandler.GetJob)
+	admin.POST("/jobs/:id/retry", jobQueueHandler.RetryJob)
+	admin.POST("/jobs/:id/cancel", jobQueueHandler.CancelJob)
+	admin.GET("/job-schedules", jobQueueHandler.GetJobSchedules)
+	admin.POST("/webhooks", webhookHandler.CreateWebhook)
+	admin.GET("/webhooks", webhookHandler.GetWebhooks)
+	admin.GET("/webhooks/:id", webhookHandler.GetWebhook)
+	admin.PUT("/webhooks/:id", webhookHandler.UpdateWebhook)
+	admin.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+	admin.GET("/webhooks/:id/deliveries", webhookHandler.GetWebhookDeliveries)
+	admin.GET("/webhook-deliveries/:id", webhookHandler.GetWebhookDelivery)
+	admin.POST("/webhook-deliveries/:id/redeliver", webhookHandler.RedeliverWebhook)
+	admin.GET("/login-lockouts", loginThrottleHandler.GetLoginLockouts)
+	admin.POST("/login-lockouts/unlock", loginThrottleHandler.UnlockLogin)
+	admin.GET("/mfa/required-roles", mfaPolicyHandler.GetMFARequiredRoles)
+	admin.PUT("/mfa/required-roles/:role", mfaPolicyHandler.PutMFARequiredRole)
+	admin.DELETE("/mfa/required-roles/:role", mfaPolicyHandler.DeleteMFARequiredRole)
+	admin.PUT("/users/:id/role", userAdminHandler.PutUserRole)
+	admin.GET("/audit-logs", auditHandler.GetAuditLogs)
+	admin.GET("/audit-logs/verify", auditHandler.VerifyAuditLogs)
+	admin.PUT("/masters/:type/:id/translations/:lang", trHandler.PutMasterTranslation)
+	admin.DELETE("/masters/:type/:id/translations/:lang", trHandler.DeleteMasterTranslation)
 
 	e.POST("/api/v1/signup", userHandler.Register)
+	e.POST("/api/v1/signup/verify", userHandler.VerifyEmail)
+	e.POST("/api/v1/signup/resend", userHandler.ResendVerification, requireAuth)
 	e.POST("/api/v1/login", userHandler.Login)
+	e.POST("/api/v1/login/mfa", userHandler.LoginMFA)
+	e.POST("/api/v1/password/forgot", userHandler.ForgotPassword)
+	e.POST("/api/v1/password/reset", userHandler.ResetPassword)
+	e.GET("/api/v1/oidc/providers", userHandler.GetOIDCProviders)
+	e.GET("/api/v1/oidc/:provider/authorize", userHandler.AuthorizeOIDC)
+	// 紐付けの場合のみJWTを付けて呼び出す
+	e.GET("/api/v1/oidc/:provider/callback", userHandler.OIDCCallback, handler.OptionalAuth(requireAuth))
+
+	e.GET("/api/v1/mfa", userHandler.GetMFAStatus, requireAuth)
+	e.POST("/api/v1/mfa/totp", userHandler.BeginMFAEnrollment, requireAuthOrMFA)
+	e.GET("/api/v1/mfa/totp/qr.png", userHandler.GetMFAQRCode, requireAuthOrMFA)
+	e.POST("/api/v1/mfa/totp/confirm", userHandler.ConfirmMFAEnrollment, requireAuthOrMFA)
+	e.POST("/api/v1/mfa/totp/disable", userHandler.DisableMFA, requireAuth)
+	e.POST("/api/v1/mfa/recovery-codes", userHandler.RegenerateMFARecoveryCodes, requireAuth)
 
 	e.GET("/api/v1/os", osHandler.GetOSList)
 	e.GET("/api/v1/languages", langHandler.GetLanguageList)
 	e.GET("/api/v1/tools", toolHandler.GetToolList)
+	e.GET("/api/v1/skill-levels", skillLevelHandler.GetSkillLevelList)
 
 	e.Logger.Fatal(e.Start(":8080"))
 }
 
+// 設定のdriverに応じたストレージ（未設定・cfgがnilならローカルの./uploads）
+func newStorage(cfg *config.StorageConfig) (storage.Storage, error) {
+	if cfg == nil {
+		return storage.NewLocalStorage("uploads")
+	}
+	switch cfg.Storage.Driver {
+	case "", "local":
+		root := cfg.Storage.Local.Root
+		if root == "" {
+			root = "uploads"
+		}
+		return storage.NewLocalStorage(root)
+	case "s3":
+		s3 := cfg.Storage.S3
+		return storage.NewS3Storage(storage.S3Config{
+			Endpoint:     s3.Endpoint,
+			Region:       s3.Region,
+			Bucket:       s3.Bucket,
+			AccessKey:    s3.AccessKey,
+			SecretKey:    s3.SecretKey,
+			UsePathStyle: s3.UsePathStyle,
+		})
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+}
+
+// 設定のstoreに応じたログインの失敗回数・禁止状態の保存先（未設定ならメモリ）
+func newLoginAttemptStore(kind string, db *gorm.DB) (service.LoginAttemptStore, error) {
+	switch kind {
+	case "", "memory":
+		return service.NewMemoryLoginAttemptStore(), nil
+	case "db":
+		return repository.NewLoginAttemptRepository(db), nil
+	}
+	return nil, fmt.Errorf("unknown login throttle store %q", kind)
+}
+
+// 設定のstoreに応じたレート制限のバケットの保存先（未設定ならメモリ）
+func newRateLimitStore(kind string, db *gorm.DB) (service.RateLimitStore, error) {
+	switch kind {
+	case "", "memory":
+		return service.NewMemoryRateLimitStore(), nil
+	case "db":
+		return repository.NewRateLimitRepository(db), nil
+	}
+	return nil, fmt.Errorf("unknown rate limit store %q", kind)
+}
+
 // http.HandlerFuncをecho.HandlerFuncに変換
 func wrapHTTPHandler(f func(http.ResponseWriter, *http.Request)) echo.HandlerFunc {
 	return func(c echo.Context) error {
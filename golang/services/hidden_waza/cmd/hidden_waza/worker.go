@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/requohylla/hidden-waza/pkg/config"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/mail"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+	"gorm.io/gorm"
+)
+
+// 完了・取り消し済みのジョブの保持期間
+const finishedJobRetention = 7 * 24 * time.Hour
+
+// アウトボックス（未配信のWebhookイベント）の確認間隔
+const webhookRelayInterval = 2 * time.Second
+
+// バックグラウンドジョブのワーカー（SIGINT/SIGTERMで実行中のジョブの完了を待って終了）
+func runWorker(db *gorm.DB) {
+	jobRepo := repository.NewJobRepository(db)
+	worker := service.NewJobWorker(jobRepo, service.DefaultJobWorkerOptions())
+
+	// ポートフォリオURLの定期確認（確認時刻を過ぎたURLを確認）
+	linkCheckSvc := service.NewLinkCheckService(repository.NewLinkCheckRepository(db), service.DefaultLinkCheckOptions())
+	worker.Handle("link_check.run", func(ctx context.Context, job *domain.Job) error {
+		n, err := linkCheckSvc.RunOnce(ctx)
+		if n > 0 {
+			log.Printf("link check: checked %d url(s)", n)
+		}
+		return err
+	})
+	// 古いジョブの削除
+	worker.Handle("jobs.prune", func(ctx context.Context, job *domain.Job) error {
+		n, err := jobRepo.DeleteFinished(time.Now().Add(-finishedJobRetention))
+		if n > 0 {
+			log.Printf("jobs prune: deleted %d job(s)", n)
+		}
+		return err
+	})
+
+	// Webhook配信（アウトボックスのイベントを配信ジョブにする）
+	webhookSvc := service.NewWebhookService(repository.NewWebhookRepository(db), service.DefaultWebhookOptions())
+	worker.Handle(service.WebhookDeliverJobType, webhookSvc.Deliver)
+
+	// メール送信・通知
+	mailCfg, err := config.LoadMailConfig("services/hidden_waza/config/mail.yaml")
+	if err != nil && !os.IsNotExist(err) {
+		log.Fatal("メール設定読み込み失敗: ", err)
+	}
+	mailer, err := newMailer(mailCfg)
+	if err != nil {
+		log.Fatal("メール送信の初期化失敗: ", err)
+	}
+	mailTemplates, err := mail.LoadTemplates()
+	if err != nil {
+		log.Fatal("メールテンプレート読み込み失敗: ", err)
+	}
+	notificationSvc := service.NewNotificationService(service.NewJobQueue(jobRepo), mailTemplates, &repository.UserRepository{DB: db})
+	worker.Handle(service.MailSendJobType, service.NewMailJobHandler(mailer))
+	worker.Handle(service.ResumeVerifiedNotifyJobType, notificationSvc.HandleResumeVerified)
+	webhookSvc.OnEvent(notificationSvc.ResumeVerifiedJobs)
+
+	schedules := []struct{ name, cron, jobType string }{
+		{"link-check", "*/10 * * * *", "link_check.run"},
+		{"jobs-prune", "@daily", "jobs.prune"},
+	}
+	for _, s := range schedules {
+		if err := worker.Schedule(s.name, s.cron, s.jobType, nil); err != nil {
+			log.Fatal("スケジュール登録失敗: ", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go webhookSvc.RunRelay(ctx, webhookRelayInterval)
+	if err := worker.Run(ctx); err != nil {
+		log.Fatal("ワーカー起動失敗: ", err)
+	}
+}
+
+// 設定のdriverに応じたメール送信（未設定・cfgがnilなら./mail_outboxに保存）
+func newMailer(cfg *config.MailConfig) (mail.Mailer, error) {
+	const defaultFrom = "hidden-waza <no-reply@localhost>"
+	if cfg == nil {
+		return mail.NewFileMailer("mail_outbox", defaultFrom)
+	}
+	from := cfg.Mail.From
+	if from == "" {
+		from = defaultFrom
+	}
+	switch cfg.Mail.Driver {
+	case "", "file":
+		dir := cfg.Mail.File.Dir
+		if dir == "" {
+			dir = "mail_outbox"
+		}
+		return mail.NewFileMailer(dir, from)
+	case "smtp":
+		smtp := cfg.Mail.SMTP
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:       smtp.Host,
+			Port:       smtp.Port,
+			Username:   smtp.Username,
+			Password:   smtp.Password,
+			RequireTLS: smtp.RequireTLS,
+			Timeout:    time.Duration(smtp.TimeoutSeconds) * time.Second,
+		}, from)
+	}
+	return nil, fmt.Errorf("unknown mail driver %q", cfg.Mail.Driver)
+}
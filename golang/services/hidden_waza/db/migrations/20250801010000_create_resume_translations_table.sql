@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS resume_translations (
+    id SERIAL PRIMARY KEY,
+    resume_id INTEGER NOT NULL REFERENCES resumes(id),
+    locale VARCHAR(8) NOT NULL,
+    title VARCHAR(255),
+    summary TEXT,
+    UNIQUE KEY uq_resume_translations_resume_locale (resume_id, locale)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS resume_translations;
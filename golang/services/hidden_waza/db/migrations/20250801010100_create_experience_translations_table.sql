@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS experience_translations (
+    id SERIAL PRIMARY KEY,
+    experience_id INTEGER NOT NULL REFERENCES experiences(id),
+    locale VARCHAR(8) NOT NULL,
+    position VARCHAR(255),
+    description TEXT,
+    UNIQUE KEY uq_experience_translations_experience_locale (experience_id, locale)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS experience_translations;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS master_translations (
+    id SERIAL PRIMARY KEY,
+    master_type VARCHAR(32) NOT NULL,
+    master_id INTEGER NOT NULL,
+    locale VARCHAR(8) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    UNIQUE KEY uq_master_translations_master_locale (master_type, master_id, locale)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS master_translations;
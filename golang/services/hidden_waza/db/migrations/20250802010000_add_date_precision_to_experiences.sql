@@ -0,0 +1,15 @@
+-- +goose Up
+-- 職歴の日付精度（"month": 年月のみ / "day": 年月日）。end_dateがNULLの場合は「現在」（在職中）
+ALTER TABLE experiences
+    ADD COLUMN start_date_precision VARCHAR(8) NOT NULL DEFAULT 'day' AFTER start_date,
+    ADD COLUMN end_date_precision VARCHAR(8) NOT NULL DEFAULT 'day' AFTER end_date;
+
+-- 既存データは年月日で登録されているため年月日精度（'day'）のままにする。
+-- 1日付の日付を年月精度とみなすと、4月1日入社のような実際の日付が年月表示になり、
+-- 終了日が月末まで延びて空白期間・経験年数の計算が変わるため行わない
+UPDATE experiences SET end_date_precision = '' WHERE end_date IS NULL;
+
+-- +goose Down
+ALTER TABLE experiences
+    DROP COLUMN start_date_precision,
+    DROP COLUMN end_date_precision;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS experience_skills (
+    id SERIAL PRIMARY KEY,
+    experience_id INTEGER NOT NULL REFERENCES experiences(id),
+    type VARCHAR(32) NOT NULL,
+    master_id INTEGER NOT NULL,
+    UNIQUE KEY uq_experience_skills_experience_master (experience_id, type, master_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS experience_skills;
@@ -0,0 +1,47 @@
+-- +goose Up
+-- skills.levelを正規コード（beginner/intermediate/advanced/expert）に統一する
+-- 別名の定義は internal/domain/skill_level.go の skillLevelAliases と揃えること
+
+-- 分類できなかった値の記録用（移行後に SELECT * FROM skill_level_migration_report で確認）
+CREATE TABLE IF NOT EXISTS skill_level_migration_report (
+    id SERIAL PRIMARY KEY,
+    skill_id INTEGER NOT NULL,
+    original_level VARCHAR(32),
+    reported_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+UPDATE skills SET level = CASE LOWER(TRIM(level))
+    WHEN 'beginner' THEN 'beginner'
+    WHEN 'basic' THEN 'beginner'
+    WHEN 'novice' THEN 'beginner'
+    WHEN 'junior' THEN 'beginner'
+    WHEN '初級' THEN 'beginner'
+    WHEN '初心者' THEN 'beginner'
+    WHEN '入門' THEN 'beginner'
+    WHEN '基礎' THEN 'beginner'
+    WHEN '1' THEN 'beginner'
+    WHEN 'intermediate' THEN 'intermediate'
+    WHEN 'middle' THEN 'intermediate'
+    WHEN 'mid' THEN 'intermediate'
+    WHEN '中級' THEN 'intermediate'
+    WHEN '2' THEN 'intermediate'
+    WHEN 'advanced' THEN 'advanced'
+    WHEN 'senior' THEN 'advanced'
+    WHEN '上級' THEN 'advanced'
+    WHEN '3' THEN 'advanced'
+    WHEN 'expert' THEN 'expert'
+    WHEN 'master' THEN 'expert'
+    WHEN 'エキスパート' THEN 'expert'
+    WHEN '熟練' THEN 'expert'
+    WHEN '専門家' THEN 'expert'
+    WHEN '4' THEN 'expert'
+    ELSE level
+END;
+
+-- 正規コードに変換できなかった行は値を変更せずレポートに残す
+INSERT INTO skill_level_migration_report (skill_id, original_level)
+SELECT id, level FROM skills
+WHERE level IS NULL OR level NOT IN ('beginner', 'intermediate', 'advanced', 'expert');
+
+-- +goose Down
+DROP TABLE IF EXISTS skill_level_migration_report;
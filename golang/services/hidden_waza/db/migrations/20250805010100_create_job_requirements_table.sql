@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS job_requirements (
+    id SERIAL PRIMARY KEY,
+    job_posting_id INTEGER NOT NULL REFERENCES job_postings(id),
+    type VARCHAR(32) NOT NULL,
+    master_id INTEGER NOT NULL,
+    min_years INTEGER NOT NULL DEFAULT 0,
+    min_level VARCHAR(32),
+    required BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS job_requirements;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS resume_completeness_scores (
+    id SERIAL PRIMARY KEY,
+    resume_id INTEGER NOT NULL REFERENCES resumes(id),
+    revision INTEGER NOT NULL,
+    score INTEGER NOT NULL,
+    suggestions TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_resume_completeness_scores_resume_revision (resume_id, revision)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS resume_completeness_scores;
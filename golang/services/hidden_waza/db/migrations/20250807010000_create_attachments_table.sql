@@ -0,0 +1,17 @@
+-- +goose Up
+-- experience_idは職歴の更新で職歴が削除された場合にNULLへ戻すため外部キーにしない
+CREATE TABLE IF NOT EXISTS attachments (
+    id SERIAL PRIMARY KEY,
+    resume_id INTEGER NOT NULL REFERENCES resumes(id),
+    experience_id INTEGER,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_attachments_storage_key (storage_key)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS portfolio_link_checks (
+    id SERIAL PRIMARY KEY,
+    url VARCHAR(255) NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    redirect_url TEXT,
+    final_url TEXT,
+    title VARCHAR(255),
+    error_message VARCHAR(255),
+    broken BOOLEAN NOT NULL DEFAULT FALSE,
+    failures INTEGER NOT NULL DEFAULT 0,
+    checked_at TIMESTAMP NULL,
+    next_check_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_portfolio_link_checks_url (url),
+    KEY idx_portfolio_link_checks_next_check_at (next_check_at)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS portfolio_link_checks;
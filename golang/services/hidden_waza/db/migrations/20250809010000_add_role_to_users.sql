@@ -0,0 +1,8 @@
+-- +goose Up
+-- ユーザーの権限（"user" / "admin"）。管理者は手動で UPDATE users SET role = 'admin' で付与する
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'user' AFTER password_hash;
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN role;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- resume_idは削除イベントも残すため外部キーにしない
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id SERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    resume_id INTEGER NOT NULL,
+    data TEXT,
+    published_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_outbox_events_published_at (published_at, id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- イベント配信（SSE）で利用者ごとに絞り込むため、データ内のuser_idを列にする
+ALTER TABLE outbox_events
+    ADD COLUMN user_id INTEGER NOT NULL DEFAULT 0 AFTER resume_id,
+    ADD KEY idx_outbox_events_user_id (user_id, id),
+    ADD KEY idx_outbox_events_created_at (created_at);
+UPDATE outbox_events SET user_id = COALESCE(JSON_VALUE(data, '$.user_id'), 0);
+
+-- +goose Down
+ALTER TABLE outbox_events
+    DROP KEY idx_outbox_events_created_at,
+    DROP KEY idx_outbox_events_user_id,
+    DROP COLUMN user_id;
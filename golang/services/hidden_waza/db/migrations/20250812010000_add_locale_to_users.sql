@@ -0,0 +1,8 @@
+-- +goose Up
+-- メールの言語（登録時のAccept-Language / ?lang=）
+ALTER TABLE users
+    ADD COLUMN locale VARCHAR(8) NOT NULL DEFAULT 'ja' AFTER role;
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN locale;
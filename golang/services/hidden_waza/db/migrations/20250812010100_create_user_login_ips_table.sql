@@ -0,0 +1,13 @@
+-- +goose Up
+-- ログインに使われたIPアドレス（新しいIPアドレスからのログイン通知用）
+CREATE TABLE IF NOT EXISTS user_login_ips (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    ip VARCHAR(45) NOT NULL,
+    first_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_user_login_ips_user_ip (user_id, ip)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_login_ips;
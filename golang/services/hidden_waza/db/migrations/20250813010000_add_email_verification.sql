@@ -0,0 +1,24 @@
+-- +goose Up
+-- メールアドレスの確認状態（"pending" / "verified"）。既存のユーザーは確認済みとする
+ALTER TABLE users
+    ADD COLUMN email_status VARCHAR(16) NOT NULL DEFAULT 'pending' AFTER locale,
+    ADD COLUMN email_verified_at TIMESTAMP NULL DEFAULT NULL AFTER email_status;
+UPDATE users SET email_status = 'verified', email_verified_at = created_at;
+
+-- メールアドレス確認のトークン（SHA-256のハッシュのみ保存）
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    token_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_email_verification_tokens_hash (token_hash),
+    KEY idx_email_verification_tokens_user (user_id, created_at)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS email_verification_tokens;
+ALTER TABLE users
+    DROP COLUMN email_verified_at,
+    DROP COLUMN email_status;
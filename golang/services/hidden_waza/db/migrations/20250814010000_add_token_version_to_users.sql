@@ -0,0 +1,8 @@
+-- +goose Up
+-- ログインで発行したJWTの世代。パスワードの再設定で1増やし、それ以前に発行したJWTを無効にする
+ALTER TABLE users
+    ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0 AFTER email_verified_at;
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN token_version;
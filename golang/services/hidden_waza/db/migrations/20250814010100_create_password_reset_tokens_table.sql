@@ -0,0 +1,15 @@
+-- +goose Up
+-- パスワード再設定のトークン（SHA-256のハッシュのみ保存）
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    token_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_password_reset_tokens_hash (token_hash),
+    KEY idx_password_reset_tokens_user (user_id, created_at)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS password_reset_tokens;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- ログインの失敗（キーは"account:<メールアドレス>" / "ip:<IPアドレス>"。login_throttle.store: dbの場合のみ使用）
+CREATE TABLE IF NOT EXISTS login_failures (
+    id SERIAL PRIMARY KEY,
+    `key` VARCHAR(320) NOT NULL,
+    created_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    KEY idx_login_failures_key_created (`key`, created_at)
+);
+
+-- ログインの一時的な禁止（login_throttle.store: dbの場合のみ使用）
+CREATE TABLE IF NOT EXISTS login_locks (
+    `key` VARCHAR(320) NOT NULL PRIMARY KEY,
+    level INTEGER NOT NULL,
+    locked_until TIMESTAMP(3) NOT NULL,
+    updated_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3)
+);
+
+-- 禁止・解除の記録（監査用）
+CREATE TABLE IF NOT EXISTS login_lockout_events (
+    id SERIAL PRIMARY KEY,
+    action VARCHAR(16) NOT NULL,
+    `key` VARCHAR(320) NOT NULL,
+    level INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP(3) NULL DEFAULT NULL,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    actor_id INTEGER NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_login_lockout_events_key (`key`)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_lockout_events;
+DROP TABLE IF EXISTS login_locks;
+DROP TABLE IF EXISTS login_failures;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- TOTPによる2段階認証
+--   mfa_secret: TOTPの秘密鍵（Base32）。登録の確認（mfa_enabled = TRUE）までは登録中の秘密鍵
+--   mfa_last_step: 最後に使用したコードの時間ステップ（同じコードの再利用を防ぐ）
+ALTER TABLE users
+    ADD COLUMN mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE AFTER token_version,
+    ADD COLUMN mfa_secret VARCHAR(64) NULL DEFAULT NULL AFTER mfa_enabled,
+    ADD COLUMN mfa_last_step BIGINT NOT NULL DEFAULT 0 AFTER mfa_secret;
+
+-- 2段階認証のリカバリーコード（SHA-256のハッシュのみ保存。1回のみ使用可能）
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_mfa_recovery_codes_user (user_id, code_hash)
+);
+
+-- 2段階認証を必須とする権限（管理APIで設定）
+CREATE TABLE IF NOT EXISTS mfa_required_roles (
+    role VARCHAR(32) NOT NULL PRIMARY KEY,
+    created_by INTEGER NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS mfa_required_roles;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+ALTER TABLE users
+    DROP COLUMN mfa_last_step,
+    DROP COLUMN mfa_secret,
+    DROP COLUMN mfa_enabled;
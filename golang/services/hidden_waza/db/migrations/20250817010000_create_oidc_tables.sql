@@ -0,0 +1,33 @@
+-- +goose Up
+-- 外部IdP（OpenID Connect）のアカウントとユーザーの紐付け（IdPごとのsubで一意）
+CREATE TABLE IF NOT EXISTS user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    provider VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    last_login_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_user_identities_provider_subject (provider, subject),
+    KEY idx_user_identities_user (user_id)
+);
+
+-- 認可リクエスト（stateはSHA-256のハッシュのみ保存。コールバックで1回のみ使用可能）
+--   link_user_id: ログイン中のユーザーが紐付けを開始した場合のユーザー
+CREATE TABLE IF NOT EXISTS oidc_auth_requests (
+    id SERIAL PRIMARY KEY,
+    state_hash CHAR(64) NOT NULL,
+    provider VARCHAR(64) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    link_user_id INTEGER NULL REFERENCES users(id),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_oidc_auth_requests_state (state_hash),
+    KEY idx_oidc_auth_requests_expires (expires_at)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS oidc_auth_requests;
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- パーソナルアクセストークン（スクリプト・外部連携用。トークンはSHA-256のハッシュと識別用の先頭部分のみ保存）
+--   scopes: 権限（"resume:read resume:write"のように空白区切り）
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    name VARCHAR(100) NOT NULL,
+    token_prefix VARCHAR(16) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP NULL DEFAULT NULL,
+    last_used_ip VARCHAR(45) NOT NULL DEFAULT '',
+    revoked_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_personal_access_tokens_hash (token_hash),
+    KEY idx_personal_access_tokens_user (user_id, created_at)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS personal_access_tokens;
@@ -0,0 +1,50 @@
+-- +goose Up
+-- 監査ログ（追記のみ。更新・削除はトリガーで禁止する）
+--   seq: 連番（欠番があれば削除された）
+--   prev_hash / hash: ハッシュチェーン（hash = SHA-256(前のエントリーのhash + エントリーの内容)。改ざん・削除を検出する）
+--   actor_user_id / actor_token_id: 行為者（ユーザーが削除されても残すため外部キーは付けない）
+--   before_hash / after_hash: 変更前後の対象（職務経歴書等）の内容のハッシュ
+--   details: 補足（JSON）
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id SERIAL PRIMARY KEY,
+    seq BIGINT NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    outcome VARCHAR(16) NOT NULL,
+    actor_user_id INTEGER NULL DEFAULT NULL,
+    actor_token_id INTEGER NULL DEFAULT NULL,
+    target_type VARCHAR(32) NOT NULL DEFAULT '',
+    target_id VARCHAR(64) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    before_hash VARCHAR(64) NOT NULL DEFAULT '',
+    after_hash VARCHAR(64) NOT NULL DEFAULT '',
+    details TEXT NOT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL,
+    UNIQUE KEY uq_audit_logs_seq (seq),
+    KEY idx_audit_logs_action (action, id),
+    KEY idx_audit_logs_actor (actor_user_id, id),
+    KEY idx_audit_logs_target (target_type, target_id, id),
+    KEY idx_audit_logs_created (created_at)
+);
+
+-- ハッシュチェーンの末尾（1行のみ。追記時に行ロックして連番・ハッシュを直列に採番する。末尾のエントリーの削除の検出にも使う）
+CREATE TABLE IF NOT EXISTS audit_log_head (
+    id INTEGER NOT NULL PRIMARY KEY,
+    seq BIGINT NOT NULL,
+    hash CHAR(64) NOT NULL
+);
+INSERT INTO audit_log_head (id, seq, hash) VALUES (1, 0, REPEAT('0', 64));
+
+CREATE TRIGGER audit_logs_no_update BEFORE UPDATE ON audit_logs FOR EACH ROW
+    SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
+CREATE TRIGGER audit_logs_no_delete BEFORE DELETE ON audit_logs FOR EACH ROW
+    SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
+
+-- +goose Down
+DROP TRIGGER IF EXISTS audit_logs_no_delete;
+DROP TRIGGER IF EXISTS audit_logs_no_update;
+DROP TABLE IF EXISTS audit_log_head;
+DROP TABLE IF EXISTS audit_logs;
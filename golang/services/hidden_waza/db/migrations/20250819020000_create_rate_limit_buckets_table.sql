@@ -0,0 +1,12 @@
+-- +goose Up
+-- APIのレート制限のトークンバケット（rate_limit.store: dbの場合のみ使用。インスタンス間で共有）
+--   tokens: updated_at時点のトークン数（経過時間に応じて補充し、リクエストごとに1つ消費する）
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    `key` VARCHAR(191) NOT NULL PRIMARY KEY,
+    tokens DOUBLE NOT NULL,
+    updated_at TIMESTAMP(6) NOT NULL,
+    KEY idx_rate_limit_buckets_updated (updated_at)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS rate_limit_buckets;
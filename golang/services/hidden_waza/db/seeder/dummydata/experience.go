@@ -18,16 +18,21 @@ func GenerateExperiences(count int, resumeCount int) []domain.Experience {
 		company := companies[i%len(companies)]
 		position := positions[i%len(positions)]
 		startYear := 2010 + rand.Intn(10)
-		startMonth := 1 + rand.Intn(12)
-		endYear := startYear + rand.Intn(3)
-		endMonth := 1 + rand.Intn(12)
+		startMonth := time.Month(1 + rand.Intn(12))
+		start := domain.NewCareerMonth(startYear, startMonth)
+		// 開始から1〜36ヶ月後に終了。1割は在職中（現在）
+		end := domain.PresentCareerDate()
+		if rand.Intn(10) != 0 {
+			endAt := start.Date.AddDate(0, 1+rand.Intn(36), 0)
+			end = domain.NewCareerMonth(endAt.Year(), endAt.Month())
+		}
 		experiences[i] = domain.Experience{
 			ID:           uint(i + 1),
 			ResumeID:     uint(rand.Intn(resumeCount) + 1),
 			Company:      company,
 			Position:     position,
-			StartDate:    fmt.Sprintf("%04d-%02d-01", startYear, startMonth),
-			EndDate:      fmt.Sprintf("%04d-%02d-01", endYear, endMonth),
+			StartDate:    start,
+			EndDate:      end,
 			Description:  fmt.Sprintf("%sで%sとして従事。Dummy説明%d", company, position, i+1),
 			PortfolioURL: fmt.Sprintf("https://portfolio.example.com/%d", i+1),
 		}
@@ -12,7 +12,7 @@ func GenerateSkills(count int, resumeCount, langCount, toolCount, osCount int) [
 	rand.Seed(time.Now().UnixNano())
 	skills := make([]domain.Skill, count)
 	types := []string{"language", "tool", "os"}
-	levels := []string{"beginner", "intermediate", "advanced", "expert"}
+	levels := domain.SkillLevels
 	for i := 0; i < count; i++ {
 		t := types[rand.Intn(len(types))]
 		var masterID uint
@@ -12,7 +12,8 @@ import (
 func GenerateUsers(n int) []domain.User {
 	rand.Seed(time.Now().UnixNano())
 	users := make([]domain.User, n)
-	now := time.Now().Format("2006-01-02 15:04:05")
+	verifiedAt := time.Now()
+	now := verifiedAt.Format("2006-01-02 15:04:05")
 	for i := 0; i < n; i++ {
 		plain := fmt.Sprintf("password%03d", i+1)
 		hash, err := domain.NewPasswordHash(plain)
@@ -23,8 +24,11 @@ func GenerateUsers(n int) []domain.User {
 			Username:     fmt.Sprintf("dummy_user_%03d", i+1),
 			Email:        domain.Email(fmt.Sprintf("user%03d%d@example.com", i+1, time.Now().UnixNano())),
 			PasswordHash: hash,
-			CreatedAt:    now,
-			UpdatedAt:    now,
+			// ダミーユーザーはメールアドレスの確認済みとする（職務経歴書を作成できるように）
+			EmailStatus:     domain.EmailVerified,
+			EmailVerifiedAt: &verifiedAt,
+			CreatedAt:       now,
+			UpdatedAt:       now,
 		}
 	}
 	return users
@@ -0,0 +1,32 @@
+// analytics_filter.go: スキル集計APIの共通絞り込み条件
+package domain
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type AnalyticsFilter struct {
+	Verified  *bool  // 職務経歴書の検証済みフラグ（nilなら全件）
+	SkillType string // "language", "tool", "os"（空なら全種別）
+	MasterID  uint   // SkillType指定時のみ有効（0なら種別内の全スキル）
+}
+
+func (f AnalyticsFilter) IsValid() bool {
+	switch f.SkillType {
+	case "":
+		return f.MasterID == 0
+	case "language", "tool", "os":
+		return true
+	}
+	return false
+}
+
+// 集計結果のキャッシュキー
+func (f AnalyticsFilter) Key() string {
+	verified := "all"
+	if f.Verified != nil {
+		verified = strconv.FormatBool(*f.Verified)
+	}
+	return fmt.Sprintf("verified=%s&type=%s&master_id=%d", verified, f.SkillType, f.MasterID)
+}
@@ -0,0 +1,21 @@
+// attachment.go: attachmentsテーブル用ドメインモデル
+// 職務経歴書（任意で職歴）に添付したファイル（PDF・画像・zip等）。実体はストレージのStorageKeyに保存
+package domain
+
+import "time"
+
+type Attachment struct {
+	ID           uint      `json:"id"`
+	ResumeID     uint      `json:"resume_id"`
+	ExperienceID *uint     `json:"experience_id"` // nilは職務経歴書全体への添付
+	FileName     string    `json:"file_name"`
+	ContentType  string    `json:"content_type"` // ファイル内容から判定したMIMEタイプ
+	Size         int64     `json:"size"`
+	Checksum     string    `json:"checksum"` // SHA-256（16進）
+	StorageKey   string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (Attachment) TableName() string {
+	return "attachments"
+}
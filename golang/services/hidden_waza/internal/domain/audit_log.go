@@ -0,0 +1,101 @@
+// audit_log.go: audit_logs / audit_log_headテーブル用ドメインモデル
+// 監査ログ（追記のみ）。各エントリーは前のエントリーのハッシュを含めてハッシュ化し（ハッシュチェーン）、改ざん・削除を検出できるようにする
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// 監査ログの操作
+const (
+	AuditLogin             = "auth.login"        // ログイン（パスワード・外部IdP・2段階認証。成功時はJWTを発行）
+	AuditAccessTokenCreate = "auth.token.create" // パーソナルアクセストークンの発行
+	AuditAccessTokenRevoke = "auth.token.revoke" // パーソナルアクセストークンの失効
+	AuditUserRoleChange    = "user.role.change"  // ユーザーの権限の変更
+	AuditResumeCreate      = "resume.create"
+	AuditResumeUpdate      = "resume.update"
+	AuditResumeDelete      = "resume.delete"
+	AuditResumeVerify      = "resume.verify" // 職務経歴書の検証済みの変更
+)
+
+// 監査ログの結果
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// 監査ログの対象の種類
+const (
+	AuditTargetUser        = "user"
+	AuditTargetAccessToken = "access_token"
+	AuditTargetResume      = "resume"
+)
+
+// ハッシュチェーンの先頭のエントリーのprev_hash
+var AuditGenesisHash = strings.Repeat("0", 64)
+
+type AuditLog struct {
+	ID           uint      `json:"id"`
+	Seq          int64     `json:"seq"`
+	Action       string    `json:"action"`
+	Outcome      string    `json:"outcome"`
+	ActorUserID  *uint     `json:"actor_user_id"`
+	ActorTokenID *uint     `json:"actor_token_id,omitempty"` // パーソナルアクセストークンで認証した場合
+	TargetType   string    `json:"target_type"`
+	TargetID     string    `json:"target_id"`
+	IP           string    `json:"ip"`
+	UserAgent    string    `json:"user_agent"`
+	RequestID    string    `json:"request_id"`
+	BeforeHash   string    `json:"before_hash,omitempty"`
+	AfterHash    string    `json:"after_hash,omitempty"`
+	Details      string    `json:"-"` // JSON（APIではDTOで展開）
+	PrevHash     string    `json:"prev_hash"`
+	Hash         string    `json:"hash"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// ハッシュチェーンの末尾（1行のみ）
+type AuditLogHead struct {
+	ID   uint
+	Seq  int64
+	Hash string
+}
+
+func (AuditLogHead) TableName() string {
+	return "audit_log_head"
+}
+
+// ComputeHashはエントリーのハッシュ（SHA-256(prev_hash + 内容のJSON)）を返します。
+// 日時はDBの精度（マイクロ秒）に切り捨て、UTCで含めます。
+func (l *AuditLog) ComputeHash() string {
+	content, _ := json.Marshal(struct {
+		Seq          int64  `json:"seq"`
+		Action       string `json:"action"`
+		Outcome      string `json:"outcome"`
+		ActorUserID  *uint  `json:"actor_user_id"`
+		ActorTokenID *uint  `json:"actor_token_id"`
+		TargetType   string `json:"target_type"`
+		TargetID     string `json:"target_id"`
+		IP           string `json:"ip"`
+		UserAgent    string `json:"user_agent"`
+		RequestID    string `json:"request_id"`
+		BeforeHash   string `json:"before_hash"`
+		AfterHash    string `json:"after_hash"`
+		Details      string `json:"details"`
+		CreatedAt    string `json:"created_at"`
+	}{
+		l.Seq, l.Action, l.Outcome, l.ActorUserID, l.ActorTokenID, l.TargetType, l.TargetID,
+		l.IP, l.UserAgent, l.RequestID, l.BeforeHash, l.AfterHash, l.Details,
+		l.CreatedAt.Truncate(time.Microsecond).UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(append([]byte(l.PrevHash), content...))
+	return hex.EncodeToString(sum[:])
+}
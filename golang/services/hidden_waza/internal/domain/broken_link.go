@@ -0,0 +1,16 @@
+// broken_link.go: リンク切れのポートフォリオURL（職歴ごと）
+package domain
+
+import "time"
+
+type BrokenLink struct {
+	ResumeID     uint       `json:"resume_id"`
+	ResumeTitle  string     `json:"resume_title"`
+	ExperienceID uint       `json:"experience_id"`
+	Company      string     `json:"company"`
+	URL          string     `json:"url"`
+	StatusCode   int        `json:"status_code"`
+	FinalURL     string     `json:"final_url,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	CheckedAt    *time.Time `json:"checked_at"`
+}
@@ -0,0 +1,125 @@
+// career_date.go: CareerDate値オブジェクト（職歴の開始/終了日）
+//
+// 年月のみ（"2020-04"）・年月日（"2020-04-15"）の精度と、終了日の「現在」（在職中）を表現する。
+// - JSON: "2020-04" / "2020-04-15" / "present"
+// - DB:   <prefix>date（DATE、「現在」はNULL）と <prefix>date_precision（"month"/"day"）の2カラム
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+type DatePrecision string
+
+const (
+	PrecisionDay   DatePrecision = "day"
+	PrecisionMonth DatePrecision = "month"
+)
+
+// JSON/APIで「現在」を表す文字列
+const PresentLiteral = "present"
+
+var ErrInvalidCareerDate = errors.New("invalid date: use YYYY-MM, YYYY-MM-DD or \"present\"")
+
+// CareerDateのゼロ値は「現在」（期間の終わりが未確定）を表す
+type CareerDate struct {
+	Date      *time.Time    `gorm:"column:date;type:date"`
+	Precision DatePrecision `gorm:"column:date_precision;type:varchar(8)"`
+}
+
+// 年月日精度のCareerDateを生成
+func NewCareerDay(year int, month time.Month, day int) CareerDate {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return CareerDate{Date: &t, Precision: PrecisionDay}
+}
+
+// 年月精度のCareerDateを生成（日は1日で保持）
+func NewCareerMonth(year int, month time.Month) CareerDate {
+	t := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return CareerDate{Date: &t, Precision: PrecisionMonth}
+}
+
+// 「現在」を表すCareerDateを生成
+func PresentCareerDate() CareerDate {
+	return CareerDate{}
+}
+
+// 文字列からCareerDateを生成
+// "YYYY-MM" / "YYYY-MM-DD"（区切りは"/"も可）、空文字・"present"・"現在"は「現在」として扱う
+func ParseCareerDate(s string) (CareerDate, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "", PresentLiteral, "現在":
+		return PresentCareerDate(), nil
+	}
+	s = strings.ReplaceAll(s, "/", "-")
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return NewCareerDay(t.Year(), t.Month(), t.Day()), nil
+	}
+	if t, err := time.Parse("2006-01", s); err == nil {
+		return NewCareerMonth(t.Year(), t.Month()), nil
+	}
+	return CareerDate{}, ErrInvalidCareerDate
+}
+
+func (d CareerDate) IsPresent() bool {
+	return d.Date == nil
+}
+
+// 期間の初日（「現在」ならnow）
+func (d CareerDate) FirstDay(now time.Time) time.Time {
+	if d.IsPresent() {
+		return truncateDay(now)
+	}
+	return *d.Date
+}
+
+// 期間の末日（年月精度なら月末、「現在」ならnow）
+func (d CareerDate) LastDay(now time.Time) time.Time {
+	if d.IsPresent() {
+		return truncateDay(now)
+	}
+	if d.Precision == PrecisionMonth {
+		return d.Date.AddDate(0, 1, -1)
+	}
+	return *d.Date
+}
+
+// 正規化した文字列表現（"2020-04" / "2020-04-15" / "present"）
+func (d CareerDate) String() string {
+	if d.IsPresent() {
+		return PresentLiteral
+	}
+	if d.Precision == PrecisionMonth {
+		return d.Date.Format("2006-01")
+	}
+	return d.Date.Format("2006-01-02")
+}
+
+func (d CareerDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *CareerDate) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*d = PresentCareerDate()
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return ErrInvalidCareerDate
+	}
+	parsed, err := ParseCareerDate(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseCareerDate(t *testing.T) {
+	tests := []struct {
+		in        string
+		want      string
+		precision DatePrecision
+		present   bool
+	}{
+		{"2020-04", "2020-04", PrecisionMonth, false},
+		{"2020/4", "", "", false},
+		{"2020/04", "2020-04", PrecisionMonth, false},
+		{"2020-04-15", "2020-04-15", PrecisionDay, false},
+		{" 2020/04/15 ", "2020-04-15", PrecisionDay, false},
+		// 1日付でも年月日精度のまま
+		{"2020-04-01", "2020-04-01", PrecisionDay, false},
+		{"2024-02-29", "2024-02-29", PrecisionDay, false},
+		{"", "present", "", true},
+		{"  ", "present", "", true},
+		{"present", "present", "", true},
+		{"Present", "present", "", true},
+		{"現在", "present", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCareerDate(tt.in)
+		if tt.want == "" {
+			if !errors.Is(err, ErrInvalidCareerDate) {
+				t.Errorf("ParseCareerDate(%q) = %v, %v; want ErrInvalidCareerDate", tt.in, got, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseCareerDate(%q): %v", tt.in, err)
+			continue
+		}
+		if got.String() != tt.want || got.Precision != tt.precision || got.IsPresent() != tt.present {
+			t.Errorf("ParseCareerDate(%q) = %s (%q, present %v), want %s (%q, present %v)",
+				tt.in, got, got.Precision, got.IsPresent(), tt.want, tt.precision, tt.present)
+		}
+	}
+
+	for _, in := range []string{"2020-13", "2020-02-30", "2023-02-29", "2020-00-10", "20200415", "2020-4-15", "April 2020", "2020"} {
+		if _, err := ParseCareerDate(in); !errors.Is(err, ErrInvalidCareerDate) {
+			t.Errorf("ParseCareerDate(%q): %v, want ErrInvalidCareerDate", in, err)
+		}
+	}
+}
+
+func TestCareerDateFirstLastDay(t *testing.T) {
+	now := time.Date(2025, 8, 1, 15, 30, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name        string
+		d           CareerDate
+		first, last time.Time
+	}{
+		{"day", NewCareerDay(2020, 4, 15), day(2020, 4, 15), day(2020, 4, 15)},
+		{"month", NewCareerMonth(2020, 4), day(2020, 4, 1), day(2020, 4, 30)},
+		{"month (31 days)", NewCareerMonth(2020, 12), day(2020, 12, 1), day(2020, 12, 31)},
+		{"february in leap year", NewCareerMonth(2024, 2), day(2024, 2, 1), day(2024, 2, 29)},
+		{"february", NewCareerMonth(2023, 2), day(2023, 2, 1), day(2023, 2, 28)},
+		// 「現在」は時刻を切り捨てたnow
+		{"present", PresentCareerDate(), day(2025, 8, 1), day(2025, 8, 1)},
+	}
+	for _, tt := range tests {
+		if got := tt.d.FirstDay(now); !got.Equal(tt.first) {
+			t.Errorf("%s: FirstDay = %s, want %s", tt.name, got, tt.first)
+		}
+		if got := tt.d.LastDay(now); !got.Equal(tt.last) {
+			t.Errorf("%s: LastDay = %s, want %s", tt.name, got, tt.last)
+		}
+	}
+}
+
+func TestCareerDateJSON(t *testing.T) {
+	for _, in := range []string{`"2020-04"`, `"2020-04-15"`, `"present"`} {
+		var d CareerDate
+		if err := json.Unmarshal([]byte(in), &d); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", in, err)
+		}
+		out, err := json.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != in {
+			t.Errorf("round trip %s = %s", in, out)
+		}
+	}
+
+	// null・空文字は「現在」
+	for _, in := range []string{`null`, `""`} {
+		d := NewCareerDay(2020, 4, 15)
+		if err := json.Unmarshal([]byte(in), &d); err != nil || !d.IsPresent() {
+			t.Errorf("Unmarshal(%s) = %v, %v; want present", in, d, err)
+		}
+	}
+	for _, in := range []string{`202004`, `"2020-13"`, `{}`} {
+		var d CareerDate
+		if err := json.Unmarshal([]byte(in), &d); !errors.Is(err, ErrInvalidCareerDate) {
+			t.Errorf("Unmarshal(%s): %v, want ErrInvalidCareerDate", in, err)
+		}
+	}
+}
+
+// 開始日・終了日の比較（精度が異なる場合は期間として比較、終了日の「現在」は常に開始日以降）
+func TestExperienceValidatePeriod(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		want       error
+	}{
+		{"month to month", "2020-04", "2021-03", nil},
+		{"same month", "2020-04", "2020-04", nil},
+		{"same day", "2020-04-15", "2020-04-15", nil},
+		{"day to earlier day", "2020-04-15", "2020-04-14", ErrEndBeforeStart},
+		{"month to earlier month", "2020-04", "2020-03", ErrEndBeforeStart},
+		// 開始日の月内に終わる年月精度の終了日は許容
+		{"day start, month end in same month", "2020-04-15", "2020-04", nil},
+		{"month start, day end in same month", "2020-04", "2020-04-01", nil},
+		{"month start, day end in previous month", "2020-04", "2020-03-31", ErrEndBeforeStart},
+		{"day start, month end in previous month", "2020-04-01", "2020-03", ErrEndBeforeStart},
+		{"open end", "2020-04", "present", nil},
+		{"open end, start in the future", "2999-01-01", "", nil},
+		{"missing start", "", "2020-04", ErrStartDateRequired},
+		{"missing start and end", "present", "present", ErrStartDateRequired},
+	}
+	for _, tt := range tests {
+		start, err := ParseCareerDate(tt.start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		end, err := ParseCareerDate(tt.end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := Experience{Company: "Example", StartDate: start, EndDate: end}
+		if got := e.ValidatePeriod(); got != tt.want {
+			t.Errorf("%s: ValidatePeriod = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := e.IsValid(); got != (tt.want == nil) {
+			t.Errorf("%s: IsValid = %v", tt.name, got)
+		}
+	}
+}
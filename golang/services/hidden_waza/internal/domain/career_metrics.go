@@ -0,0 +1,37 @@
+// career_metrics.go: 職歴から算出する派生データ（総経験年数・空白期間・兼務・在籍期間）
+// 値はサービス層（CareerService）で算出し、APIレスポンスにそのまま利用する
+package domain
+
+type CareerMetrics struct {
+	TotalMonths    int             `json:"total_months"` // 重複期間をマージした実働月数
+	TotalYears     float64         `json:"total_years"`  // TotalMonthsを年換算（小数1桁）
+	GapThreshold   int             `json:"gap_threshold_months"`
+	Gaps           []CareerGap     `json:"gaps"`
+	Overlaps       []CareerOverlap `json:"overlaps"`
+	CompanyTenures []CompanyTenure `json:"company_tenures"`
+}
+
+// CareerGapは職歴と職歴の間の空白期間（閾値を超えるもののみ）
+type CareerGap struct {
+	From   string `json:"from"` // 空白の初日（YYYY-MM-DD）
+	To     string `json:"to"`   // 空白の末日（YYYY-MM-DD）
+	Months int    `json:"months"`
+}
+
+// CareerOverlapは期間が重なる（兼務している）2つの職歴
+type CareerOverlap struct {
+	ExperienceIDs []uint   `json:"experience_ids"`
+	Companies     []string `json:"companies"`
+	From          string   `json:"from"`
+	To            string   `json:"to"`
+	Months        int      `json:"months"`
+}
+
+// CompanyTenureは会社ごとの在籍期間（同一社内の重複はマージ）
+type CompanyTenure struct {
+	Company string  `json:"company"`
+	From    string  `json:"from"`
+	To      string  `json:"to"` // 在籍中は"present"
+	Months  int     `json:"months"`
+	Years   float64 `json:"years"`
+}
@@ -0,0 +1,18 @@
+// completeness_score.go: resume_completeness_scoresテーブル用ドメインモデル
+// 職務経歴書の登録・更新（リビジョン）ごとの充実度スコア（0〜100）と改善提案
+package domain
+
+import "time"
+
+type CompletenessScore struct {
+	ID          uint                     `json:"-"`
+	ResumeID    uint                     `json:"resume_id"`
+	Revision    int                      `json:"revision"` // 職務経歴書ごとに1から採番（0は未記録の即時算出）
+	Score       int                      `json:"score"`
+	Suggestions []CompletenessSuggestion `json:"suggestions" gorm:"serializer:json;type:text"`
+	CreatedAt   time.Time                `json:"created_at"`
+}
+
+func (CompletenessScore) TableName() string {
+	return "resume_completeness_scores"
+}
@@ -0,0 +1,11 @@
+// completeness_suggestion.go: 充実度スコアの改善提案（満たしていないルール1件分）
+package domain
+
+type CompletenessSuggestion struct {
+	RuleID        string  `json:"rule_id"`
+	Message       string  `json:"message,omitempty"`        // 表示言語で解決した提案文（保存はしない）
+	Gain          float64 `json:"gain"`                     // 対応した場合に上がる点数
+	ExperienceIDs []uint  `json:"experience_ids,omitempty"` // 対象の職歴（職歴単位のルールのみ）
+	Count         int     `json:"count,omitempty"`          // 対象件数（スキル単位のルール等）
+	Threshold     int     `json:"threshold,omitempty"`      // 評価時の閾値（min_length / min_count）
+}
@@ -0,0 +1,18 @@
+// email_verification_token.go: email_verification_tokensテーブル用ドメインモデル
+// メールアドレス確認のトークン（トークン自体は保存せず、SHA-256のハッシュのみ保存）
+package domain
+
+import "time"
+
+type EmailVerificationToken struct {
+	ID        uint       `json:"id"`
+	UserID    uint       `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"` // 使用済み・再送で無効化された日時
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (EmailVerificationToken) TableName() string {
+	return "email_verification_tokens"
+}
@@ -1,22 +1,69 @@
 // experience.go: experiencesテーブル用ドメインモデル
 package domain
 
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrStartDateRequired = errors.New("start_date is required")
+	ErrEndBeforeStart    = errors.New("end_date must not be before start_date")
+)
+
 type Experience struct {
-	ID           uint   `json:"id"`
-	ResumeID     uint   `json:"resume_id"`
-	Company      string `json:"company"`
-	Position     string `json:"position"`
-	StartDate    string `json:"start_date"`
-	EndDate      string `json:"end_date"`
-	Description  string `json:"description"`
-	PortfolioURL string `json:"portfolio_url"`
+	ID           uint       `json:"id"`
+	ResumeID     uint       `json:"resume_id"`
+	Company      string     `json:"company"`
+	Position     string     `json:"position"`
+	StartDate    CareerDate `json:"start_date" gorm:"embedded;embeddedPrefix:start_"`
+	EndDate      CareerDate `json:"end_date" gorm:"embedded;embeddedPrefix:end_"` // ゼロ値は「現在」（在職中）
+	Description  string     `json:"description"`
+	PortfolioURL string     `json:"portfolio_url"`
+	// Position/Descriptionの言語別翻訳
+	Translations []ExperienceTranslation `json:"translations,omitempty" gorm:"foreignKey:ExperienceID;constraint:OnDelete:CASCADE"`
+	// この職歴で使用したスキル（任意）
+	Skills []ExperienceSkill `json:"skills,omitempty" gorm:"foreignKey:ExperienceID;constraint:OnDelete:CASCADE"`
 }
 
 func (e Experience) IsValid() bool {
-	if e.Company == "" || e.StartDate == "" {
+	if e.Company == "" {
 		return false
 	}
-	return true
+	return e.ValidatePeriod() == nil
+}
+
+// 期間の業務ルール: 開始日は必須（「現在」不可）、終了日は開始日より前にならない
+// 精度が異なる場合は期間として比較する（開始"2020-04-15"・終了"2020-04"は同月として許容）
+func (e Experience) ValidatePeriod() error {
+	if e.StartDate.IsPresent() {
+		return ErrStartDateRequired
+	}
+	if e.EndDate.IsPresent() {
+		return nil
+	}
+	if e.EndDate.LastDay(time.Time{}).Before(e.StartDate.FirstDay(time.Time{})) {
+		return ErrEndBeforeStart
+	}
+	return nil
+}
+
+// 指定言語で表示するためのコピーを返す
+func (e Experience) Localized(loc Locale) Experience {
+	var tr ExperienceTranslation
+	var otherPositions, otherDescriptions []string
+	for _, t := range e.Translations {
+		if t.Locale == loc {
+			tr = t
+			continue
+		}
+		otherPositions = append(otherPositions, t.Position)
+		otherDescriptions = append(otherDescriptions, t.Description)
+	}
+	out := e
+	out.Position = pickLocalized(tr.Position, e.Position, otherPositions...)
+	out.Description = pickLocalized(tr.Description, e.Description, otherDescriptions...)
+	return out
 }
 
 func (Experience) TableName() string {
@@ -0,0 +1,23 @@
+// experience_skill.go: experience_skillsテーブル用ドメインモデル
+// 職歴で使用したスキル（languages/tools/osマスタ）を表す
+package domain
+
+type ExperienceSkill struct {
+	ID           uint   `json:"id"`
+	ExperienceID uint   `json:"experience_id"`
+	Type         string `json:"type"`      // "language", "tool", "os"
+	MasterID     uint   `json:"master_id"` // languages/tools/osのid
+}
+
+func (s ExperienceSkill) IsValid() bool {
+	switch s.Type {
+	case "language", "tool", "os":
+	default:
+		return false
+	}
+	return s.MasterID != 0
+}
+
+func (ExperienceSkill) TableName() string {
+	return "experience_skills"
+}
@@ -0,0 +1,14 @@
+// experience_translation.go: experience_translationsテーブル用ドメインモデル
+package domain
+
+type ExperienceTranslation struct {
+	ID           uint   `json:"id"`
+	ExperienceID uint   `json:"experience_id"`
+	Locale       Locale `json:"locale"`
+	Position     string `json:"position"`
+	Description  string `json:"description"`
+}
+
+func (ExperienceTranslation) TableName() string {
+	return "experience_translations"
+}
@@ -0,0 +1,48 @@
+// job.go: jobsテーブル用ドメインモデル
+// DBに保存するバックグラウンドジョブ（ワーカーが取得して実行）
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ジョブの状態
+const (
+	JobPending   = "pending"   // 実行待ち（失敗後の再試行待ちを含む）
+	JobRunning   = "running"   // 実行中
+	JobSucceeded = "succeeded" // 成功
+	JobDead      = "dead"      // 再試行の上限に達した（デッドレター）
+	JobCancelled = "cancelled" // 管理APIで取り消し
+)
+
+type Job struct {
+	ID          uint            `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload" gorm:"type:text"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	// 同じキーの未完了（pending/running）ジョブは1件まで。完了・デッドレター・取り消しでNULLに戻す
+	UniqueKey  *string    `json:"unique_key,omitempty"`
+	RunAt      time.Time  `json:"run_at"`
+	LockedBy   string     `json:"locked_by,omitempty"`
+	LockedAt   *time.Time `json:"locked_at,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// IsValidJobStatusはジョブの状態の値が正しいかを返します。
+func IsValidJobStatus(s string) bool {
+	switch s {
+	case JobPending, JobRunning, JobSucceeded, JobDead, JobCancelled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+// job_match.go: 求人と職務経歴書のマッチング結果（スコアと要件ごとの判定理由）
+package domain
+
+// 要件ごとの充足状況
+const (
+	MatchMet     = "met"     // 年数・レベルとも満たす
+	MatchPartial = "partial" // スキルはあるが年数またはレベルが不足
+	MatchMissing = "missing" // スキルがない
+)
+
+type JobMatch struct {
+	JobPostingID  uint               `json:"job_posting_id"`
+	ResumeID      uint               `json:"resume_id"`
+	Score         float64            `json:"score"`          // 0〜100
+	MeetsRequired bool               `json:"meets_required"` // 必須要件を全て満たすか
+	Details       []RequirementMatch `json:"details"`
+}
+
+// RequirementMatchは要件1件に対する判定理由
+type RequirementMatch struct {
+	Type        string     `json:"type"`
+	MasterID    uint       `json:"master_id"`
+	Required    bool       `json:"required"`
+	Status      string     `json:"status"`
+	MinYears    int        `json:"min_years"`
+	MinLevel    SkillLevel `json:"min_level,omitempty"`
+	ActualYears int        `json:"actual_years"`
+	ActualLevel SkillLevel `json:"actual_level,omitempty"`
+	Score       float64    `json:"score"` // 0〜1
+}
@@ -0,0 +1,48 @@
+// job_posting.go: job_postingsテーブル用ドメインモデル
+package domain
+
+import "time"
+
+// 求人の公開状態
+const (
+	JobStatusDraft     = "draft"
+	JobStatusPublished = "published"
+	JobStatusClosed    = "closed"
+)
+
+type JobPosting struct {
+	ID           uint             `json:"id"`
+	PosterID     uint             `json:"poster_id" gorm:"column:poster_id"` // 掲載者（users.id）
+	Title        string           `json:"title"`
+	Company      string           `json:"company"`
+	Description  string           `json:"description"`
+	Status       string           `json:"status"`
+	Requirements []JobRequirement `json:"requirements" gorm:"foreignKey:JobPostingID;constraint:OnDelete:CASCADE"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+func (j *JobPosting) IsValid() bool {
+	if j.Title == "" || j.PosterID == 0 {
+		return false
+	}
+	switch j.Status {
+	case JobStatusDraft, JobStatusPublished, JobStatusClosed:
+	default:
+		return false
+	}
+	for _, r := range j.Requirements {
+		if !r.IsValid() {
+			return false
+		}
+	}
+	return true
+}
+
+func (j *JobPosting) IsPublished() bool {
+	return j.Status == JobStatusPublished
+}
+
+func (JobPosting) TableName() string {
+	return "job_postings"
+}
@@ -0,0 +1,29 @@
+// job_requirement.go: job_requirementsテーブル用ドメインモデル
+// 求人が求めるスキル（languages/tools/osマスタ参照）と最低経験年数・最低レベル
+package domain
+
+type JobRequirement struct {
+	ID           uint       `json:"id"`
+	JobPostingID uint       `json:"job_posting_id"`
+	Type         string     `json:"type"`      // "language", "tool", "os"
+	MasterID     uint       `json:"master_id"` // languages/tools/osのid
+	MinYears     int        `json:"min_years"`
+	MinLevel     SkillLevel `json:"min_level"` // 空なら不問
+	Required     bool       `json:"required"`  // true: 必須, false: 歓迎
+}
+
+func (r JobRequirement) IsValid() bool {
+	switch r.Type {
+	case "language", "tool", "os":
+	default:
+		return false
+	}
+	if r.MasterID == 0 || r.MinYears < 0 {
+		return false
+	}
+	return r.MinLevel == "" || r.MinLevel.IsValid()
+}
+
+func (JobRequirement) TableName() string {
+	return "job_requirements"
+}
@@ -0,0 +1,24 @@
+// job_schedule.go: job_schedulesテーブル用ドメインモデル
+// cron形式の定期実行スケジュール（次回実行時刻を過ぎたらジョブを登録）
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type JobSchedule struct {
+	ID        uint            `json:"-"`
+	Name      string          `json:"name"`
+	Cron      string          `json:"cron"` // 例: "*/10 * * * *"
+	JobType   string          `json:"job_type"`
+	Payload   json.RawMessage `json:"payload,omitempty" gorm:"type:text"`
+	NextRunAt time.Time       `json:"next_run_at"`
+	LastRunAt *time.Time      `json:"last_run_at,omitempty"`
+	CreatedAt time.Time       `json:"-"`
+	UpdatedAt time.Time       `json:"-"`
+}
+
+func (JobSchedule) TableName() string {
+	return "job_schedules"
+}
@@ -0,0 +1,24 @@
+// link_check.go: portfolio_link_checksテーブル用ドメインモデル
+// 職歴のポートフォリオURL（experiences.portfolio_url）の死活・メタデータの確認結果（URLごとに1件）
+package domain
+
+import "time"
+
+type LinkCheck struct {
+	ID          uint       `json:"-"`
+	URL         string     `json:"url"`
+	StatusCode  int        `json:"status_code"`            // 0は接続失敗等でレスポンスなし
+	RedirectURL string     `json:"redirect_url,omitempty"` // 最初のリダイレクト先（Location）
+	FinalURL    string     `json:"final_url,omitempty"`    // リダイレクト後の最終URL
+	Title       string     `json:"title,omitempty"`        // HTMLの<title>
+	Error       string     `json:"error,omitempty" gorm:"column:error_message"`
+	Broken      bool       `json:"broken"`     // 接続失敗またはステータス400以上
+	Failures    int        `json:"-"`          // 連続失敗回数（再確認のバックオフ用）
+	CheckedAt   *time.Time `json:"checked_at"` // nilは未確認
+	NextCheckAt time.Time  `json:"-"`
+	CreatedAt   time.Time  `json:"-"`
+}
+
+func (LinkCheck) TableName() string {
+	return "portfolio_link_checks"
+}
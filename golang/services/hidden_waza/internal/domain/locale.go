@@ -0,0 +1,53 @@
+// locale.go: Locale値オブジェクト（対応言語とフォールバック規則）
+package domain
+
+import "strings"
+
+type Locale string
+
+const (
+	LocaleJA Locale = "ja"
+	LocaleEN Locale = "en"
+
+	// DefaultLocaleは言語指定がない場合の既定言語
+	DefaultLocale = LocaleJA
+)
+
+// SupportedLocalesは翻訳を保持できる言語一覧
+var SupportedLocales = []Locale{LocaleJA, LocaleEN}
+
+// 言語タグ（"en", "en-US", "JA" 等）を対応言語に変換
+func ParseLocale(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	for _, l := range SupportedLocales {
+		if string(l) == tag {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+func (l Locale) IsValid() bool {
+	_, ok := ParseLocale(string(l))
+	return ok
+}
+
+// 翻訳フィールドのフォールバック規則
+// 指定言語の翻訳 → 原文（resumes等の元カラム） → 他言語の翻訳 の順で最初の非空値を返す
+func pickLocalized(translated, original string, others ...string) string {
+	if translated != "" {
+		return translated
+	}
+	if original != "" {
+		return original
+	}
+	for _, o := range others {
+		if o != "" {
+			return o
+		}
+	}
+	return ""
+}
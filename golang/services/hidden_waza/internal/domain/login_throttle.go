@@ -0,0 +1,49 @@
+// login_throttle.go: ログイン試行の制限（login_failures / login_locks / login_lockout_events）用ドメインモデル
+// キーはメールアドレスごとの"account:<メールアドレス>"とIPアドレスごとの"ip:<IPアドレス>"
+package domain
+
+import "time"
+
+// ログインの失敗（スライディングウィンドウ内の件数を数える）
+type LoginFailure struct {
+	ID        uint      `json:"id"`
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (LoginFailure) TableName() string {
+	return "login_failures"
+}
+
+// ログインの一時的な禁止（Levelは連続した禁止の回数。禁止時間はLevelごとに倍になる）
+type LoginLock struct {
+	Key         string    `json:"key" gorm:"primaryKey"`
+	Level       int       `json:"level"`
+	LockedUntil time.Time `json:"locked_until"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (LoginLock) TableName() string {
+	return "login_locks"
+}
+
+// 禁止・解除の記録
+const (
+	LoginLockoutLocked   = "locked"
+	LoginLockoutUnlocked = "unlocked" // 管理者による解除
+)
+
+type LoginLockoutEvent struct {
+	ID          uint       `json:"id"`
+	Action      string     `json:"action"`
+	Key         string     `json:"key"`
+	Level       int        `json:"level,omitempty"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	IP          string     `json:"ip"`                 // 禁止の契機になったリクエストのIPアドレス
+	ActorID     *uint      `json:"actor_id,omitempty"` // 解除した管理者
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (LoginLockoutEvent) TableName() string {
+	return "login_lockout_events"
+}
@@ -0,0 +1,24 @@
+// master_translation.go: master_translationsテーブル用ドメインモデル
+// languages/tools/osマスタの名称翻訳を種別+IDで保持する
+package domain
+
+type MasterTranslation struct {
+	ID         uint   `json:"id"`
+	MasterType string `json:"master_type"` // "language", "tool", "os"
+	MasterID   uint   `json:"master_id"`
+	Locale     Locale `json:"locale"`
+	Name       string `json:"name"`
+}
+
+func (t MasterTranslation) IsValid() bool {
+	switch t.MasterType {
+	case "language", "tool", "os":
+	default:
+		return false
+	}
+	return t.MasterID != 0 && t.Locale.IsValid() && t.Name != ""
+}
+
+func (MasterTranslation) TableName() string {
+	return "master_translations"
+}
@@ -0,0 +1,28 @@
+// mfa.go: 2段階認証（mfa_recovery_codes / mfa_required_roles）用ドメインモデル
+package domain
+
+import "time"
+
+// 2段階認証のリカバリーコード（認証アプリを使えない場合にTOTPのコードの代わりに1回のみ使用可能。SHA-256のハッシュのみ保存）
+type MFARecoveryCode struct {
+	ID        uint       `json:"id"`
+	UserID    uint       `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// 2段階認証を必須とする権限
+type MFARequiredRole struct {
+	Role      string    `json:"role" gorm:"primaryKey"`
+	CreatedBy *uint     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (MFARequiredRole) TableName() string {
+	return "mfa_required_roles"
+}
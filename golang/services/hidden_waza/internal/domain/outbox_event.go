@@ -0,0 +1,58 @@
+// outbox_event.go: outbox_eventsテーブル用ドメインモデル
+// 職務経歴書の変更と同じトランザクションで記録するイベント（トランザクショナルアウトボックス）。
+// ワーカーが未配信のイベントを取り出してWebhookの配信に振り分けます。
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// イベント種別
+const (
+	EventResumeCreated         = "resume.created"
+	EventResumeUpdated         = "resume.updated"
+	EventResumeDeleted         = "resume.deleted"
+	EventResumeVerifiedChanged = "resume.verified_changed" // verifiedフラグの変更
+)
+
+// EventTypesはWebhookで購読できるイベント種別の一覧です。
+var EventTypes = []string{
+	EventResumeCreated,
+	EventResumeUpdated,
+	EventResumeDeleted,
+	EventResumeVerifiedChanged,
+}
+
+// IsValidEventTypeはイベント種別が正しいかを返します。
+func IsValidEventType(t string) bool {
+	for _, e := range EventTypes {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+type OutboxEvent struct {
+	ID          uint            `json:"id"`
+	Type        string          `json:"type"`
+	ResumeID    uint            `json:"resume_id"`
+	UserID      uint            `json:"user_id"` // 職務経歴書の所有者（SSEの配信先）
+	Data        json.RawMessage `json:"data" gorm:"type:text"`
+	PublishedAt *time.Time      `json:"published_at,omitempty"` // nilは未配信
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// ResumeEventDataは職務経歴書のイベントのデータ（OutboxEvent.Data）です。
+type ResumeEventData struct {
+	ResumeID         uint   `json:"resume_id"`
+	UserID           uint   `json:"user_id"`
+	Title            string `json:"title,omitempty"`
+	Verified         bool   `json:"verified"`
+	PreviousVerified *bool  `json:"previous_verified,omitempty"` // resume.verified_changedのみ
+}
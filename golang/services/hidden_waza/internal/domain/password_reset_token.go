@@ -0,0 +1,18 @@
+// password_reset_token.go: password_reset_tokensテーブル用ドメインモデル
+// パスワード再設定のトークン（トークン自体は保存せず、SHA-256のハッシュのみ保存）
+package domain
+
+import "time"
+
+type PasswordResetToken struct {
+	ID        uint       `json:"id"`
+	UserID    uint       `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"` // 使用済み・再発行で無効化された日時
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
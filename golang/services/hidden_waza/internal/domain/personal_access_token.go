@@ -0,0 +1,70 @@
+// personal_access_token.go: personal_access_tokensテーブル用ドメインモデル
+// パーソナルアクセストークン（スクリプト・外部連携用。トークン自体は保存せず、SHA-256のハッシュのみ保存）
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// パーソナルアクセストークンの先頭（JWTと区別し、漏えい時に検出しやすくする）
+const AccessTokenPrefix = "hwz_"
+
+// パーソナルアクセストークンの権限
+const (
+	ScopeResumeRead  = "resume:read"  // 職務経歴書・添付ファイル・変更イベントの参照
+	ScopeResumeWrite = "resume:write" // 職務経歴書・添付ファイルの作成・更新・削除（resume:readを含む）
+)
+
+// 有効な権限の一覧
+var AccessTokenScopes = []string{ScopeResumeRead, ScopeResumeWrite}
+
+// 権限として有効な値か
+func IsValidScope(scope string) bool {
+	for _, s := range AccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type PersonalAccessToken struct {
+	ID     uint   `json:"id"`
+	UserID uint   `json:"user_id"`
+	Name   string `json:"name"`
+	// トークンの先頭部分（一覧でどのトークンかを識別する）
+	TokenPrefix string `json:"token_prefix"`
+	TokenHash   string `json:"-"`
+	// 権限（空白区切り。JSONではScopeList）
+	Scopes     string     `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (PersonalAccessToken) TableName() string {
+	return "personal_access_tokens"
+}
+
+// 権限の一覧
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// 権限を持つか（resume:writeはresume:readを含む）
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope || (s == ScopeResumeWrite && scope == ScopeResumeRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// 時刻nowに使用できるか（失効・期限切れでない）
+func (t *PersonalAccessToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
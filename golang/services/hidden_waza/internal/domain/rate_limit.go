@@ -0,0 +1,52 @@
+// rate_limit.go: APIのレート制限（トークンバケット）用ドメインモデル
+// キーは"<ポリシー名>:user:<ユーザーID>" / "<ポリシー名>:token:<トークンID>" / "<ポリシー名>:ip:<IPアドレス>"
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// トークンバケット（rate_limit.store: dbの場合のみ使用）
+type RateLimitBucket struct {
+	Key       string    `gorm:"primaryKey"`
+	Tokens    float64   // updated_at時点のトークン数
+	UpdatedAt time.Time // 最後にトークンを補充・消費した日時
+}
+
+func (RateLimitBucket) TableName() string {
+	return "rate_limit_buckets"
+}
+
+// RateLimitResultはバケットからトークンを取り出した結果です。
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int // バケットの容量
+	Remaining int // 残りのトークン数
+	// 次のトークンが補充されるまでの時間（Allowedがfalseの場合のみ）
+	RetryAfter time.Duration
+	// バケットが満杯になるまでの時間
+	Reset time.Duration
+}
+
+// NewRateLimitResultは取り出し後のトークン数tokensから結果を返します（perSecondは1秒あたりの補充数）。
+func NewRateLimitResult(allowed bool, tokens, perSecond float64, burst int) *RateLimitResult {
+	tokens = math.Max(tokens, 0)
+	res := &RateLimitResult{
+		Allowed:   allowed,
+		Limit:     burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     secondsDuration((float64(burst) - tokens) / perSecond),
+	}
+	if !allowed {
+		res.RetryAfter = secondsDuration((1 - tokens) / perSecond)
+	}
+	return res
+}
+
+func secondsDuration(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
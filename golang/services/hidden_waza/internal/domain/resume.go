@@ -47,6 +47,8 @@ type Resume struct {
 	CreatedAt   time.Time    `json:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at"`
 	Verified    bool         `json:"verified"`
+	// Title/Summaryの言語別翻訳（元カラムは原文として扱う）
+	Translations []ResumeTranslation `json:"translations,omitempty" gorm:"foreignKey:ResumeID;constraint:OnDelete:CASCADE"`
 }
 
 /// 職務経歴書の業務的バリデーション
@@ -81,6 +83,38 @@ func (r *Resume) RemoveSkill(skillID uint) {
 	r.Skills = newSkills
 }
 
+// 指定言語のTranslationを取得
+func (r *Resume) Translation(loc Locale) (ResumeTranslation, bool) {
+	for _, t := range r.Translations {
+		if t.Locale == loc {
+			return t, true
+		}
+	}
+	return ResumeTranslation{}, false
+}
+
+// 指定言語で表示するためのコピーを返す（職歴も含めてフォールバック規則を適用）
+func (r Resume) Localized(loc Locale) Resume {
+	tr, _ := r.Translation(loc)
+	var otherTitles, otherSummaries []string
+	for _, t := range r.Translations {
+		if t.Locale != loc {
+			otherTitles = append(otherTitles, t.Title)
+			otherSummaries = append(otherSummaries, t.Summary)
+		}
+	}
+	out := r
+	out.Title = pickLocalized(tr.Title, r.Title, otherTitles...)
+	out.Summary = pickLocalized(tr.Summary, r.Summary, otherSummaries...)
+	if r.Experiences != nil {
+		out.Experiences = make([]Experience, len(r.Experiences))
+		for i, e := range r.Experiences {
+			out.Experiences[i] = e.Localized(loc)
+		}
+	}
+	return out
+}
+
 func (Resume) TableName() string {
 	return "resumes"
 }
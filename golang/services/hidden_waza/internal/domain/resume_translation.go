@@ -0,0 +1,14 @@
+// resume_translation.go: resume_translationsテーブル用ドメインモデル
+package domain
+
+type ResumeTranslation struct {
+	ID       uint   `json:"id"`
+	ResumeID uint   `json:"resume_id"`
+	Locale   Locale `json:"locale"`
+	Title    string `json:"title"`
+	Summary  string `json:"summary"`
+}
+
+func (ResumeTranslation) TableName() string {
+	return "resume_translations"
+}
@@ -0,0 +1,18 @@
+// similar_resume.go: 類似職務経歴書の推薦結果（総合スコアと内訳）
+package domain
+
+type SimilarResume struct {
+	ResumeID     uint          `json:"resume_id"`
+	UserID       uint          `json:"user_id"`
+	Title        string        `json:"title"`
+	Score        float64       `json:"score"`       // 0〜100
+	SkillScore   float64       `json:"skill_score"` // スキルベクトルのコサイン類似度（0〜1）
+	TextScore    float64       `json:"text_score"`  // 本文TF-IDFのコサイン類似度（0〜1）
+	SharedSkills []SharedSkill `json:"shared_skills"`
+}
+
+// SharedSkillは両方の職務経歴書に登録されているスキル
+type SharedSkill struct {
+	Type     string `json:"type"`
+	MasterID uint   `json:"master_id"`
+}
@@ -2,16 +2,16 @@
 package domain
 
 type Skill struct {
-	ID       uint   `json:"id"`
-	ResumeID uint   `json:"resume_id"`
-	Type     string `json:"type"`      // "language", "tool", "os"
-	MasterID uint   `json:"master_id"` // languages/tools/osのid
-	Level    string `json:"level"`
-	Years    int    `json:"years"`
+	ID       uint       `json:"id"`
+	ResumeID uint       `json:"resume_id"`
+	Type     string     `json:"type"`      // "language", "tool", "os"
+	MasterID uint       `json:"master_id"` // languages/tools/osのid
+	Level    SkillLevel `json:"level"`
+	Years    int        `json:"years"`
 }
 
 func (s Skill) IsValid() bool {
-	if s.Type == "" || s.Level == "" || s.Years < 0 {
+	if s.Type == "" || !s.Level.IsValid() || s.Years < 0 {
 		return false
 	}
 	return true
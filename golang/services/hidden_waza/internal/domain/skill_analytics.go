@@ -0,0 +1,45 @@
+// skill_analytics.go: スキル集計（利用数ランキング・共起・分布・推移）の結果
+package domain
+
+// SkillCountはスキル1件の利用状況
+type SkillCount struct {
+	Type     string  `json:"type"`
+	MasterID uint    `json:"master_id"`
+	Name     string  `json:"name"`
+	Resumes  int     `json:"resumes"`   // そのスキルを持つ職務経歴書数
+	AvgYears float64 `json:"avg_years"` // 平均経験年数
+	Share    float64 `json:"share"`     // 母数に対する割合（0〜100）
+}
+
+// CoOccurrenceMatrixはスキル同士の共起行列（Counts[i][j] = スキルiとjを両方持つ職務経歴書数、対角はスキルの保有数）
+type CoOccurrenceMatrix struct {
+	Skills []SkillCount `json:"skills"`
+	Counts [][]int      `json:"counts"`
+}
+
+// CoOccurringSkillsは特定スキルと一緒に登録されているスキル（Shareは基準スキル保有数に対する割合）
+type CoOccurringSkills struct {
+	Skill  SkillCount   `json:"skill"`
+	Skills []SkillCount `json:"co_occurring"`
+}
+
+// SkillHistogramはレベル・経験年数の分布（スキル登録件数）
+type SkillHistogram struct {
+	Total  int               `json:"total"`
+	Levels []HistogramBucket `json:"levels"`
+	Years  []HistogramBucket `json:"years"`
+}
+
+// HistogramBucketは分布の1区間
+type HistogramBucket struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// TrendPointは作成日時（created_at）の期間ごとの集計
+type TrendPoint struct {
+	Period    string   `json:"period"`               // "2025-07"（月）/ "2025"（年）
+	Resumes   int      `json:"resumes"`              // 期間内に作成された職務経歴書数
+	WithSkill *int     `json:"with_skill,omitempty"` // スキル指定時: そのスキルを持つ数
+	Share     *float64 `json:"share,omitempty"`      // スキル指定時: 割合（0〜100）
+}
@@ -0,0 +1,33 @@
+// skill_consistency.go: 自己申告のスキル経験年数と職歴に基づく経験年数の突き合わせ結果
+package domain
+
+// 警告コード
+const (
+	ConsistencyExceedsEvidence = "exceeds_evidence" // 申告年数が職歴で裏付けられる年数を超えている
+	ConsistencyNoEvidence      = "no_evidence"      // 職歴で一度も使用されていない
+)
+
+type SkillConsistency struct {
+	Skills   []SkillEvidence      `json:"skills"`
+	Warnings []ConsistencyWarning `json:"warnings"`
+}
+
+// SkillEvidenceは申告スキルごとの裏付け状況
+type SkillEvidence struct {
+	Type            string  `json:"type"`
+	MasterID        uint    `json:"master_id"`
+	DeclaredYears   int     `json:"declared_years"`
+	SupportedMonths int     `json:"supported_months"` // 使用職歴の期間をマージした月数
+	SupportedYears  float64 `json:"supported_years"`
+	ExperienceIDs   []uint  `json:"experience_ids"`
+}
+
+// ConsistencyWarningは申告と職歴の不整合
+type ConsistencyWarning struct {
+	Code           string  `json:"code"`
+	Type           string  `json:"type"`
+	MasterID       uint    `json:"master_id"`
+	DeclaredYears  int     `json:"declared_years"`
+	SupportedYears float64 `json:"supported_years"`
+	Message        string  `json:"message"`
+}
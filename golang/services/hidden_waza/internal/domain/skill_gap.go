@@ -0,0 +1,40 @@
+// skill_gap.go: 職務経歴書と目標スキルのギャップ分析結果（スキル種別ごと）
+package domain
+
+// SkillGapは職務経歴書と目標スキルのギャップ分析結果
+type SkillGap struct {
+	ResumeID uint            `json:"resume_id"`
+	Groups   []SkillGapGroup `json:"groups"` // language, tool, os の順
+	Summary  SkillGapSummary `json:"summary"`
+}
+
+// SkillGapGroupはスキル種別1つ分の分類結果
+type SkillGapGroup struct {
+	Type    string         `json:"type"`
+	Missing []SkillGapItem `json:"missing"` // 目標にあるが職務経歴書にない
+	Below   []SkillGapItem `json:"below"`   // 職務経歴書にあるが年数またはレベルが不足
+	Met     []SkillGapItem `json:"met"`     // 目標を満たす
+	Surplus []SkillGapItem `json:"surplus"` // 職務経歴書にあるが目標にない
+}
+
+// SkillGapItemはスキル1件の判定内容
+type SkillGapItem struct {
+	MasterID       uint       `json:"master_id"`
+	Name           string     `json:"name"`
+	MinYears       int        `json:"min_years"`
+	MinLevel       SkillLevel `json:"min_level,omitempty"`
+	ActualYears    int        `json:"actual_years"`
+	ActualLevel    SkillLevel `json:"actual_level,omitempty"`
+	YearsShortfall int        `json:"years_shortfall"` // 不足年数
+	LevelShortfall int        `json:"level_shortfall"` // 不足段階数（SkillLevel.Ordinalの差）
+}
+
+// SkillGapSummaryは分類ごとの件数と目標の充足率
+type SkillGapSummary struct {
+	Targets  int     `json:"targets"`
+	Missing  int     `json:"missing"`
+	Below    int     `json:"below"`
+	Met      int     `json:"met"`
+	Surplus  int     `json:"surplus"`
+	Coverage float64 `json:"coverage"` // 目標を満たす割合（0〜100）
+}
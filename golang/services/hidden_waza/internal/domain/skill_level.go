@@ -0,0 +1,89 @@
+// skill_level.go: SkillLevel値オブジェクト（スキル習熟度の段階定義）
+//
+// DBには正規化したコード（beginner/intermediate/advanced/expert）を保存し、
+// 比較・並び替えはOrdinal()の数値で行う。入力時は旧来の表記（"上級"等）も別名として受け付ける。
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+type SkillLevel string
+
+const (
+	SkillLevelBeginner     SkillLevel = "beginner"
+	SkillLevelIntermediate SkillLevel = "intermediate"
+	SkillLevelAdvanced     SkillLevel = "advanced"
+	SkillLevelExpert       SkillLevel = "expert"
+)
+
+var ErrInvalidSkillLevel = errors.New("invalid skill level")
+
+// SkillLevelsは習熟度の低い順の一覧
+var SkillLevels = []SkillLevel{
+	SkillLevelBeginner,
+	SkillLevelIntermediate,
+	SkillLevelAdvanced,
+	SkillLevelExpert,
+}
+
+// 入力時に受け付ける別名（小文字・前後空白除去後に照合）
+var skillLevelAliases = map[string]SkillLevel{
+	"beginner": SkillLevelBeginner, "basic": SkillLevelBeginner, "novice": SkillLevelBeginner,
+	"junior": SkillLevelBeginner, "初級": SkillLevelBeginner, "初心者": SkillLevelBeginner,
+	"入門": SkillLevelBeginner, "基礎": SkillLevelBeginner, "1": SkillLevelBeginner,
+
+	"intermediate": SkillLevelIntermediate, "middle": SkillLevelIntermediate, "mid": SkillLevelIntermediate,
+	"中級": SkillLevelIntermediate, "2": SkillLevelIntermediate,
+
+	"advanced": SkillLevelAdvanced, "senior": SkillLevelAdvanced, "上級": SkillLevelAdvanced,
+	"3": SkillLevelAdvanced,
+
+	"expert": SkillLevelExpert, "master": SkillLevelExpert, "エキスパート": SkillLevelExpert,
+	"熟練": SkillLevelExpert, "専門家": SkillLevelExpert, "4": SkillLevelExpert,
+}
+
+var skillLevelLabels = map[SkillLevel]map[Locale]string{
+	SkillLevelBeginner:     {LocaleJA: "初級", LocaleEN: "Beginner"},
+	SkillLevelIntermediate: {LocaleJA: "中級", LocaleEN: "Intermediate"},
+	SkillLevelAdvanced:     {LocaleJA: "上級", LocaleEN: "Advanced"},
+	SkillLevelExpert:       {LocaleJA: "エキスパート", LocaleEN: "Expert"},
+}
+
+// 正規コード・別名からSkillLevelを生成
+func ParseSkillLevel(s string) (SkillLevel, error) {
+	if l, ok := skillLevelAliases[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return l, nil
+	}
+	return "", ErrInvalidSkillLevel
+}
+
+func (l SkillLevel) IsValid() bool {
+	return l.Ordinal() > 0
+}
+
+// 段階の序数（beginner=1 〜 expert=4、未分類は0）
+func (l SkillLevel) Ordinal() int {
+	for i, v := range SkillLevels {
+		if v == l {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// lがother以上の習熟度か
+func (l SkillLevel) AtLeast(other SkillLevel) bool {
+	return l.Ordinal() >= other.Ordinal()
+}
+
+// 表示用ラベル（未分類なら保存値のまま）
+func (l SkillLevel) Label(loc Locale) string {
+	if labels, ok := skillLevelLabels[l]; ok {
+		if label, ok := labels[loc]; ok {
+			return label
+		}
+	}
+	return string(l)
+}
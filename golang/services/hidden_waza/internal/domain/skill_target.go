@@ -0,0 +1,21 @@
+// skill_target.go: スキルギャップ分析の目標スキル（目標ロールが求めるスキルと最低年数・最低レベル）
+package domain
+
+type SkillTarget struct {
+	Type     string     `json:"type"`      // "language", "tool", "os"
+	MasterID uint       `json:"master_id"` // languages/tools/osのid
+	MinYears int        `json:"min_years"`
+	MinLevel SkillLevel `json:"min_level,omitempty"` // 空なら不問
+}
+
+func (t SkillTarget) IsValid() bool {
+	switch t.Type {
+	case "language", "tool", "os":
+	default:
+		return false
+	}
+	if t.MasterID == 0 || t.MinYears < 0 {
+		return false
+	}
+	return t.MinLevel == "" || t.MinLevel.IsValid()
+}
@@ -1,15 +1,57 @@
 // user.go: usersテーブル用ドメインモデル
 package domain
 
+import "time"
+
+// ユーザーの権限
+const (
+	RoleUser     = "user"
+	RoleVerifier = "verifier" // 職務経歴書の内容を検証する担当者
+	RoleAdmin    = "admin"    // 管理API（/api/v1/admin）を利用可能
+)
+
+// 権限として有効な値か
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleUser, RoleVerifier, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// メールアドレスの確認状態
+const (
+	EmailPending  = "pending"  // 確認メールのリンク（トークン）が未使用
+	EmailVerified = "verified" // 確認済み
+)
+
 type User struct {
 	ID           uint         `json:"id"`
 	Username     string       `json:"username"`
 	Email        Email        `json:"email"`
 	PasswordHash PasswordHash `json:"password_hash"`
-	CreatedAt    string       `json:"created_at"`
-	UpdatedAt    string       `json:"updated_at"`
+	Role         string       `json:"role" gorm:"default:user"`
+	Locale       Locale       `json:"locale" gorm:"default:ja"` // メールの言語
+	EmailStatus  string       `json:"email_status" gorm:"default:pending"`
+	// メールアドレスの確認日時（未確認ならnil）
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// JWTの世代（パスワードの再設定で1増やし、それ以前に発行したJWTを無効にする）
+	TokenVersion int `json:"-"`
+	// TOTPによる2段階認証が有効か
+	MFAEnabled bool `json:"mfa_enabled"`
+	// TOTPの秘密鍵（Base32。MFAEnabledがfalseの間は登録中の秘密鍵）
+	MFASecret *string `json:"-"`
+	// 最後に使用したTOTPのコードの時間ステップ（同じコードの再利用を防ぐ）
+	MFALastStep int64  `json:"-"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
 }
 
 func (User) TableName() string {
 	return "users"
 }
+
+// メールアドレスが確認済みか
+func (u *User) IsEmailVerified() bool {
+	return u.EmailStatus == EmailVerified
+}
@@ -0,0 +1,38 @@
+// user_identity.go: user_identities / oidc_auth_requestsテーブル用ドメインモデル
+// 外部IdP（OpenID Connect）のアカウントとの紐付けと、ログイン・紐付けの認可リクエスト
+package domain
+
+import "time"
+
+// 外部IdPのアカウントとの紐付け（ProviderはIdPの設定名、SubjectはIdPのsubクレーム）
+type UserIdentity struct {
+	ID          uint       `json:"id"`
+	UserID      uint       `json:"user_id"`
+	Provider    string     `json:"provider"`
+	Subject     string     `json:"subject"`
+	Email       string     `json:"email"` // 紐付け・最後のログイン時のIdPのメールアドレス
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// 認可リクエスト（stateはハッシュのみ保存。nonce・PKCEのcode_verifierはコールバックで使用）
+type OIDCAuthRequest struct {
+	ID           uint   `json:"id"`
+	StateHash    string `json:"-"`
+	Provider     string `json:"provider"`
+	Nonce        string `json:"-"`
+	CodeVerifier string `json:"-"`
+	// ログイン中のユーザーが紐付けを開始した場合のユーザー（ログインならnil）
+	LinkUserID *uint      `json:"link_user_id"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	UsedAt     *time.Time `json:"used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (OIDCAuthRequest) TableName() string {
+	return "oidc_auth_requests"
+}
@@ -0,0 +1,17 @@
+// user_login_ip.go: user_login_ipsテーブル用ドメインモデル
+// ユーザーがログインに使ったIPアドレス（新しいIPアドレスからのログインの判定用）
+package domain
+
+import "time"
+
+type UserLoginIP struct {
+	ID          uint      `json:"id"`
+	UserID      uint      `json:"user_id"`
+	IP          string    `json:"ip"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+func (UserLoginIP) TableName() string {
+	return "user_login_ips"
+}
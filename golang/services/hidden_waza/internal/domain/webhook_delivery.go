@@ -0,0 +1,32 @@
+// webhook_delivery.go: webhook_deliveriesテーブル用ドメインモデル
+// イベント1件を購読1件に配信する単位（再試行はジョブキューで行い、各試行はWebhookDeliveryAttemptに記録）
+package domain
+
+import "time"
+
+// 配信の状態
+const (
+	DeliveryPending   = "pending"   // 配信待ち・再試行待ち
+	DeliverySucceeded = "succeeded" // 2xxの応答
+	DeliveryFailed    = "failed"    // 再試行の上限に達した、または購読が削除・無効化された
+)
+
+type WebhookDelivery struct {
+	ID             uint                     `json:"id"`
+	SubscriptionID uint                     `json:"subscription_id"`
+	EventID        uint                     `json:"event_id"`
+	EventType      string                   `json:"event_type"`
+	Status         string                   `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	LastStatusCode int                      `json:"last_status_code,omitempty"`
+	LastError      string                   `json:"last_error,omitempty"`
+	RedeliveryOf   *uint                    `json:"redelivery_of,omitempty"` // 再配信の元の配信ID
+	DeliveredAt    *time.Time               `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+	AttemptLogs    []WebhookDeliveryAttempt `json:"attempt_logs,omitempty" gorm:"foreignKey:DeliveryID"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,23 @@
+// webhook_delivery_attempt.go: webhook_delivery_attemptsテーブル用ドメインモデル
+// 配信の試行1回分のログ（送信したリクエストと受け取ったレスポンス）
+package domain
+
+import "time"
+
+type WebhookDeliveryAttempt struct {
+	ID              uint      `json:"id"`
+	DeliveryID      uint      `json:"delivery_id"`
+	Attempt         int       `json:"attempt"`
+	RequestHeaders  string    `json:"request_headers"`
+	RequestBody     string    `json:"request_body" gorm:"type:text"`
+	StatusCode      int       `json:"status_code"` // 0は接続失敗等でレスポンスなし
+	ResponseHeaders string    `json:"response_headers,omitempty"`
+	ResponseBody    string    `json:"response_body,omitempty" gorm:"type:text"` // 先頭のみ
+	Error           string    `json:"error,omitempty" gorm:"column:error_message"`
+	DurationMS      int64     `json:"duration_ms" gorm:"column:duration_ms"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (WebhookDeliveryAttempt) TableName() string {
+	return "webhook_delivery_attempts"
+}
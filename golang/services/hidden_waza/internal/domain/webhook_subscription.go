@@ -0,0 +1,44 @@
+// webhook_subscription.go: webhook_subscriptionsテーブル用ドメインモデル
+// イベントの送信先URLと購読するイベント種別（Eventsはカンマ区切り、"*"は全イベント）
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// 全イベントを購読する場合のEventsの値
+const AllEvents = "*"
+
+type WebhookSubscription struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"-"` // 署名（HMAC-SHA256）の鍵
+	Events      string    `json:"-"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// EventListは購読するイベント種別の一覧を返します。
+func (s *WebhookSubscription) EventList() []string {
+	if s.Events == "" {
+		return []string{}
+	}
+	return strings.Split(s.Events, ",")
+}
+
+// Matchesはイベント種別を購読しているかを返します。
+func (s *WebhookSubscription) Matches(eventType string) bool {
+	for _, e := range s.EventList() {
+		if e == AllEvents || e == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,302 @@
+/*
+docx_exporter.go
+
+職務経歴書（Resume集約）をWord形式（.docx / Office Open XML）で出力するエクスポーター。
+外部ライブラリは使わず、archive/zip と XML文字列の組み立てのみで最小構成のパッケージを生成します。
+
+生成するパート:
+- [Content_Types].xml / _rels/.rels … パッケージ定義
+- word/document.xml                … 本文（見出し・職務経歴テーブル・スキルマトリクス）
+- word/styles.xml                  … 見出し・表スタイル定義
+- docProps/core.xml                … タイトル等のメタデータ
+*/
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+)
+
+// DocxExporterは職務経歴書をdocx形式で書き出す
+type DocxExporter struct {
+	now func() time.Time
+}
+
+// NewDocxExporterはDocxExporterを生成します。
+func NewDocxExporter() *DocxExporter {
+	return &DocxExporter{now: time.Now}
+}
+
+// ContentTypeはdocxのMIMEタイプを返します。
+func (e *DocxExporter) ContentType() string {
+	return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+}
+
+// Exportは職務経歴書をdocxとしてwに書き出します。
+func (e *DocxExporter) Export(w io.Writer, resume *domain.Resume, names MasterNames, opt Options) error {
+	opt = opt.normalize()
+	zw := zip.NewWriter(w)
+
+	parts := []struct {
+		name string
+		body string
+	}{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRootRels},
+		{"word/_rels/document.xml.rels", docxDocumentRels},
+		{"word/styles.xml", docxStyles},
+		{"docProps/core.xml", e.coreProps(resume)},
+		{"word/document.xml", e.document(resume, names, opt)},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// 本文（word/document.xml）の組み立て
+func (e *DocxExporter) document(resume *domain.Resume, names MasterNames, opt Options) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+
+	writeParagraph(&b, "Title", label(opt.Lang, "resume"))
+	writeParagraph(&b, "Heading1", resume.Title)
+
+	if resume.Summary != "" {
+		writeParagraph(&b, "Heading2", label(opt.Lang, "summary"))
+		for _, line := range strings.Split(resume.Summary, "\n") {
+			writeParagraph(&b, "", line)
+		}
+	}
+
+	if opt.Layout == LayoutSkill {
+		e.writeSkills(&b, resume.Skills, names, opt)
+		e.writeExperiences(&b, resume.Experiences, opt)
+	} else {
+		e.writeExperiences(&b, resume.Experiences, opt)
+		e.writeSkills(&b, resume.Skills, names, opt)
+	}
+
+	// A4縦・余白約20mm
+	b.WriteString(`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134" w:header="567" w:footer="567" w:gutter="0"/></w:sectPr>`)
+	b.WriteString(`</w:body></w:document>`)
+	return b.String()
+}
+
+// 職務経歴テーブル
+// 編年体レイアウトでは新しい順、スキル重視レイアウトでは古い順に並べる
+func (e *DocxExporter) writeExperiences(b *strings.Builder, exps []domain.Experience, opt Options) {
+	if len(exps) == 0 {
+		return
+	}
+	now := e.now()
+	sorted := make([]domain.Experience, len(exps))
+	copy(sorted, exps)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		si, sj := sorted[i].StartDate.FirstDay(now), sorted[j].StartDate.FirstDay(now)
+		if opt.Layout == LayoutChronological {
+			return si.After(sj)
+		}
+		return si.Before(sj)
+	})
+
+	writeParagraph(b, "Heading2", label(opt.Lang, "experiences"))
+	header := []string{
+		label(opt.Lang, "period"),
+		label(opt.Lang, "company"),
+		label(opt.Lang, "position"),
+		label(opt.Lang, "description"),
+	}
+	rows := make([][]string, 0, len(sorted))
+	for _, exp := range sorted {
+		desc := exp.Description
+		if exp.PortfolioURL != "" {
+			desc += "\n" + label(opt.Lang, "portfolio") + ": " + exp.PortfolioURL
+		}
+		rows = append(rows, []string{
+			formatPeriod(exp.StartDate, exp.EndDate, opt.Lang),
+			exp.Company,
+			exp.Position,
+			desc,
+		})
+	}
+	writeTable(b, header, rows, []int{1800, 2000, 1800, 4038})
+}
+
+// スキルマトリクス（種別・名称・レベル・経験年数）
+// スキル重視レイアウトでは経験年数の長い順、編年体では種別順に並べる（同年数はレベルの高い順）
+func (e *DocxExporter) writeSkills(b *strings.Builder, skills []domain.Skill, names MasterNames, opt Options) {
+	if len(skills) == 0 {
+		return
+	}
+	sorted := make([]domain.Skill, len(skills))
+	copy(sorted, skills)
+	typeOrder := map[string]int{"language": 0, "tool": 1, "os": 2}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if opt.Layout == LayoutSkill && sorted[i].Years != sorted[j].Years {
+			return sorted[i].Years > sorted[j].Years
+		}
+		if sorted[i].Type != sorted[j].Type {
+			return typeOrder[sorted[i].Type] < typeOrder[sorted[j].Type]
+		}
+		if sorted[i].Years != sorted[j].Years {
+			return sorted[i].Years > sorted[j].Years
+		}
+		return sorted[i].Level.Ordinal() > sorted[j].Level.Ordinal()
+	})
+
+	writeParagraph(b, "Heading2", label(opt.Lang, "skills"))
+	header := []string{
+		label(opt.Lang, "type"),
+		label(opt.Lang, "name"),
+		label(opt.Lang, "level"),
+		label(opt.Lang, "years"),
+	}
+	rows := make([][]string, 0, len(sorted))
+	for _, s := range sorted {
+		rows = append(rows, []string{
+			label(opt.Lang, s.Type),
+			names.Name(s.Type, s.MasterID),
+			s.Level.Label(domain.Locale(opt.Lang)),
+			strconv.Itoa(s.Years) + " " + label(opt.Lang, "yearsUnit"),
+		})
+	}
+	writeTable(b, header, rows, []int{1600, 4038, 2000, 2000})
+}
+
+// docProps/core.xml
+func (e *DocxExporter) coreProps(resume *domain.Resume) string {
+	now := e.now().UTC().Format(time.RFC3339)
+	return xml.Header +
+		`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">` +
+		`<dc:title>` + escape(resume.Title) + `</dc:title>` +
+		`<dc:creator>hidden_waza</dc:creator>` +
+		`<dcterms:created xsi:type="dcterms:W3CDTF">` + now + `</dcterms:created>` +
+		`<dcterms:modified xsi:type="dcterms:W3CDTF">` + now + `</dcterms:modified>` +
+		`</cp:coreProperties>`
+}
+
+// 段落の書き出し（styleが空なら標準スタイル）。改行はw:brに変換
+func writeParagraph(b *strings.Builder, style, text string) {
+	b.WriteString(`<w:p>`)
+	if style != "" {
+		b.WriteString(`<w:pPr><w:pStyle w:val="` + style + `"/></w:pPr>`)
+	}
+	writeRun(b, text, false)
+	b.WriteString(`</w:p>`)
+}
+
+func writeRun(b *strings.Builder, text string, bold bool) {
+	b.WriteString(`<w:r>`)
+	if bold {
+		b.WriteString(`<w:rPr><w:b/></w:rPr>`)
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			b.WriteString(`<w:br/>`)
+		}
+		b.WriteString(`<w:t xml:space="preserve">` + escape(line) + `</w:t>`)
+	}
+	b.WriteString(`</w:r>`)
+}
+
+// 表の書き出し（1行目はヘッダー行として太字・繰り返し表示）
+func writeTable(b *strings.Builder, header []string, rows [][]string, widths []int) {
+	b.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="0" w:type="auto"/></w:tblPr><w:tblGrid>`)
+	for _, w := range widths {
+		b.WriteString(`<w:gridCol w:w="` + strconv.Itoa(w) + `"/>`)
+	}
+	b.WriteString(`</w:tblGrid>`)
+	writeRow(b, header, widths, true)
+	for _, row := range rows {
+		writeRow(b, row, widths, false)
+	}
+	b.WriteString(`</w:tbl>`)
+	// 表の直後に空段落を置かないとWordで表同士が結合されるため
+	b.WriteString(`<w:p/>`)
+}
+
+func writeRow(b *strings.Builder, cells []string, widths []int, header bool) {
+	b.WriteString(`<w:tr>`)
+	if header {
+		b.WriteString(`<w:trPr><w:tblHeader/></w:trPr>`)
+	}
+	for i, cell := range cells {
+		b.WriteString(`<w:tc><w:tcPr><w:tcW w:w="` + strconv.Itoa(widths[i]) + `" w:type="dxa"/>`)
+		if header {
+			b.WriteString(`<w:shd w:val="clear" w:color="auto" w:fill="D9E2F3"/>`)
+		}
+		b.WriteString(`</w:tcPr><w:p>`)
+		writeRun(b, cell, header)
+		b.WriteString(`</w:p></w:tc>`)
+	}
+	b.WriteString(`</w:tr>`)
+}
+
+// 期間表記（2020-01-15〜現在 → "2020/01 - 現在"）
+func formatPeriod(start, end domain.CareerDate, lang string) string {
+	return formatYearMonth(start, lang) + " - " + formatYearMonth(end, lang)
+}
+
+func formatYearMonth(d domain.CareerDate, lang string) string {
+	if d.IsPresent() {
+		return label(lang, "present")
+	}
+	return d.Date.Format("2006/01")
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+	`</Types>`
+
+const docxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// 和文フォントは游明朝/游ゴシック、欧文はCentury/Arialを指定
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Century" w:hAnsi="Century" w:eastAsia="Yu Mincho"/><w:sz w:val="21"/><w:lang w:val="ja-JP" w:eastAsia="ja-JP"/></w:rPr></w:rPrDefault>` +
+	`<w:pPrDefault><w:pPr><w:spacing w:after="60"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="center"/><w:spacing w:after="240"/></w:pPr><w:rPr><w:rFonts w:ascii="Arial" w:hAnsi="Arial" w:eastAsia="Yu Gothic"/><w:b/><w:sz w:val="36"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="240" w:after="120"/><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:rFonts w:ascii="Arial" w:hAnsi="Arial" w:eastAsia="Yu Gothic"/><w:b/><w:sz w:val="28"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:pPr><w:keepNext/><w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="auto"/></w:pBdr><w:spacing w:before="240" w:after="120"/><w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:rFonts w:ascii="Arial" w:hAnsi="Arial" w:eastAsia="Yu Gothic"/><w:b/><w:sz w:val="24"/></w:rPr></w:style>` +
+	`<w:style w:type="table" w:styleId="TableGrid"><w:name w:val="Table Grid"/><w:tblPr><w:tblBorders>` +
+	`<w:top w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:left w:val="single" w:sz="4" w:space="0" w:color="auto"/>` +
+	`<w:bottom w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:right w:val="single" w:sz="4" w:space="0" w:color="auto"/>` +
+	`<w:insideH w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:insideV w:val="single" w:sz="4" w:space="0" w:color="auto"/>` +
+	`</w:tblBorders><w:tblCellMar><w:left w:w="108" w:type="dxa"/><w:right w:w="108" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>` +
+	`</w:styles>`
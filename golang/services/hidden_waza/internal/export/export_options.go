@@ -0,0 +1,27 @@
+// export_options.go: エクスポート共通オプション（見出し言語・レイアウト）
+package export
+
+// Layoutは職務経歴書の構成（編年体 or スキル重視）を表す
+type Layout string
+
+const (
+	LayoutChronological Layout = "chronological" // 職歴を先頭に新しい順で記載
+	LayoutSkill         Layout = "skill"         // スキルマトリクスを先頭に記載
+)
+
+// Optionsはエクスポート時の出力オプション
+type Options struct {
+	Lang   string // 見出しの言語（"ja" / "en"）
+	Layout Layout
+}
+
+// 未指定・不正値をデフォルト（ja・編年体）に補正
+func (o Options) normalize() Options {
+	if o.Lang != "en" {
+		o.Lang = "ja"
+	}
+	if o.Layout != LayoutSkill {
+		o.Layout = LayoutChronological
+	}
+	return o
+}
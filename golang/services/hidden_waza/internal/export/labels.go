@@ -0,0 +1,53 @@
+// labels.go: エクスポート時の見出し文言（ja/en）
+package export
+
+var labels = map[string]map[string]string{
+	"ja": {
+		"resume":      "職務経歴書",
+		"summary":     "職務要約",
+		"experiences": "職務経歴",
+		"skills":      "スキル",
+		"period":      "期間",
+		"company":     "会社名",
+		"position":    "役職",
+		"description": "業務内容",
+		"portfolio":   "ポートフォリオ",
+		"type":        "種別",
+		"name":        "名称",
+		"level":       "レベル",
+		"years":       "経験年数",
+		"present":     "現在",
+		"language":    "言語",
+		"tool":        "ツール",
+		"os":          "OS",
+		"yearsUnit":   "年",
+	},
+	"en": {
+		"resume":      "Resume",
+		"summary":     "Summary",
+		"experiences": "Work Experience",
+		"skills":      "Skills",
+		"period":      "Period",
+		"company":     "Company",
+		"position":    "Position",
+		"description": "Description",
+		"portfolio":   "Portfolio",
+		"type":        "Type",
+		"name":        "Name",
+		"level":       "Level",
+		"years":       "Years",
+		"present":     "Present",
+		"language":    "Language",
+		"tool":        "Tool",
+		"os":          "OS",
+		"yearsUnit":   "yrs",
+	},
+}
+
+// 言語・キーから見出しを取得（未定義ならキーをそのまま返す）
+func label(lang, key string) string {
+	if l, ok := labels[lang][key]; ok {
+		return l
+	}
+	return key
+}
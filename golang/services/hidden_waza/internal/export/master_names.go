@@ -0,0 +1,17 @@
+// master_names.go: スキルのマスタID→表示名の対応表
+package export
+
+import "strconv"
+
+// MasterNamesはスキル種別（"language", "tool", "os"）ごとのマスタID→名称マップ
+type MasterNames map[string]map[uint]string
+
+// 種別・IDから名称を取得（未登録ならID表記）
+func (m MasterNames) Name(skillType string, id uint) string {
+	if names, ok := m[skillType]; ok {
+		if name, ok := names[id]; ok {
+			return name
+		}
+	}
+	return "#" + strconv.FormatUint(uint64(id), 10)
+}
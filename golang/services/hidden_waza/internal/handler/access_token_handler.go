@@ -0,0 +1,182 @@
+// access_token_handler.go: パーソナルアクセストークンの管理APIハンドラ（/api/v1/me/tokens、JWTのみ）と認証ミドルウェア
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+// RequireAuthOrAccessTokenはAuthorization: Bearerの値がパーソナルアクセストークン（hwz_で始まる）ならトークンで、
+// それ以外はrequireAuth（JWT）で認証するミドルウェアです。トークンはscopeの権限を持たなければ403とします。
+// トークンで認証したリクエストは権限（role）を持たないため、管理者用のルートには使用できません。
+func RequireAuthOrAccessToken(requireAuth echo.MiddlewareFunc, tokens *service.AccessTokenService, scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withJWT := requireAuth(next)
+		return func(c echo.Context) error {
+			auth := c.Request().Header.Get("Authorization")
+			tokenStr, ok := strings.CutPrefix(auth, "Bearer ")
+			if !ok || !strings.HasPrefix(tokenStr, domain.AccessTokenPrefix) {
+				return withJWT(c)
+			}
+			pat, err := authenticateAccessToken(c, tokens, tokenStr)
+			if err != nil {
+				if errors.Is(err, service.ErrInvalidAccessToken) {
+					return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+				}
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+			}
+			if !pat.HasScope(scope) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "insufficient scope: " + scope})
+			}
+			c.Set(userIDKey, pat.UserID)
+			c.Set(userRoleKey, "")
+			c.Set(accessTokenIDKey, pat.ID)
+			return next(c)
+		}
+	}
+}
+
+// 同じリクエストでのトークンの認証結果を保存するコンテキストのキー（レート制限と認証で2回照合しない）
+const accessTokenResultKey = "access_token_result"
+
+type accessTokenResult struct {
+	pat *domain.PersonalAccessToken
+	err error
+}
+
+// トークンを照合する（同じリクエストで照合済みならその結果を返す）
+func authenticateAccessToken(c echo.Context, tokens *service.AccessTokenService, token string) (*domain.PersonalAccessToken, error) {
+	if r, ok := c.Get(accessTokenResultKey).(*accessTokenResult); ok {
+		return r.pat, r.err
+	}
+	pat, err := tokens.Authenticate(token, c.RealIP())
+	c.Set(accessTokenResultKey, &accessTokenResult{pat: pat, err: err})
+	return pat, err
+}
+
+type AccessTokenHandler struct {
+	tokens *service.AccessTokenService
+	audit  *service.AuditService
+}
+
+func NewAccessTokenHandler(tokens *service.AccessTokenService, audit *service.AuditService) *AccessTokenHandler {
+	return &AccessTokenHandler{tokens: tokens, audit: audit}
+}
+
+// POST /api/v1/me/tokens
+// トークンを発行（トークンはこのレスポンスでのみ返す）
+func (h *AccessTokenHandler) CreateAccessToken(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	var req dto.AccessTokenCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	if req.ExpiresInDays < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": service.ErrInvalidTokenTTL.Error()})
+	}
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	token, pat, err := h.tokens.Create(userID, req.Name, req.Scopes, ttl)
+	if err != nil {
+		return accessTokenError(c, err)
+	}
+	h.audit.Record(c.Request().Context(), service.AuditEvent{
+		Action:     domain.AuditAccessTokenCreate,
+		TargetType: domain.AuditTargetAccessToken,
+		TargetID:   strconv.FormatUint(uint64(pat.ID), 10),
+		Details: map[string]interface{}{
+			"name":         pat.Name,
+			"token_prefix": pat.TokenPrefix,
+			"scopes":       pat.ScopeList(),
+			"expires_at":   pat.ExpiresAt.UTC().Format(time.RFC3339),
+		},
+	})
+	res := convertDomainAccessTokenToDTO(pat, time.Now())
+	res.Token = token
+	return c.JSON(http.StatusCreated, res)
+}
+
+// GET /api/v1/me/tokens
+// 発行したトークンの一覧（失効・期限切れも含む、新しい順）
+func (h *AccessTokenHandler) GetAccessTokens(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	tokens, err := h.tokens.List(userID)
+	if err != nil {
+		return accessTokenError(c, err)
+	}
+	now := time.Now()
+	dtoList := make([]dto.AccessTokenDTO, 0, len(tokens))
+	for i := range tokens {
+		dtoList = append(dtoList, convertDomainAccessTokenToDTO(&tokens[i], now))
+	}
+	return c.JSON(http.StatusOK, dtoList)
+}
+
+// DELETE /api/v1/me/tokens/:id
+// トークンを失効させる
+func (h *AccessTokenHandler) RevokeAccessToken(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if err := h.tokens.Revoke(userID, uint(id)); err != nil {
+		return accessTokenError(c, err)
+	}
+	h.audit.Record(c.Request().Context(), service.AuditEvent{
+		Action:     domain.AuditAccessTokenRevoke,
+		TargetType: domain.AuditTargetAccessToken,
+		TargetID:   strconv.FormatUint(id, 10),
+	})
+	return c.NoContent(http.StatusNoContent)
+}
+
+func accessTokenError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "token not found"})
+	case errors.Is(err, service.ErrInvalidTokenName), errors.Is(err, service.ErrInvalidScope), errors.Is(err, service.ErrInvalidTokenTTL):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrTooManyAccessTokens):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+}
+
+func convertDomainAccessTokenToDTO(t *domain.PersonalAccessToken, now time.Time) dto.AccessTokenDTO {
+	res := dto.AccessTokenDTO{
+		ID:          t.ID,
+		Name:        t.Name,
+		TokenPrefix: t.TokenPrefix,
+		Scopes:      t.ScopeList(),
+		ExpiresAt:   t.ExpiresAt.Format(time.RFC3339),
+		LastUsedIP:  t.LastUsedIP,
+		Active:      t.IsActive(now),
+		CreatedAt:   t.CreatedAt.Format(time.RFC3339),
+	}
+	if t.LastUsedAt != nil {
+		s := t.LastUsedAt.Format(time.RFC3339)
+		res.LastUsedAt = &s
+	}
+	if t.RevokedAt != nil {
+		s := t.RevokedAt.Format(time.RFC3339)
+		res.RevokedAt = &s
+	}
+	return res
+}
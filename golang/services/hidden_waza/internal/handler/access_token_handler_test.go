@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+	"gorm.io/gorm"
+)
+
+// テスト用のトークンの保存先（発行と照合のみ）
+type fakeAccessTokenStore struct {
+	tokens []domain.PersonalAccessToken
+}
+
+func (f *fakeAccessTokenStore) Create(t *domain.PersonalAccessToken) error {
+	t.ID = uint(len(f.tokens) + 1)
+	f.tokens = append(f.tokens, *t)
+	return nil
+}
+
+func (f *fakeAccessTokenStore) FindByHash(tokenHash string) (*domain.PersonalAccessToken, error) {
+	for _, t := range f.tokens {
+		if t.TokenHash == tokenHash {
+			return &t, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeAccessTokenStore) ListByUser(uint) ([]domain.PersonalAccessToken, error) {
+	return nil, nil
+}
+
+func (f *fakeAccessTokenStore) CountActive(uint, time.Time) (int, error) { return 0, nil }
+
+func (f *fakeAccessTokenStore) Revoke(uint, uint, time.Time) error { return nil }
+
+func (f *fakeAccessTokenStore) TouchLastUsed(uint, string, time.Time, time.Duration) error {
+	return nil
+}
+
+type fakeTokenUsers struct{}
+
+func (fakeTokenUsers) GetByID(id uint) (*domain.User, error) { return &domain.User{ID: id}, nil }
+
+// 職務経歴書の参照・更新ルートとresume:read・resume:writeのトークン
+func newAccessTokenTestServer(t *testing.T) (e *echo.Echo, readToken, writeToken string) {
+	t.Helper()
+	tokens := service.NewAccessTokenService(&fakeAccessTokenStore{}, fakeTokenUsers{})
+	var err error
+	readToken, _, err = tokens.Create(7, "read", []string{domain.ScopeResumeRead}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeToken, _, err = tokens.Create(7, "write", []string{domain.ScopeResumeWrite}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// JWTの認証はこのテストでは常に401
+	requireAuth := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "jwt"})
+		}
+	}
+	ok := func(c echo.Context) error {
+		id, _ := currentUserID(c)
+		return c.String(http.StatusOK, strconv.FormatUint(uint64(id), 10))
+	}
+	e = echo.New()
+	e.GET("/api/v1/resume/:id", ok, RequireAuthOrAccessToken(requireAuth, tokens, domain.ScopeResumeRead))
+	e.PUT("/api/v1/resume/:id", ok, RequireAuthOrAccessToken(requireAuth, tokens, domain.ScopeResumeWrite))
+	return e, readToken, writeToken
+}
+
+func TestRequireAuthOrAccessToken(t *testing.T) {
+	e, readToken, writeToken := newAccessTokenTestServer(t)
+	tests := []struct {
+		name   string
+		method string
+		auth   string
+		want   int
+	}{
+		{"read token on read route", http.MethodGet, "Bearer " + readToken, http.StatusOK},
+		{"read token on write route", http.MethodPut, "Bearer " + readToken, http.StatusForbidden},
+		{"write token on write route", http.MethodPut, "Bearer " + writeToken, http.StatusOK},
+		// resume:writeはresume:readを含む
+		{"write token on read route", http.MethodGet, "Bearer " + writeToken, http.StatusOK},
+		{"unknown token", http.MethodGet, "Bearer " + domain.AccessTokenPrefix + "unknown", http.StatusUnauthorized},
+		// hwz_で始まらない値はJWTとして認証する
+		{"jwt", http.MethodPut, "Bearer eyJhbGciOiJIUzI1NiJ9.e30.sig", http.StatusUnauthorized},
+		{"no authorization", http.MethodPut, "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/resume/1", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusOK && rec.Body.String() != "7" {
+				t.Errorf("user id = %s, want 7", rec.Body.String())
+			}
+			if tt.name == "jwt" && rec.Body.String() != "{\"error\":\"jwt\"}\n" {
+				t.Errorf("not handled by requireAuth: %s", rec.Body.String())
+			}
+		})
+	}
+}
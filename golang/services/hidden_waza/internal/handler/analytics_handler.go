@@ -0,0 +1,146 @@
+// analytics_handler.go: スキル集計（ランキング・共起・分布・推移）APIハンドラ
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+type AnalyticsHandler struct {
+	svc *service.AnalyticsService
+}
+
+func NewAnalyticsHandler(svc *service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{svc: svc}
+}
+
+// GET /api/v1/analytics/skills/top?type=tool&verified=true&limit=10&lang=ja
+func (h *AnalyticsHandler) GetTopSkills(c echo.Context) error {
+	f, ok := analyticsFilterParam(c.Request())
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid filter"})
+	}
+	limit, ok := limitParam(c.Request(), service.DefaultAnalyticsLimit)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+	loc := requestLocale(c.Request())
+	skills, err := h.svc.TopSkills(f, limit, loc)
+	if err != nil {
+		return analyticsError(c, err)
+	}
+	setContentLanguage(c.Response().Header(), loc)
+	return c.JSON(http.StatusOK, skills)
+}
+
+// GET /api/v1/analytics/skills/co-occurrence?type=&verified=&limit=10
+// 上位スキル同士の共起行列
+func (h *AnalyticsHandler) GetCoOccurrenceMatrix(c echo.Context) error {
+	f, ok := analyticsFilterParam(c.Request())
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid filter"})
+	}
+	limit, ok := limitParam(c.Request(), service.DefaultAnalyticsLimit)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+	loc := requestLocale(c.Request())
+	m, err := h.svc.CoOccurrenceMatrix(f, limit, loc)
+	if err != nil {
+		return analyticsError(c, err)
+	}
+	setContentLanguage(c.Response().Header(), loc)
+	return c.JSON(http.StatusOK, m)
+}
+
+// GET /api/v1/analytics/skills/:type/:id/co-occurring?type=tool&verified=&limit=10
+// 指定スキルと一緒に登録されているスキル（?typeは相手の種別）
+func (h *AnalyticsHandler) GetCoOccurring(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	f, ok := analyticsFilterParam(c.Request())
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid filter"})
+	}
+	limit, ok := limitParam(c.Request(), service.DefaultAnalyticsLimit)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+	loc := requestLocale(c.Request())
+	result, err := h.svc.CoOccurring(c.Param("type"), uint(id), f, limit, loc)
+	if err != nil {
+		return analyticsError(c, err)
+	}
+	setContentLanguage(c.Response().Header(), loc)
+	return c.JSON(http.StatusOK, result)
+}
+
+// GET /api/v1/analytics/skills/histogram?type=language&master_id=1&verified=
+// レベル・経験年数の分布
+func (h *AnalyticsHandler) GetHistogram(c echo.Context) error {
+	f, ok := analyticsFilterParam(c.Request())
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid filter"})
+	}
+	hist, err := h.svc.Histogram(f)
+	if err != nil {
+		return analyticsError(c, err)
+	}
+	return c.JSON(http.StatusOK, hist)
+}
+
+// GET /api/v1/analytics/resumes/trend?interval=month&type=&master_id=&verified=
+// 作成日時の月/年ごとの職務経歴書数（スキル指定時は保有数・保有率も）
+func (h *AnalyticsHandler) GetTrend(c echo.Context) error {
+	f, ok := analyticsFilterParam(c.Request())
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid filter"})
+	}
+	interval := c.QueryParam("interval")
+	if interval == "" {
+		interval = "month"
+	}
+	points, err := h.svc.Trend(f, interval)
+	if err != nil {
+		return analyticsError(c, err)
+	}
+	return c.JSON(http.StatusOK, points)
+}
+
+func analyticsError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidAnalyticsFilter):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+}
+
+// クエリ?verified=true|false, ?type=, ?master_id= を絞り込み条件に変換
+func analyticsFilterParam(r *http.Request) (domain.AnalyticsFilter, bool) {
+	q := r.URL.Query()
+	f := domain.AnalyticsFilter{SkillType: q.Get("type")}
+	if v := q.Get("verified"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, false
+		}
+		f.Verified = &b
+	}
+	if v := q.Get("master_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return f, false
+		}
+		f.MasterID = uint(id)
+	}
+	return f, f.IsValid()
+}
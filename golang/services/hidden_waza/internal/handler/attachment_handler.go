@@ -0,0 +1,158 @@
+// attachment_handler.go: 職務経歴書・職歴の添付ファイルAPIハンドラ
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/storage"
+)
+
+// multipartの境界・他フィールド分の余裕
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	svc          *service.AttachmentService
+	resumes      *repository.ResumeRepository
+	verification *service.EmailVerificationService
+}
+
+func NewAttachmentHandler(svc *service.AttachmentService, resumes *repository.ResumeRepository, verification *service.EmailVerificationService) *AttachmentHandler {
+	return &AttachmentHandler{svc: svc, resumes: resumes, verification: verification}
+}
+
+// POST /api/v1/resume/:id/attachments（multipart/form-data: file, experience_id（任意））
+// 認証済みユーザー自身の職務経歴書のみ（メールアドレスの確認が必要）
+func (h *AttachmentHandler) UploadAttachment(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if status, msg := authorizeResumeWrite(c, h.resumes, h.verification, uint(id)); status != 0 {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	// 上限を大きく超えるリクエストは本文を読む前に拒否
+	if c.Request().ContentLength > h.svc.MaxSize()+multipartOverhead {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": service.ErrAttachmentTooLarge.Error()})
+	}
+	var experienceID *uint
+	if v := c.FormValue("experience_id"); v != "" {
+		eid, err := strconv.Atoi(v)
+		if err != nil || eid <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid experience_id"})
+		}
+		u := uint(eid)
+		experienceID = &u
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required"})
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file"})
+	}
+	defer f.Close()
+
+	a, err := h.svc.Upload(c.Request().Context(), uint(id), experienceID, fh.Filename, fh.Size, f)
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	return c.JSON(http.StatusCreated, a)
+}
+
+// GET /api/v1/resume/:id/attachments
+func (h *AttachmentHandler) GetAttachments(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	attachments, err := h.svc.List(uint(id))
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	return c.JSON(http.StatusOK, attachments)
+}
+
+// GET /api/v1/attachments/:id/url
+// 期限付きのダウンロードURL
+func (h *AttachmentHandler) GetAttachmentURL(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	url, expiresAt, err := h.svc.SignedURL(uint(id))
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{
+		"url":        url,
+		"expires_at": expiresAt.Format(time.RFC3339),
+	})
+}
+
+// GET /api/v1/attachments/:id/download?expires=...&signature=...
+// 署名付きURLでのダウンロード（ローカル保存時）
+func (h *AttachmentHandler) DownloadAttachment(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	a, rc, err := h.svc.Open(c.Request().Context(), uint(id), c.QueryParam("expires"), c.QueryParam("signature"))
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	defer rc.Close()
+
+	header := c.Response().Header()
+	etag := `"` + a.Checksum + `"`
+	header.Set("ETag", etag)
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Content-Disposition", storage.ContentDisposition(a.FileName))
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+	header.Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	return c.Stream(http.StatusOK, a.ContentType, rc)
+}
+
+// DELETE /api/v1/attachments/:id
+// 認証済みユーザー自身の職務経歴書の添付ファイルのみ（メールアドレスの確認が必要）
+func (h *AttachmentHandler) DeleteAttachment(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	a, err := h.svc.Get(uint(id))
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	if status, msg := authorizeResumeWrite(c, h.resumes, h.verification, a.ResumeID); status != 0 {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	if err := h.svc.Delete(c.Request().Context(), a.ID); err != nil {
+		return attachmentError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func attachmentError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrAttachmentTypeNotAllowed):
+		return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrAttachmentEmpty), errors.Is(err, service.ErrExperienceNotInResume):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidDownloadSignature):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "attachment error"})
+}
@@ -0,0 +1,143 @@
+// audit_handler.go: 監査ログの管理APIハンドラ（/api/v1/admin、管理者のみ）と行為者を設定するミドルウェア
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+// 監査ログの一覧の既定件数・上限
+const (
+	defaultAuditLogListLimit = 50
+	maxAuditLogListLimit     = 500
+)
+
+// AuditContextは監査ログの行為者（認証済みユーザー・トークン、IPアドレス、User-Agent、リクエストID）を
+// リクエストのcontextに設定するミドルウェアです（middleware.RequestIDの後に使用）。
+// 認証はルートのミドルウェアで行われるため、行為者は記録する時点で取得します。
+func AuditContext() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := service.WithAuditActor(c.Request().Context(), func() service.AuditActor {
+				return auditActor(c)
+			})
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+func auditActor(c echo.Context) service.AuditActor {
+	actor := service.AuditActor{
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+	}
+	if id, ok := currentUserID(c); ok {
+		actor.UserID = &id
+	}
+	if id, ok := c.Get(accessTokenIDKey).(uint); ok {
+		actor.TokenID = &id
+	}
+	return actor
+}
+
+type AuditHandler struct {
+	audit *service.AuditService
+}
+
+func NewAuditHandler(audit *service.AuditService) *AuditHandler {
+	return &AuditHandler{audit: audit}
+}
+
+// GET /api/v1/admin/audit-logs?action=&outcome=&actor_user_id=&target_type=&target_id=&request_id=&from=&to=&before_id=&limit=50
+// 監査ログ（新しい順。from/toはRFC3339、次のページはnext_before_idをbefore_idに指定）
+func (h *AuditHandler) GetAuditLogs(c echo.Context) error {
+	limit, ok := limitParam(c.Request(), defaultAuditLogListLimit)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+	limit = min(limit, maxAuditLogListLimit)
+	f := repository.AuditLogFilter{
+		Action:     c.QueryParam("action"),
+		Outcome:    c.QueryParam("outcome"),
+		TargetType: c.QueryParam("target_type"),
+		TargetID:   c.QueryParam("target_id"),
+		RequestID:  c.QueryParam("request_id"),
+	}
+	for name, dst := range map[string]*uint{"actor_user_id": &f.ActorUserID, "before_id": &f.BeforeID} {
+		if v := c.QueryParam(name); v != "" {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil || n == 0 {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid " + name})
+			}
+			*dst = uint(n)
+		}
+	}
+	for name, dst := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		if v := c.QueryParam(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid " + name})
+			}
+			*dst = t
+		}
+	}
+	logs, err := h.audit.List(f, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	res := dto.AuditLogListResponse{Entries: make([]dto.AuditLogDTO, 0, len(logs))}
+	for i := range logs {
+		res.Entries = append(res.Entries, convertDomainAuditLogToDTO(&logs[i]))
+	}
+	if len(logs) == limit {
+		next := logs[len(logs)-1].ID
+		res.NextBeforeID = &next
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// GET /api/v1/admin/audit-logs/verify
+// ハッシュチェーンを検証（改ざん・削除があればvalid: falseと最初に不整合が見つかった連番）
+func (h *AuditHandler) VerifyAuditLogs(c echo.Context) error {
+	result, err := h.audit.Verify()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func convertDomainAuditLogToDTO(l *domain.AuditLog) dto.AuditLogDTO {
+	details := json.RawMessage(l.Details)
+	if !json.Valid(details) {
+		details = json.RawMessage("{}")
+	}
+	return dto.AuditLogDTO{
+		ID:           l.ID,
+		Seq:          l.Seq,
+		Action:       l.Action,
+		Outcome:      l.Outcome,
+		ActorUserID:  l.ActorUserID,
+		ActorTokenID: l.ActorTokenID,
+		TargetType:   l.TargetType,
+		TargetID:     l.TargetID,
+		IP:           l.IP,
+		UserAgent:    l.UserAgent,
+		RequestID:    l.RequestID,
+		BeforeHash:   l.BeforeHash,
+		AfterHash:    l.AfterHash,
+		Details:      details,
+		PrevHash:     l.PrevHash,
+		Hash:         l.Hash,
+		CreatedAt:    l.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
@@ -0,0 +1,130 @@
+// auth.go: JWT認証（ログインで発行したトークンの検証）
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// JWTの署名鍵（起動時にSetJWTSecretで設定。未設定の間はJWTを発行・検証しない）
+var jwtSecret []byte
+
+// JWTの署名鍵の最小の長さ
+const minJWTSecretLen = 32
+
+// SetJWTSecretはログインで発行するJWTの署名鍵を設定します（ルートの登録前に呼び出す）。
+func SetJWTSecret(key []byte) error {
+	if len(key) < minJWTSecretLen {
+		return fmt.Errorf("jwt signing key must be at least %d bytes", minJWTSecretLen)
+	}
+	jwtSecret = key
+	return nil
+}
+
+// 認証済みユーザーのID・権限を保存するコンテキストのキー
+const (
+	userIDKey   = "user_id"
+	userRoleKey = "user_role"
+	// パーソナルアクセストークンで認証した場合のトークンのID
+	accessTokenIDKey = "access_token_id"
+)
+
+// AuthUsersはJWTを検証するときのユーザーの現在の状態を返します。
+type AuthUsers interface {
+	// AuthStateはユーザーのJWTの世代（パスワードの再設定・権限の変更で増え、それ以前に発行したJWTは無効）と権限を返します。
+	AuthState(userID uint) (int, string, error)
+}
+
+// RequireAuthはAuthorization: Bearer <JWT> を検証し、ユーザーID・権限をコンテキストに設定するミドルウェアです。
+// JWTの世代（tv）がユーザーの現在の世代と異なる場合（パスワードの再設定前に発行された場合）は無効とします。
+// 権限はJWTのroleではなくDBの現在の値を使います。
+func RequireAuth(users AuthUsers) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth := c.Request().Header.Get("Authorization")
+			tokenStr, ok := strings.CutPrefix(auth, "Bearer ")
+			if !ok || tokenStr == "" {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+			}
+			claims, id, ok := parseJWT(tokenStr)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+			}
+			// tvのないJWTは世代0として扱う
+			tv, _ := claims["tv"].(float64)
+			current, role, err := users.AuthState(uint(id))
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+				}
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+			}
+			if int(tv) != current {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "token revoked"})
+			}
+			c.Set(userIDKey, uint(id))
+			c.Set(userRoleKey, role)
+			return next(c)
+		}
+	}
+}
+
+// JWTの署名・有効期限を検証し、クレームとユーザーIDを返す（世代の確認は呼び出し側で行う）
+func parseJWT(tokenStr string) (jwt.MapClaims, float64, bool) {
+	if len(jwtSecret) == 0 {
+		return nil, 0, false
+	}
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, 0, false
+	}
+	// JSONの数値はfloat64としてデコードされる
+	id, ok := claims["user_id"].(float64)
+	if !ok || id <= 0 {
+		return nil, 0, false
+	}
+	return claims, id, true
+}
+
+// OptionalAuthはAuthorizationヘッダーがある場合のみrequireAuthで認証するミドルウェアです（ない場合は未認証のまま通す）。
+func OptionalAuth(requireAuth echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withAuth := requireAuth(next)
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" {
+				return next(c)
+			}
+			return withAuth(c)
+		}
+	}
+}
+
+// RequireRoleは認証済みユーザーが指定の権限のいずれかを持つ場合のみ通すミドルウェアです（RequireAuthの後に使用）。
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r, _ := c.Get(userRoleKey).(string)
+			for _, role := range roles {
+				if r == role {
+					return next(c)
+				}
+			}
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+		}
+	}
+}
+
+// 認証済みユーザーID（RequireAuthを通過したリクエストのみ）
+func currentUserID(c echo.Context) (uint, bool) {
+	id, ok := c.Get(userIDKey).(uint)
+	return id, ok
+}
@@ -0,0 +1,69 @@
+// career_handler.go: 職歴の集計（総経験年数・空白期間・兼務・在籍期間）・スキル年数整合性APIハンドラ
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+type CareerHandler struct {
+	svc *service.CareerService
+}
+
+func NewCareerHandler(svc *service.CareerService) *CareerHandler {
+	return &CareerHandler{svc: svc}
+}
+
+// GET /api/v1/resume/:id/metrics?gap_months=3
+func (h *CareerHandler) GetMetrics(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	gapMonths, ok := gapMonthsParam(c.Request())
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid gap_months"})
+	}
+	metrics, err := h.svc.Metrics(uint(id), gapMonths)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusOK, metrics)
+}
+
+// GET /api/v1/resume/:id/consistency
+// 申告スキル年数が職歴から裏付けられる年数を超えているスキルを警告として返す
+func (h *CareerHandler) GetConsistency(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	result, err := h.svc.Consistency(uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// クエリ?gap_months=（空白期間の閾値・月）。未指定なら既定値
+func gapMonthsParam(r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("gap_months")
+	if v == "" {
+		return service.DefaultGapThresholdMonths, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
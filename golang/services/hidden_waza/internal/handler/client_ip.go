@@ -0,0 +1,51 @@
+// client_ip.go: クライアントのIPアドレス（c.RealIP()）の取得方法
+package handler
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewIPExtractorはe.IPExtractorに設定する、クライアントのIPアドレスの取得方法を返します。
+// IPアドレスはレート制限・ログイン試行の制限・監査ログ・新しいIPアドレスからのログインの通知に使うため、
+// クライアントが自由に指定できるX-Forwarded-For・X-Real-IPはそのままでは信用しません。
+//   - trustedProxiesが空なら接続元のアドレス（ヘッダーは無視する）
+//   - 指定した場合は、接続元がそのいずれか（CIDRまたはIPアドレス）のときのみX-Real-IPを使う
+//     （nginxのproxy_set_header X-Real-IP $remote_addr。クライアントが送ったX-Real-IPはnginxが上書きする）
+func NewIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+	// 既定で信用するループバック・リンクローカル・プライベートアドレスも、指定したものだけにする
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, p := range trustedProxies {
+		ipNet, err := parseIPRange(strings.TrimSpace(p))
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromRealIPHeader(options...), nil
+}
+
+// CIDR（10.0.0.0/8）またはIPアドレス（そのアドレスのみ）
+func parseIPRange(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", s)
+		}
+		return ipNet, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid trusted proxy %q", s)
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+func TestNewIPExtractor(t *testing.T) {
+	tests := []struct {
+		name       string
+		trusted    []string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"direct", nil, "203.0.113.5:1234", nil, "203.0.113.5"},
+		{"forged headers ignored without trusted proxies", nil, "203.0.113.5:1234",
+			map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.2"}, "203.0.113.5"},
+		// プライベートアドレスからの接続でも、指定していなければ信用しない
+		{"private peer not trusted by default", nil, "10.0.0.2:1234",
+			map[string]string{"X-Real-IP": "198.51.100.2"}, "10.0.0.2"},
+		{"trusted proxy", []string{"172.16.0.0/12"}, "172.18.0.3:1234",
+			map[string]string{"X-Real-IP": "198.51.100.2"}, "198.51.100.2"},
+		{"trusted proxy by address", []string{"172.18.0.3"}, "172.18.0.3:1234",
+			map[string]string{"X-Real-IP": "198.51.100.2"}, "198.51.100.2"},
+		// X-Forwarded-Forはnginxが設定しないため、信用するプロキシ経由でも使わない
+		{"forwarded-for ignored behind proxy", []string{"172.16.0.0/12"}, "172.18.0.3:1234",
+			map[string]string{"X-Forwarded-For": "198.51.100.1"}, "172.18.0.3"},
+		{"untrusted peer", []string{"172.16.0.0/12"}, "203.0.113.5:1234",
+			map[string]string{"X-Real-IP": "198.51.100.2"}, "203.0.113.5"},
+		{"loopback not trusted unless listed", []string{"172.16.0.0/12"}, "127.0.0.1:1234",
+			map[string]string{"X-Real-IP": "198.51.100.2"}, "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extract, err := NewIPExtractor(tt.trusted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			if got := extract(req); got != tt.want {
+				t.Errorf("IP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []string{"not-an-ip", "10.0.0.0/33"} {
+		if _, err := NewIPExtractor([]string{bad}); err == nil {
+			t.Errorf("NewIPExtractor(%q) succeeded", bad)
+		}
+	}
+}
+
+// X-Forwarded-For・X-Real-IPを毎回変えても、同じ接続元は同じバケットで制限される
+func TestRateLimitForgedForwardedFor(t *testing.T) {
+	e := echo.New()
+	extract, err := NewIPExtractor(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.IPExtractor = extract
+	limiter := service.NewRateLimiter(service.NewMemoryRateLimitStore(), service.RateLimitOptions{
+		Enabled: true,
+		Default: service.RateLimitPolicy{Name: "default", PerSecond: 1.0 / 3600, Burst: 2},
+	})
+	e.Use(RateLimit(limiter, nil))
+	e.GET("/api/v1/ping", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+
+	get := func(remoteAddr, forged string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forged)
+		req.Header.Set("X-Real-IP", forged)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	for i, forged := range []string{"198.51.100.1", "198.51.100.2"} {
+		if code := get("203.0.113.5:1000", forged); code != http.StatusNoContent {
+			t.Fatalf("request %d: status %d", i, code)
+		}
+	}
+	if code := get("203.0.113.5:1001", "198.51.100.3"); code != http.StatusTooManyRequests {
+		t.Errorf("request with a new forged header: status %d, want 429", code)
+	}
+	// 別の接続元は別のバケット
+	if code := get("203.0.113.6:1000", "198.51.100.3"); code != http.StatusNoContent {
+		t.Errorf("another client: status %d", code)
+	}
+}
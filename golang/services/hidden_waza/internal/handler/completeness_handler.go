@@ -0,0 +1,58 @@
+// completeness_handler.go: 職務経歴書の充実度スコア・改善提案APIハンドラ
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+type CompletenessHandler struct {
+	svc *service.CompletenessService
+}
+
+func NewCompletenessHandler(svc *service.CompletenessService) *CompletenessHandler {
+	return &CompletenessHandler{svc: svc}
+}
+
+// GET /api/v1/resume/:id/completeness?lang=ja
+// 最新リビジョンのスコアと改善提案
+func (h *CompletenessHandler) GetCompleteness(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	loc := requestLocale(c.Request())
+	score, err := h.svc.Latest(uint(id), loc)
+	if err != nil {
+		return completenessError(c, err)
+	}
+	setContentLanguage(c.Response().Header(), loc)
+	return c.JSON(http.StatusOK, score)
+}
+
+// GET /api/v1/resume/:id/completeness/history?lang=ja
+// リビジョンごとのスコア（新しい順）
+func (h *CompletenessHandler) GetCompletenessHistory(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	loc := requestLocale(c.Request())
+	scores, err := h.svc.History(uint(id), loc)
+	if err != nil {
+		return completenessError(c, err)
+	}
+	setContentLanguage(c.Response().Header(), loc)
+	return c.JSON(http.StatusOK, scores)
+}
+
+func completenessError(c echo.Context, err error) error {
+	if errors.Is(err, service.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+}
@@ -0,0 +1,114 @@
+// event_handler.go: 職務経歴書のイベントのSSE（Server-Sent Events）ハンドラ
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+const (
+	// 接続維持のためのコメント行の送信間隔（プロキシのアイドルタイムアウト対策）
+	sseHeartbeatInterval = 25 * time.Second
+	// 切断時にクライアントが再接続するまでの待ち時間（ミリ秒）
+	sseRetryMillis = 3000
+)
+
+type EventHandler struct {
+	events *service.EventStreamService
+}
+
+func NewEventHandler(events *service.EventStreamService) *EventHandler {
+	return &EventHandler{events: events}
+}
+
+// GET /api/v1/events（要認証）
+// 自分の職務経歴書の作成・更新・削除・verifiedの変更をSSEで配信
+// 再接続時はLast-Event-IDヘッダー（またはクエリ?last_event_id=）より後のイベントを先に再送
+func (h *EventHandler) StreamEvents(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	lastID := c.Request().Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.QueryParam("last_event_id")
+	}
+	var afterID uint64
+	if lastID != "" {
+		var err error
+		if afterID, err = strconv.ParseUint(lastID, 10, 64); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid Last-Event-ID"})
+		}
+	}
+
+	// 再送中のイベントを取りこぼさないよう、再送より先に購読する
+	sub := h.events.Subscribe(userID)
+	defer h.events.Unsubscribe(sub)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	// nginxのバッファリングを無効化
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", sseRetryMillis); err != nil {
+		return nil
+	}
+	res.Flush()
+
+	sent := map[uint]bool{}
+	if lastID != "" {
+		err := h.events.Replay(userID, uint(afterID), func(ev *domain.OutboxEvent) error {
+			sent[ev.ID] = true
+			return writeSSEEvent(res, ev)
+		})
+		if err != nil {
+			return nil
+		}
+		res.Flush()
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-sub.C:
+			if !ok {
+				// 受信が追いつかず切断された（クライアントはLast-Event-IDで再接続）
+				return nil
+			}
+			if sent[ev.ID] {
+				continue
+			}
+			if err := writeSSEEvent(res, &ev); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// イベント1件をSSEの形式（id / event / data）で書き込む
+func writeSSEEvent(w *echo.Response, ev *domain.OutboxEvent) error {
+	data, err := json.Marshal(service.NewEventEnvelope(ev))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+	return err
+}
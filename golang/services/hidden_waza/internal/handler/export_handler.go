@@ -0,0 +1,50 @@
+// export_handler.go: 職務経歴書ファイル出力APIハンドラ
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/export"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+type ExportHandler struct {
+	svc *service.ExportService
+}
+
+func NewExportHandler(svc *service.ExportService) *ExportHandler {
+	return &ExportHandler{svc: svc}
+}
+
+// GET /api/v1/resume/:id/export/docx?lang=ja|en&layout=chronological|skill
+// 言語は?lang= → Accept-Language の順で判定
+func (h *ExportHandler) ExportDocx(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	layout := export.Layout(c.QueryParam("layout"))
+	if layout != "" && layout != export.LayoutChronological && layout != export.LayoutSkill {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid layout"})
+	}
+	opt := export.Options{
+		Lang:   string(requestLocale(c.Request())),
+		Layout: layout,
+	}
+
+	// 途中でエラーになった場合に壊れたファイルを返さないよう、一旦バッファに書き出す
+	var buf bytes.Buffer
+	if err := h.svc.ExportDocx(&buf, uint(id), opt); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "export failed"})
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="resume_%d.docx"`, id))
+	return c.Blob(http.StatusOK, h.svc.DocxContentType(), buf.Bytes())
+}
@@ -0,0 +1,74 @@
+// gap_analysis_handler.go: 目標ロールに対するスキルギャップ分析APIハンドラ
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+type GapAnalysisHandler struct {
+	svc *service.GapAnalysisService
+}
+
+func NewGapAnalysisHandler(svc *service.GapAnalysisService) *GapAnalysisHandler {
+	return &GapAnalysisHandler{svc: svc}
+}
+
+// POST /api/v1/resume/:id/gap-analysis?lang=ja
+// 目標スキルに対する不足（missing）・未達（below）・充足（met）・余剰（surplus）をスキル種別ごとに返す
+func (h *GapAnalysisHandler) AnalyzeGap(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req dto.GapAnalysisRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	targets, err := convertSkillTargetDTOs(req.Targets)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	loc := requestLocale(c.Request())
+	gap, err := h.svc.Analyze(uint(id), targets, loc)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+		case errors.Is(err, service.ErrInvalidSkillTarget), errors.Is(err, service.ErrUnknownMaster):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	setContentLanguage(c.Response().Header(), loc)
+	return c.JSON(http.StatusOK, gap)
+}
+
+// SkillTargetDTO → domain.SkillTarget変換（レベルの別名変換込み）
+func convertSkillTargetDTOs(dtos []dto.SkillTargetDTO) ([]domain.SkillTarget, error) {
+	targets := make([]domain.SkillTarget, 0, len(dtos))
+	for i, t := range dtos {
+		var level domain.SkillLevel
+		if t.MinLevel != "" {
+			l, err := domain.ParseSkillLevel(t.MinLevel)
+			if err != nil {
+				return nil, fmt.Errorf("targets[%d].min_level: %w", i, err)
+			}
+			level = l
+		}
+		targets = append(targets, domain.SkillTarget{
+			Type:     t.Type,
+			MasterID: t.MasterID,
+			MinYears: t.MinYears,
+			MinLevel: level,
+		})
+	}
+	return targets, nil
+}
@@ -0,0 +1,121 @@
+// job_handler.go: バックグラウンドジョブの管理APIハンドラ（/api/v1/admin、管理者のみ）
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+// ジョブ一覧の既定件数
+const defaultJobListLimit = 50
+
+type JobHandler struct {
+	queue *service.JobQueue
+}
+
+func NewJobHandler(queue *service.JobQueue) *JobHandler {
+	return &JobHandler{queue: queue}
+}
+
+// GET /api/v1/admin/jobs?status=dead&type=link_check.run&limit=50&offset=0
+func (h *JobHandler) GetJobs(c echo.Context) error {
+	limit, ok := limitParam(c.Request(), defaultJobListLimit)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+	offset := 0
+	if v := c.QueryParam("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid offset"})
+		}
+		offset = n
+	}
+	jobs, err := h.queue.List(repository.JobFilter{
+		Status: c.QueryParam("status"),
+		Type:   c.QueryParam("type"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, jobs)
+}
+
+// GET /api/v1/admin/jobs/stats
+// 状態ごとのジョブ件数
+func (h *JobHandler) GetJobStats(c echo.Context) error {
+	stats, err := h.queue.Stats()
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+// GET /api/v1/admin/jobs/:id
+func (h *JobHandler) GetJob(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	job, err := h.queue.Get(uint(id))
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// POST /api/v1/admin/jobs/:id/retry
+// デッドレター・取り消し済みのジョブを再実行
+func (h *JobHandler) RetryJob(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	job, err := h.queue.Retry(uint(id))
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// POST /api/v1/admin/jobs/:id/cancel
+// 実行待ちのジョブを取り消し
+func (h *JobHandler) CancelJob(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	job, err := h.queue.Cancel(uint(id))
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// GET /api/v1/admin/job-schedules
+func (h *JobHandler) GetJobSchedules(c echo.Context) error {
+	schedules, err := h.queue.Schedules()
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, schedules)
+}
+
+func jobError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	case errors.Is(err, service.ErrInvalidJob):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid status"})
+	case errors.Is(err, service.ErrJobStateConflict):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+}
@@ -0,0 +1,270 @@
+// job_posting_handler.go: 求人CRUD・マッチングAPIハンドラ
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+	"gorm.io/gorm"
+)
+
+type JobPostingHandler struct {
+	repo     *repository.JobPostingRepository
+	matching *service.MatchingService
+}
+
+func NewJobPostingHandler(repo *repository.JobPostingRepository, matching *service.MatchingService) *JobPostingHandler {
+	return &JobPostingHandler{repo: repo, matching: matching}
+}
+
+// POST /api/v1/jobs
+// 求人を掲載（要認証。掲載者は認証済みユーザー。poster_idを省略した場合は認証済みユーザー）
+func (h *JobPostingHandler) CreateJob(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	var req dto.JobPostingDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	if req.PosterID == 0 {
+		req.PosterID = userID
+	}
+	if req.PosterID != userID {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+	job, err := convertJobPostingDTO(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := h.repo.Create(job); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusCreated, convertDomainJobPostingToDTO(job))
+}
+
+// GET /api/v1/jobs?status=published
+func (h *JobPostingHandler) GetJobs(c echo.Context) error {
+	jobs, err := h.repo.FindByStatus(c.QueryParam("status"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	dtoList := make([]dto.JobPostingDTO, 0, len(jobs))
+	for i := range jobs {
+		dtoList = append(dtoList, convertDomainJobPostingToDTO(&jobs[i]))
+	}
+	return c.JSON(http.StatusOK, dtoList)
+}
+
+// GET /api/v1/jobs/:id
+func (h *JobPostingHandler) GetJobByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	job, err := h.repo.GetByID(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusOK, convertDomainJobPostingToDTO(job))
+}
+
+// PUT /api/v1/jobs/:id （要件は全置換）
+// 掲載者または管理者のみ更新できる（掲載者は変更できない）
+func (h *JobPostingHandler) UpdateJob(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	existing, status, msg := h.ownJob(c, uint(id))
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	var req dto.JobPostingDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	if req.PosterID == 0 {
+		req.PosterID = existing.PosterID
+	}
+	if req.PosterID != existing.PosterID {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "poster_id cannot be changed"})
+	}
+	job, err := convertJobPostingDTO(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	job.ID = existing.ID
+	job.CreatedAt = existing.CreatedAt
+	if err := h.repo.Update(job); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusOK, convertDomainJobPostingToDTO(job))
+}
+
+// DELETE /api/v1/jobs/:id
+// 掲載者または管理者のみ削除できる（存在しない場合は何もしない）
+func (h *JobPostingHandler) DeleteJob(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	_, status, msg := h.ownJob(c, uint(id))
+	if status == http.StatusNotFound {
+		return c.NoContent(http.StatusNoContent)
+	}
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	if err := h.repo.Delete(uint(id)); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// 認証済みユーザーが掲載者（または管理者）の求人を取得する（存在しない場合・変更できない場合はstatusが0以外）
+func (h *JobPostingHandler) ownJob(c echo.Context, id uint) (*domain.JobPosting, int, string) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, http.StatusUnauthorized, "missing token"
+	}
+	job, err := h.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, "not found"
+		}
+		return nil, http.StatusInternalServerError, "DB error"
+	}
+	if role, _ := c.Get(userRoleKey).(string); job.PosterID != userID && role != domain.RoleAdmin {
+		return nil, http.StatusForbidden, "forbidden"
+	}
+	return job, 0, ""
+}
+
+// GET /api/v1/jobs/:id/matches?limit=20
+// 求人に対する職務経歴書のマッチング順位
+func (h *JobPostingHandler) GetResumeMatches(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	limit, ok := limitParam(c.Request(), service.DefaultMatchLimit)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+	matches, err := h.matching.MatchResumes(uint(id), limit)
+	if err != nil {
+		return matchingError(c, err)
+	}
+	return c.JSON(http.StatusOK, matches)
+}
+
+// GET /api/v1/resume/:id/job-matches?limit=20
+// 職務経歴書に対する公開中求人のマッチング順位
+func (h *JobPostingHandler) GetJobMatches(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	limit, ok := limitParam(c.Request(), service.DefaultMatchLimit)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+	matches, err := h.matching.MatchJobs(uint(id), limit)
+	if err != nil {
+		return matchingError(c, err)
+	}
+	return c.JSON(http.StatusOK, matches)
+}
+
+func matchingError(c echo.Context, err error) error {
+	if errors.Is(err, service.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+}
+
+// クエリ?limit=（1以上）。未指定なら既定値
+func limitParam(r *http.Request, def int) (int, bool) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n, true
+}
+
+// JobPostingDTO → domain.JobPosting変換（レベルの別名変換・業務バリデーション込み）
+func convertJobPostingDTO(req dto.JobPostingDTO) (*domain.JobPosting, error) {
+	job := &domain.JobPosting{
+		PosterID:    req.PosterID,
+		Title:       req.Title,
+		Company:     req.Company,
+		Description: req.Description,
+		Status:      req.Status,
+	}
+	if job.Status == "" {
+		job.Status = domain.JobStatusDraft
+	}
+	for i, r := range req.Requirements {
+		var level domain.SkillLevel
+		if r.MinLevel != "" {
+			l, err := domain.ParseSkillLevel(r.MinLevel)
+			if err != nil {
+				return nil, fmt.Errorf("requirements[%d].min_level: %w", i, err)
+			}
+			level = l
+		}
+		job.Requirements = append(job.Requirements, domain.JobRequirement{
+			Type:     r.Type,
+			MasterID: r.MasterID,
+			MinYears: r.MinYears,
+			MinLevel: level,
+			Required: r.Required,
+		})
+	}
+	if !job.IsValid() {
+		return nil, errors.New("invalid job posting")
+	}
+	return job, nil
+}
+
+// domain.JobPosting → JobPostingDTO変換
+func convertDomainJobPostingToDTO(job *domain.JobPosting) dto.JobPostingDTO {
+	reqs := make([]dto.JobRequirementDTO, 0, len(job.Requirements))
+	for _, r := range job.Requirements {
+		reqs = append(reqs, dto.JobRequirementDTO{
+			Type:     r.Type,
+			MasterID: r.MasterID,
+			MinYears: r.MinYears,
+			MinLevel: string(r.MinLevel),
+			Required: r.Required,
+		})
+	}
+	return dto.JobPostingDTO{
+		ID:           job.ID,
+		PosterID:     job.PosterID,
+		Title:        job.Title,
+		Company:      job.Company,
+		Description:  job.Description,
+		Status:       job.Status,
+		Requirements: reqs,
+		CreatedAt:    job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    job.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -10,11 +10,12 @@ import (
 )
 
 type LanguageHandler struct {
-	repo *repository.LanguageRepository
+	repo   *repository.LanguageRepository
+	trRepo *repository.TranslationRepository
 }
 
-func NewLanguageHandler(repo *repository.LanguageRepository) *LanguageHandler {
-	return &LanguageHandler{repo: repo}
+func NewLanguageHandler(repo *repository.LanguageRepository, trRepo *repository.TranslationRepository) *LanguageHandler {
+	return &LanguageHandler{repo: repo, trRepo: trRepo}
 }
 
 // GET /api/v1/languages
@@ -23,12 +24,19 @@ func (h *LanguageHandler) GetLanguageList(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to fetch language list"})
 	}
+	// 表示言語の翻訳があれば名称を差し替え
+	loc := requestLocale(c.Request())
+	names, err := h.trRepo.FindMasterNames("language", nil, loc)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to fetch language list"})
+	}
 	var dtoList []dto.LanguageDTO
 	for _, lang := range langList {
 		dtoList = append(dtoList, dto.LanguageDTO{
 			ID:   lang.ID,
-			Name: lang.Name,
+			Name: localizedName(names, lang.ID, lang.Name),
 		})
 	}
+	setContentLanguage(c.Response().Header(), loc)
 	return c.JSON(http.StatusOK, dtoList)
 }
@@ -0,0 +1,31 @@
+// link_check_handler.go: ポートフォリオURLの確認結果APIハンドラ
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+type LinkCheckHandler struct {
+	svc *service.LinkCheckService
+}
+
+func NewLinkCheckHandler(svc *service.LinkCheckService) *LinkCheckHandler {
+	return &LinkCheckHandler{svc: svc}
+}
+
+// GET /api/v1/me/broken-links（要認証）
+// ログインユーザーの職務経歴書のうちリンク切れのポートフォリオURLを持つ職歴
+func (h *LinkCheckHandler) GetMyBrokenLinks(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+	links, err := h.svc.BrokenLinks(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusOK, links)
+}
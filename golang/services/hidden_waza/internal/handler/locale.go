@@ -0,0 +1,63 @@
+// locale.go: リクエストの表示言語判定（?lang= → Accept-Language → 既定言語）
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+)
+
+// クエリ?lang=を優先し、なければAccept-Languageのq値が高い順に対応言語を探す
+func requestLocale(r *http.Request) domain.Locale {
+	if loc, ok := domain.ParseLocale(r.URL.Query().Get("lang")); ok {
+		return loc
+	}
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		if fields[0] == "" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				if v, err := strconv.ParseFloat(f[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		candidates = append(candidates, candidate{tag: fields[0], q: q})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, c := range candidates {
+		if c.q <= 0 {
+			continue
+		}
+		if loc, ok := domain.ParseLocale(c.tag); ok {
+			return loc
+		}
+	}
+	return domain.DefaultLocale
+}
+
+// レスポンスに表示言語ヘッダを付与
+func setContentLanguage(h http.Header, loc domain.Locale) {
+	h.Set("Content-Language", string(loc))
+	h.Add("Vary", "Accept-Language")
+}
+
+// マスタ名称の翻訳があれば翻訳、なければ元の名称
+func localizedName(names map[uint]string, id uint, name string) string {
+	if tr, ok := names[id]; ok && tr != "" {
+		return tr
+	}
+	return name
+}
@@ -0,0 +1,63 @@
+// login_throttle_handler.go: ログイン試行の制限の管理APIハンドラ（/api/v1/admin、管理者のみ）
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+// 禁止・解除の記録一覧の既定件数
+const defaultLoginLockoutListLimit = 50
+
+type LoginThrottleHandler struct {
+	throttle *service.LoginThrottle
+}
+
+func NewLoginThrottleHandler(throttle *service.LoginThrottle) *LoginThrottleHandler {
+	return &LoginThrottleHandler{throttle: throttle}
+}
+
+// GET /api/v1/admin/login-lockouts?email=&ip=&limit=50&offset=0
+// ログインの禁止・解除の記録（新しい順）
+func (h *LoginThrottleHandler) GetLoginLockouts(c echo.Context) error {
+	limit, ok := limitParam(c.Request(), defaultLoginLockoutListLimit)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+	offset := 0
+	if v := c.QueryParam("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid offset"})
+		}
+		offset = n
+	}
+	events, err := h.throttle.Events(domain.Email(c.QueryParam("email")), c.QueryParam("ip"), limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusOK, events)
+}
+
+// POST /api/v1/admin/login-lockouts/unlock
+// メールアドレス・IPアドレスのログインの禁止を解除（失敗回数・禁止時間の段階も戻す）
+func (h *LoginThrottleHandler) UnlockLogin(c echo.Context) error {
+	var req dto.LoginUnlockRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	adminID, _ := currentUserID(c)
+	if err := h.throttle.Unlock(req.Email, req.IP, adminID); err != nil {
+		if errors.Is(err, service.ErrNothingToUnlock) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
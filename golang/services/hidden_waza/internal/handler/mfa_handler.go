@@ -0,0 +1,296 @@
+// mfa_handler.go: 2段階認証（TOTP）のログイン・登録ハンドラー
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+// MFAトークン（ログインの1段階目で発行）で認証したリクエストかを保存するコンテキストのキー
+const mfaChallengeKey = "mfa_challenge"
+
+// RequireAuthOrMFATokenはX-MFA-TokenヘッダーのMFAトークン、なければrequireAuth（JWT）で認証するミドルウェアです。
+// 2段階認証が権限により必須で未登録のユーザーが、JWTを取得する前に登録するためのルートに使用します。
+func RequireAuthOrMFAToken(requireAuth echo.MiddlewareFunc, mfa *service.MFAService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withJWT := requireAuth(next)
+		return func(c echo.Context) error {
+			mfaToken := c.Request().Header.Get("X-MFA-Token")
+			if mfaToken == "" {
+				return withJWT(c)
+			}
+			user, err := mfa.ParseChallenge(mfaToken)
+			if err != nil {
+				if errors.Is(err, service.ErrInvalidMFAChallenge) {
+					return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+				}
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+			}
+			c.Set(userIDKey, user.ID)
+			c.Set(userRoleKey, user.Role)
+			c.Set(mfaChallengeKey, true)
+			return next(c)
+		}
+	}
+}
+
+// POST /api/v1/login/mfa
+// ログインの2段階目: MFAトークンとTOTPのコード（またはリカバリーコード）をJWTと交換
+// コードの誤りはパスワードの誤りと同じくログイン試行の制限の失敗回数に数える
+func (h *UserHandler) LoginMFA(c echo.Context) error {
+	var req dto.MFALoginRequest
+	if err := c.Bind(&req); err != nil || req.MFAToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	user, err := h.MFA.ParseChallenge(req.MFAToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidMFAChallenge) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	if !user.MFAEnabled {
+		// 権限により必須で未登録（MFAトークンで登録すると、登録の確認でJWTを返す）
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "mfa enrollment required"})
+	}
+	if ok, err := h.verifyMFACode(c, user, req.Code); !ok {
+		h.auditLogin(c, &user.ID, user.Email, domain.AuditFailure, "mfa_verification_failed")
+		return err
+	}
+	tokenStr, err := h.issueLoginToken(c, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "token generation failed"})
+	}
+	return c.JSON(http.StatusOK, loginResponse(user, tokenStr))
+}
+
+// GET /api/v1/mfa（要認証）
+// 2段階認証の状態
+func (h *UserHandler) GetMFAStatus(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	status, err := h.MFA.Status(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusOK, dto.MFAStatusResponse{
+		Enabled:                status.Enabled,
+		Required:               status.Required,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+	})
+}
+
+// POST /api/v1/mfa/totp（JWTまたはX-MFA-Token）
+// 2段階認証の登録を開始（秘密鍵・登録用URI・QRコードを返す。POST /api/v1/mfa/totp/confirmで確認するまでは無効）
+func (h *UserHandler) BeginMFAEnrollment(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	enrollment, err := h.MFA.BeginEnrollment(userID)
+	if err != nil {
+		return mfaError(c, err)
+	}
+	return c.JSON(http.StatusCreated, dto.MFAEnrollmentResponse{
+		Secret: enrollment.Secret,
+		URI:    enrollment.URI,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(enrollment.QRCode),
+	})
+}
+
+// GET /api/v1/mfa/totp/qr.png（JWTまたはX-MFA-Token）
+// 登録中の秘密鍵のQRコード（PNG）
+func (h *UserHandler) GetMFAQRCode(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	enrollment, err := h.MFA.Enrollment(userID)
+	if err != nil {
+		return mfaError(c, err)
+	}
+	// 秘密鍵を含むためキャッシュさせない
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.Blob(http.StatusOK, "image/png", enrollment.QRCode)
+}
+
+// POST /api/v1/mfa/totp/confirm（JWTまたはX-MFA-Token）
+// 認証アプリのコードで登録を確認して2段階認証を有効にし、リカバリーコードを返す（表示はこの1回のみ）
+// MFAトークンで登録した場合はログインを完了し、JWTも返す
+func (h *UserHandler) ConfirmMFAEnrollment(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	var req dto.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	codes, err := h.MFA.ConfirmEnrollment(userID, req.Code)
+	if err != nil {
+		return mfaError(c, err)
+	}
+	resp := dto.MFARecoveryCodesResponse{RecoveryCodes: codes}
+	if challenged, _ := c.Get(mfaChallengeKey).(bool); challenged {
+		user, err := h.Repo.GetByID(userID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+		}
+		if resp.Token, err = h.issueLoginToken(c, user); err != nil {
+			// 登録は完了しているため、ログインからやり直せる
+			log.Printf("login token for user %d after mfa enrollment: %v", userID, err)
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// POST /api/v1/mfa/totp/disable（要認証）
+// コードを確認して2段階認証を無効にする（権限により必須の場合は409）
+func (h *UserHandler) DisableMFA(c echo.Context) error {
+	user, ok, err := h.mfaUser(c)
+	if !ok {
+		return err
+	}
+	var req dto.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	if ok, err := h.checkThrottle(c, user.Email); !ok {
+		return err
+	}
+	if err := h.MFA.Disable(user, req.Code); err != nil {
+		h.mfaCodeFailure(c, user, err)
+		return mfaError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// POST /api/v1/mfa/recovery-codes（要認証）
+// コードを確認してリカバリーコードを再発行する（以前のリカバリーコードは無効）
+func (h *UserHandler) RegenerateMFARecoveryCodes(c echo.Context) error {
+	user, ok, err := h.mfaUser(c)
+	if !ok {
+		return err
+	}
+	var req dto.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	if ok, err := h.checkThrottle(c, user.Email); !ok {
+		return err
+	}
+	codes, err := h.MFA.RegenerateRecoveryCodes(user, req.Code)
+	if err != nil {
+		h.mfaCodeFailure(c, user, err)
+		return mfaError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.MFARecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// コードを検証する（ログインが禁止されていれば429、コードの誤りは401。okがfalseならerrをそのまま返す）
+func (h *UserHandler) verifyMFACode(c echo.Context, user *domain.User, code string) (ok bool, err error) {
+	if ok, err := h.checkThrottle(c, user.Email); !ok {
+		return false, err
+	}
+	if err := h.MFA.Verify(user, code); err != nil {
+		h.mfaCodeFailure(c, user, err)
+		if errors.Is(err, service.ErrInvalidMFACode) {
+			return false, c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		}
+		return false, mfaError(c, err)
+	}
+	return true, nil
+}
+
+// コードの誤りをログイン試行の制限の失敗回数に数える
+func (h *UserHandler) mfaCodeFailure(c echo.Context, user *domain.User, err error) {
+	if !errors.Is(err, service.ErrInvalidMFACode) {
+		return
+	}
+	if err := h.Throttle.Failure(user.Email, c.RealIP()); err != nil {
+		log.Printf("login throttle: %v", err)
+	}
+}
+
+// 認証済みユーザー（okがfalseならerrをそのまま返す）
+func (h *UserHandler) mfaUser(c echo.Context) (user *domain.User, ok bool, err error) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false, c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	user, err = h.Repo.GetByID(userID)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
+	return user, true, nil
+}
+
+// 2段階認証のエラーのレスポンス
+func mfaError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidMFACode):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrMFAAlreadyEnabled), errors.Is(err, service.ErrMFANotEnabled),
+		errors.Is(err, service.ErrMFANotEnrolling), errors.Is(err, service.ErrMFARequiredByRole):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+}
+
+// MFAPolicyHandlerは2段階認証を必須とする権限の管理APIハンドラです（/api/v1/admin、管理者のみ）。
+type MFAPolicyHandler struct {
+	mfa *service.MFAService
+}
+
+func NewMFAPolicyHandler(mfa *service.MFAService) *MFAPolicyHandler {
+	return &MFAPolicyHandler{mfa: mfa}
+}
+
+// GET /api/v1/admin/mfa/required-roles
+// 2段階認証を必須とする権限の一覧
+func (h *MFAPolicyHandler) GetMFARequiredRoles(c echo.Context) error {
+	roles, err := h.mfa.RequiredRoles()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusOK, roles)
+}
+
+// PUT /api/v1/admin/mfa/required-roles/:role
+// 権限に2段階認証を必須とする（次回のログインから。未登録のユーザーはログイン時に登録する）
+func (h *MFAPolicyHandler) PutMFARequiredRole(c echo.Context) error {
+	adminID, _ := currentUserID(c)
+	if err := h.mfa.RequireRole(c.Param("role"), adminID); err != nil {
+		if errors.Is(err, service.ErrInvalidRole) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DELETE /api/v1/admin/mfa/required-roles/:role
+// 権限の2段階認証を必須としない（登録済みのユーザーの2段階認証は有効のまま）
+func (h *MFAPolicyHandler) DeleteMFARequiredRole(c echo.Context) error {
+	if err := h.mfa.UnrequireRole(c.Param("role")); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "role not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,134 @@
+// oidc_handler.go: 外部IdP（OpenID Connect）でのログイン・アカウントの紐付けハンドラー
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+// GET /api/v1/oidc/providers
+// ログインに使用できるIdPの一覧
+func (h *UserHandler) GetOIDCProviders(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.OIDC.Providers())
+}
+
+// GET /api/v1/oidc/:provider/authorize
+// IdPのログイン画面にリダイレクト（302）
+func (h *UserHandler) AuthorizeOIDC(c echo.Context) error {
+	authURL, err := h.OIDC.Authorize(c.Request().Context(), c.Param("provider"), nil)
+	if err != nil {
+		return oidcError(c, err)
+	}
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// GET /api/v1/oidc/:provider/callback?code=&state=
+// IdPからのリダイレクトを処理してログイン（レスポンスはPOST /api/v1/loginと同じ。2段階認証が必要ならMFAトークン）
+// 紐付け（POST /api/v1/me/identities/:provider）の場合は、開始したユーザーのJWTを付けて呼び出すと紐付けたアカウントを返す
+func (h *UserHandler) OIDCCallback(c echo.Context) error {
+	if e := c.QueryParam("error"); e != "" {
+		// IdPでのログインの中止・失敗
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "identity provider error: " + e})
+	}
+	code, state := c.QueryParam("code"), c.QueryParam("state")
+	if code == "" || state == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	var callerID *uint
+	if id, ok := currentUserID(c); ok {
+		callerID = &id
+	}
+	result, err := h.OIDC.Callback(c.Request().Context(), c.Param("provider"), state, code, callerID)
+	if err != nil {
+		h.Audit.Record(c.Request().Context(), service.AuditEvent{
+			Action:     domain.AuditLogin,
+			Outcome:    domain.AuditFailure,
+			TargetType: domain.AuditTargetUser,
+			Details:    map[string]interface{}{"provider": c.Param("provider"), "path": c.Path(), "reason": err.Error()},
+		})
+		return oidcError(c, err)
+	}
+	if result.Linked {
+		return c.JSON(http.StatusOK, result.Identity)
+	}
+	if result.Created {
+		log.Printf("oidc %s: created user %d", c.Param("provider"), result.User.ID)
+	}
+	return h.completeLogin(c, result.User)
+}
+
+// GET /api/v1/me/identities
+// 紐付けたIdPのアカウント一覧
+func (h *UserHandler) GetIdentities(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	identities, err := h.OIDC.Identities(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusOK, identities)
+}
+
+// POST /api/v1/me/identities/:provider
+// IdPのアカウントの紐付けを開始（IdPのログイン画面のURLを返す。コールバックはJWTを付けて呼び出す）
+func (h *UserHandler) LinkIdentity(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	authURL, err := h.OIDC.Authorize(c.Request().Context(), c.Param("provider"), &userID)
+	if err != nil {
+		return oidcError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.OIDCAuthorizeResponse{AuthorizationURL: authURL})
+}
+
+// DELETE /api/v1/me/identities/:id
+// IdPのアカウントの紐付けを解除
+func (h *UserHandler) UnlinkIdentity(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if err := h.OIDC.Unlink(userID, uint(id)); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "identity not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// 外部IdPでのログインのエラーのレスポンス
+func oidcError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrUnknownOIDCProvider):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidOIDCState):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrOIDCLoginFailed):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrOIDCEmailNotVerified):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrOIDCEmailConflict), errors.Is(err, service.ErrOIDCIdentityLinked):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrOIDCProviderUnavailable):
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+}
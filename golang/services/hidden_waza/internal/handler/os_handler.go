@@ -10,11 +10,12 @@ import (
 )
 
 type OSHandler struct {
-	repo *repository.OSRepository
+	repo   *repository.OSRepository
+	trRepo *repository.TranslationRepository
 }
 
-func NewOSHandler(repo *repository.OSRepository) *OSHandler {
-	return &OSHandler{repo: repo}
+func NewOSHandler(repo *repository.OSRepository, trRepo *repository.TranslationRepository) *OSHandler {
+	return &OSHandler{repo: repo, trRepo: trRepo}
 }
 
 // GET /api/v1/os
@@ -23,12 +24,19 @@ func (h *OSHandler) GetOSList(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to fetch OS list"})
 	}
+	// 表示言語の翻訳があれば名称を差し替え
+	loc := requestLocale(c.Request())
+	names, err := h.trRepo.FindMasterNames("os", nil, loc)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to fetch OS list"})
+	}
 	var dtoList []dto.OSDTO
 	for _, os := range osList {
 		dtoList = append(dtoList, dto.OSDTO{
 			ID:   os.ID,
-			Name: os.Name,
+			Name: localizedName(names, os.ID, os.Name),
 		})
 	}
+	setContentLanguage(c.Response().Header(), loc)
 	return c.JSON(http.StatusOK, dtoList)
 }
@@ -14,59 +14,121 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
 	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
 	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+	"gorm.io/gorm"
 )
 
 type ResumeHandler struct {
-	repo *repository.ResumeRepository
+	repo         *repository.ResumeRepository
+	career       *service.CareerService
+	similarity   *service.SimilarityService
+	completeness *service.CompletenessService
+	attachments  *service.AttachmentService
+	linkChecks   *service.LinkCheckService
+	verification *service.EmailVerificationService
+	audit        *service.AuditService
 }
 
-func NewResumeHandler(repo *repository.ResumeRepository) *ResumeHandler {
-	return &ResumeHandler{repo: repo}
+func NewResumeHandler(
+	repo *repository.ResumeRepository,
+	career *service.CareerService,
+	similarity *service.SimilarityService,
+	completeness *service.CompletenessService,
+	attachments *service.AttachmentService,
+	linkChecks *service.LinkCheckService,
+	verification *service.EmailVerificationService,
+	audit *service.AuditService,
+) *ResumeHandler {
+	return &ResumeHandler{
+		repo:         repo,
+		career:       career,
+		similarity:   similarity,
+		completeness: completeness,
+		attachments:  attachments,
+		linkChecks:   linkChecks,
+		verification: verification,
+		audit:        audit,
+	}
 }
 
-func (h *ResumeHandler) CreateResume(w http.ResponseWriter, r *http.Request) {
+// POST /api/v1/resume
+// 職務経歴書を作成（認証済みユーザー自身のもののみ。user_idを省略した場合は認証済みユーザー）
+func (h *ResumeHandler) CreateResume(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
 	var req dto.ResumeDTO
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
-		return
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	if !validTranslationLocales(req) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "unsupported translation locale"})
+	}
+	if req.UserID == 0 {
+		req.UserID = userID
+	}
+	if req.UserID != userID {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+	if status, msg := h.checkEmailVerified(userID); status != 0 {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	skills, err := convertSkillDTOs(req.Skills)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	exps, err := convertExperienceDTOs(req.Experiences)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 	// DTO（ResumeDTO）からドメインモデル（Resume）へ変換
 	resume := domain.Resume{
-		UserID:      req.UserID,
-		Title:       req.Title,
-		Summary:     req.Summary,
-		Skills:      convertSkillDTOs(req.Skills),
-		Experiences: convertExperienceDTOs(req.Experiences),
+		UserID:       req.UserID,
+		Title:        req.Title,
+		Summary:      req.Summary,
+		Skills:       skills,
+		Experiences:  exps,
+		Translations: convertResumeTranslationDTOs(req.Translations),
 	}
 	if err := h.repo.Create(&resume); err != nil {
-		http.Error(w, "DB error", http.StatusInternalServerError)
-		return
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
 	}
+	h.auditResume(c.Request(), domain.AuditResumeCreate, resume.ID, "", h.resumeHash(resume.ID), nil)
 
-	// 登録したResumeをDTOに変換して返す
+	// 登録したResumeを表示言語で解決し、DTOに変換して返す
+	loc := requestLocale(c.Request())
+	localized := resume.Localized(loc)
 	resumeDTO := dto.ResumeDTO{
-		ID:          resume.ID,
-		UserID:      resume.UserID,
-		Title:       resume.Title,
-		Summary:     resume.Summary,
-		Skills:      convertDomainSkillsToDTO(resume.Skills),
-		Experiences: convertDomainExperiencesToDTO(resume.Experiences),
-		CreatedAt:   resume.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   resume.UpdatedAt.Format(time.RFC3339),
-		Verified:    resume.Verified,
+		ID:           resume.ID,
+		UserID:       resume.UserID,
+		Title:        localized.Title,
+		Summary:      localized.Summary,
+		Skills:       convertDomainSkillsToDTO(resume.Skills, loc),
+		Experiences:  convertDomainExperiencesToDTO(localized.Experiences),
+		CreatedAt:    resume.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    resume.UpdatedAt.Format(time.RFC3339),
+		Verified:     resume.Verified,
+		Lang:         string(loc),
+		Translations: convertDomainResumeTranslationsToDTO(resume.Translations),
 	}
+	h.attachMetrics(c.Request(), &resumeDTO, resume.Experiences)
+	resumeDTO.Completeness = h.recordCompleteness(&resume, loc)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(resumeDTO)
+	setContentLanguage(c.Response().Header(), loc)
+	return c.JSON(http.StatusCreated, resumeDTO)
 }
 
 func (h *ResumeHandler) UpdateResume(c echo.Context) error {
@@ -76,64 +138,266 @@ func (h *ResumeHandler) UpdateResume(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
 
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+
 	var req dto.ResumeDTO
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
 	}
+	if !validTranslationLocales(req) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "unsupported translation locale"})
+	}
+	// 所有者の変更はできない（user_idを省略した場合は認証済みユーザー）
+	if req.UserID == 0 {
+		req.UserID = userID
+	}
+	existing, status, msg := h.ownResume(uint(id), userID)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	if req.UserID != userID {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+	if status, msg := h.checkEmailVerified(userID); status != 0 {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	skills, err := convertSkillDTOs(req.Skills)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	exps, err := convertExperienceDTOs(req.Experiences)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
 
 	// DTO→ドメイン
 	resume := domain.Resume{
-		ID:          uint(id),
-		UserID:      req.UserID,
-		Title:       req.Title,
-		Summary:     req.Summary,
-		Skills:      convertSkillDTOs(req.Skills),
-		Experiences: convertExperienceDTOs(req.Experiences),
+		ID:           uint(id),
+		UserID:       req.UserID,
+		Title:        req.Title,
+		Summary:      req.Summary,
+		Skills:       skills,
+		Experiences:  exps,
+		Translations: convertResumeTranslationDTOs(req.Translations),
 	}
 
+	beforeHash := service.ResumeHash(existing)
 	if err := h.repo.Update(&resume); err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
 	}
+	h.auditResume(c.Request(), domain.AuditResumeUpdate, resume.ID, beforeHash, h.resumeHash(resume.ID), nil)
+	h.similarity.Invalidate(resume.ID)
 
 	// 更新後のDTO返却
+	loc := requestLocale(c.Request())
+	localized := resume.Localized(loc)
 	resumeDTO := dto.ResumeDTO{
-		ID:          resume.ID,
-		UserID:      resume.UserID,
-		Title:       resume.Title,
-		Summary:     resume.Summary,
-		Skills:      convertDomainSkillsToDTO(resume.Skills),
-		Experiences: convertDomainExperiencesToDTO(resume.Experiences),
-		CreatedAt:   resume.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   resume.UpdatedAt.Format(time.RFC3339),
-		Verified:    resume.Verified,
+		ID:           resume.ID,
+		UserID:       resume.UserID,
+		Title:        localized.Title,
+		Summary:      localized.Summary,
+		Skills:       convertDomainSkillsToDTO(resume.Skills, loc),
+		Experiences:  convertDomainExperiencesToDTO(localized.Experiences),
+		CreatedAt:    resume.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    resume.UpdatedAt.Format(time.RFC3339),
+		Verified:     resume.Verified,
+		Lang:         string(loc),
+		Translations: convertDomainResumeTranslationsToDTO(resume.Translations),
 	}
+	h.attachMetrics(c.Request(), &resumeDTO, resume.Experiences)
+	resumeDTO.Completeness = h.recordCompleteness(&resume, loc)
+	setContentLanguage(c.Response().Header(), loc)
 	return c.JSON(http.StatusOK, resumeDTO)
 }
 
+// PUT /api/v1/resume/:id/verified（検証担当者・管理者のみ）
+// 職務経歴書の検証済みを変更（内容を更新すると検証済みは解除される）
+func (h *ResumeHandler) VerifyResume(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req dto.ResumeVerifyRequest
+	if err := c.Bind(&req); err != nil || req.Verified == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	beforeHash := h.resumeHash(uint(id))
+	previous, err := h.repo.SetVerified(uint(id), *req.Verified)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "resume not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	if previous != *req.Verified {
+		h.auditResume(c.Request(), domain.AuditResumeVerify, uint(id), beforeHash, h.resumeHash(uint(id)),
+			map[string]interface{}{"verified": *req.Verified, "previous_verified": previous})
+	}
+	return c.JSON(http.StatusOK, dto.ResumeVerifyResponse{ID: uint(id), Verified: *req.Verified})
+}
+
+// 職務経歴書の内容のハッシュ（監査ログ用。存在しない場合は空）
+func (h *ResumeHandler) resumeHash(id uint) string {
+	resume, err := h.repo.GetByID(id)
+	if err != nil {
+		return ""
+	}
+	return service.ResumeHash(resume)
+}
+
+// 職務経歴書の変更を監査ログに記録する
+func (h *ResumeHandler) auditResume(r *http.Request, action string, id uint, beforeHash, afterHash string, details map[string]interface{}) {
+	h.audit.Record(r.Context(), service.AuditEvent{
+		Action:     action,
+		TargetType: domain.AuditTargetResume,
+		TargetID:   strconv.FormatUint(uint64(id), 10),
+		BeforeHash: beforeHash,
+		AfterHash:  afterHash,
+		Details:    details,
+	})
+}
+
+// 認証済みユーザーの職務経歴書を取得する（存在しない場合・他のユーザーのものの場合はstatusが0以外）
+func (h *ResumeHandler) ownResume(id, userID uint) (*domain.Resume, int, string) {
+	return ownResume(h.repo, id, userID)
+}
+
+// メールアドレスが未確認のユーザーは職務経歴書を作成・更新できない（作成・更新できる場合はstatusが0）
+func (h *ResumeHandler) checkEmailVerified(userID uint) (int, string) {
+	return checkEmailVerified(h.verification, userID)
+}
+
+func ownResume(repo *repository.ResumeRepository, id, userID uint) (*domain.Resume, int, string) {
+	resume, err := repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, "resume not found"
+		}
+		return nil, http.StatusInternalServerError, "DB error"
+	}
+	if resume.UserID != userID {
+		return nil, http.StatusForbidden, "forbidden"
+	}
+	return resume, 0, ""
+}
+
+func checkEmailVerified(verification *service.EmailVerificationService, userID uint) (int, string) {
+	err := verification.RequireVerified(userID)
+	switch {
+	case err == nil:
+		return 0, ""
+	case errors.Is(err, service.ErrEmailNotVerified):
+		return http.StatusForbidden, err.Error()
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusBadRequest, "user not found"
+	}
+	return http.StatusInternalServerError, "DB error"
+}
+
+// authorizeResumeWriteは認証済みユーザーが職務経歴書の所有者で、メールアドレスが確認済みであることを確認する
+// （添付ファイル・翻訳等、職務経歴書に付随するデータの書き込み用。書き込みできる場合はstatusが0）
+func authorizeResumeWrite(c echo.Context, repo *repository.ResumeRepository, verification *service.EmailVerificationService, resumeID uint) (int, string) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return http.StatusUnauthorized, "missing token"
+	}
+	if _, status, msg := ownResume(repo, resumeID, userID); status != 0 {
+		return status, msg
+	}
+	return checkEmailVerified(verification, userID)
+}
+
+// ?include=metrics 指定時のみ職歴の集計結果をレスポンスに付与
+func (h *ResumeHandler) attachMetrics(r *http.Request, resumeDTO *dto.ResumeDTO, exps []domain.Experience) {
+	if !includes(r, "metrics") {
+		return
+	}
+	gapMonths, ok := gapMonthsParam(r)
+	if !ok {
+		gapMonths = service.DefaultGapThresholdMonths
+	}
+	metrics := h.career.Compute(exps, gapMonths)
+	resumeDTO.Metrics = &metrics
+}
+
+// 職歴のポートフォリオURLの確認結果を付与（取得に失敗しても職務経歴書は返す）
+func (h *ResumeHandler) attachLinkChecks(exps []dto.ExperienceDTO) {
+	var urls []string
+	for _, e := range exps {
+		if e.PortfolioURL != "" {
+			urls = append(urls, e.PortfolioURL)
+		}
+	}
+	if len(urls) == 0 {
+		return
+	}
+	checks, err := h.linkChecks.ForURLs(urls)
+	if err != nil {
+		log.Printf("link check: failed to load results: %v", err)
+		return
+	}
+	for i := range exps {
+		if c, ok := checks[exps[i].PortfolioURL]; ok {
+			exps[i].LinkCheck = &c
+		}
+	}
+}
+
+// 登録・更新後の充実度スコアを新しいリビジョンとして記録（記録に失敗しても登録・更新自体は成功扱い）
+func (h *ResumeHandler) recordCompleteness(resume *domain.Resume, loc domain.Locale) *domain.CompletenessScore {
+	score, err := h.completeness.Record(resume)
+	if err != nil {
+		log.Printf("completeness: failed to record score for resume %d: %v", resume.ID, err)
+		return nil
+	}
+	service.LocalizeSuggestions(score.Suggestions, loc)
+	return score
+}
+
+// クエリ?include=（カンマ区切り）に指定の値が含まれるか
+func includes(r *http.Request, name string) bool {
+	for _, v := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if strings.TrimSpace(v) == name {
+			return true
+		}
+	}
+	return false
+}
+
 // SkillDTOからdomain.Skillへの変換
 // DTOとドメインモデルの構造やフィールド名が異なる場合もここで吸収可能
-func convertSkillDTOs(dtos []dto.SkillDTO) []domain.Skill {
+// レベルの別名（"上級"等）は正規コードに変換する
+func convertSkillDTOs(dtos []dto.SkillDTO) ([]domain.Skill, error) {
 	var skills []domain.Skill
-	for _, s := range dtos {
+	for i, s := range dtos {
+		level, err := domain.ParseSkillLevel(s.Level)
+		if err != nil {
+			return nil, fmt.Errorf("skills[%d].level: %w", i, err)
+		}
 		skills = append(skills, domain.Skill{
 			Type:     s.Type,
 			MasterID: s.MasterID,
-			Level:    s.Level,
+			Level:    level,
 			Years:    s.Years,
 		})
 	}
-	return skills
+	return skills, nil
 }
 
 // domain.Skill → dto.SkillDTO変換
-func convertDomainSkillsToDTO(skills []domain.Skill) []dto.SkillDTO {
+func convertDomainSkillsToDTO(skills []domain.Skill, loc domain.Locale) []dto.SkillDTO {
 	var dtos []dto.SkillDTO
 	for _, s := range skills {
 		dtos = append(dtos, dto.SkillDTO{
-			Type:     s.Type,
-			MasterID: s.MasterID,
-			Level:    s.Level,
-			Years:    s.Years,
+			Type:       s.Type,
+			MasterID:   s.MasterID,
+			Level:      string(s.Level),
+			Years:      s.Years,
+			LevelRank:  s.Level.Ordinal(),
+			LevelLabel: s.Level.Label(loc),
 		})
 	}
 	return dtos
@@ -141,19 +405,41 @@ func convertDomainSkillsToDTO(skills []domain.Skill) []dto.SkillDTO {
 
 // ExperienceDTOからdomain.Experienceへの変換
 // DTOとドメインモデルの構造やフィールド名が異なる場合もここで吸収可能
-func convertExperienceDTOs(dtos []dto.ExperienceDTO) []domain.Experience {
+// 日付文字列のパースと期間の前後関係チェックもここで行う
+func convertExperienceDTOs(dtos []dto.ExperienceDTO) ([]domain.Experience, error) {
 	var exps []domain.Experience
-	for _, e := range dtos {
-		exps = append(exps, domain.Experience{
+	for i, e := range dtos {
+		start, err := domain.ParseCareerDate(e.StartDate)
+		if err != nil {
+			return nil, fmt.Errorf("experiences[%d].start_date: %w", i, err)
+		}
+		end, err := domain.ParseCareerDate(e.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("experiences[%d].end_date: %w", i, err)
+		}
+		exp := domain.Experience{
+			ID:           e.ID,
 			Company:      e.Company,
 			Position:     e.Position,
-			StartDate:    e.StartDate,
-			EndDate:      e.EndDate,
+			StartDate:    start,
+			EndDate:      end,
 			Description:  e.Description,
 			PortfolioURL: e.PortfolioURL,
-		})
+			Translations: convertExperienceTranslationDTOs(e.Translations),
+		}
+		if err := exp.ValidatePeriod(); err != nil {
+			return nil, fmt.Errorf("experiences[%d]: %w", i, err)
+		}
+		for j, sk := range e.Skills {
+			es := domain.ExperienceSkill{Type: sk.Type, MasterID: sk.MasterID}
+			if !es.IsValid() {
+				return nil, fmt.Errorf("experiences[%d].skills[%d]: invalid skill", i, j)
+			}
+			exp.Skills = append(exp.Skills, es)
+		}
+		exps = append(exps, exp)
 	}
-	return exps
+	return exps, nil
 }
 
 // domain.Experience → dto.ExperienceDTO変換
@@ -161,23 +447,145 @@ func convertDomainExperiencesToDTO(exps []domain.Experience) []dto.ExperienceDTO
 	var dtos []dto.ExperienceDTO
 	for _, e := range exps {
 		dtos = append(dtos, dto.ExperienceDTO{
+			ID:           e.ID,
 			Company:      e.Company,
 			Position:     e.Position,
-			StartDate:    e.StartDate,
-			EndDate:      e.EndDate,
+			StartDate:    e.StartDate.String(),
+			EndDate:      e.EndDate.String(),
 			Description:  e.Description,
 			PortfolioURL: e.PortfolioURL,
+			Translations: convertDomainExperienceTranslationsToDTO(e.Translations),
+			Skills:       convertDomainExperienceSkillsToDTO(e.Skills),
+		})
+	}
+	return dtos
+}
+
+// domain.ExperienceSkill → dto.ExperienceSkillDTO変換
+func convertDomainExperienceSkillsToDTO(skills []domain.ExperienceSkill) []dto.ExperienceSkillDTO {
+	var dtos []dto.ExperienceSkillDTO
+	for _, s := range skills {
+		dtos = append(dtos, dto.ExperienceSkillDTO{
+			Type:     s.Type,
+			MasterID: s.MasterID,
+		})
+	}
+	return dtos
+}
+
+// 翻訳DTO（言語コード→翻訳）からdomain.ResumeTranslationへの変換
+// 指定がない場合（nil）はnil、空の指定（{}）は空のスライス（更新時は翻訳の維持・全削除になる）
+func convertResumeTranslationDTOs(dtos map[string]dto.ResumeTranslationDTO) []domain.ResumeTranslation {
+	if dtos == nil {
+		return nil
+	}
+	trs := make([]domain.ResumeTranslation, 0, len(dtos))
+	for lang, t := range dtos {
+		loc, _ := domain.ParseLocale(lang)
+		trs = append(trs, domain.ResumeTranslation{
+			Locale:  loc,
+			Title:   t.Title,
+			Summary: t.Summary,
+		})
+	}
+	return trs
+}
+
+// domain.ResumeTranslation → 翻訳DTO変換
+func convertDomainResumeTranslationsToDTO(trs []domain.ResumeTranslation) map[string]dto.ResumeTranslationDTO {
+	if len(trs) == 0 {
+		return nil
+	}
+	dtos := make(map[string]dto.ResumeTranslationDTO, len(trs))
+	for _, t := range trs {
+		dtos[string(t.Locale)] = dto.ResumeTranslationDTO{
+			Title:   t.Title,
+			Summary: t.Summary,
+		}
+	}
+	return dtos
+}
+
+// 翻訳DTO（言語コード→翻訳）からdomain.ExperienceTranslationへの変換（nil・空の扱いはconvertResumeTranslationDTOsと同じ）
+func convertExperienceTranslationDTOs(dtos map[string]dto.ExperienceTranslationDTO) []domain.ExperienceTranslation {
+	if dtos == nil {
+		return nil
+	}
+	trs := make([]domain.ExperienceTranslation, 0, len(dtos))
+	for lang, t := range dtos {
+		loc, _ := domain.ParseLocale(lang)
+		trs = append(trs, domain.ExperienceTranslation{
+			Locale:      loc,
+			Position:    t.Position,
+			Description: t.Description,
 		})
 	}
+	return trs
+}
+
+// domain.ExperienceTranslation → 翻訳DTO変換
+func convertDomainExperienceTranslationsToDTO(trs []domain.ExperienceTranslation) map[string]dto.ExperienceTranslationDTO {
+	if len(trs) == 0 {
+		return nil
+	}
+	dtos := make(map[string]dto.ExperienceTranslationDTO, len(trs))
+	for _, t := range trs {
+		dtos[string(t.Locale)] = dto.ExperienceTranslationDTO{
+			Position:    t.Position,
+			Description: t.Description,
+		}
+	}
 	return dtos
 }
 
+// リクエスト中の翻訳キーが全て対応言語かを確認
+func validTranslationLocales(req dto.ResumeDTO) bool {
+	for lang := range req.Translations {
+		if _, ok := domain.ParseLocale(lang); !ok {
+			return false
+		}
+	}
+	for _, e := range req.Experiences {
+		for lang := range e.Translations {
+			if _, ok := domain.ParseLocale(lang); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// GET /api/v1/resume?min_level=advanced&skill_type=language&master_id=2
+// min_level指定時は、そのレベル以上のスキルを持つResumeのみ返す
 func (h *ResumeHandler) GetResumes(w http.ResponseWriter, r *http.Request) {
-	resumes, err := h.repo.GetAll()
+	var resumes []domain.Resume
+	var err error
+	if q := r.URL.Query(); q.Get("min_level") != "" {
+		minLevel, perr := domain.ParseSkillLevel(q.Get("min_level"))
+		if perr != nil {
+			http.Error(w, "Invalid min_level", http.StatusBadRequest)
+			return
+		}
+		var masterID int
+		if v := q.Get("master_id"); v != "" {
+			if masterID, perr = strconv.Atoi(v); perr != nil {
+				http.Error(w, "Invalid master_id", http.StatusBadRequest)
+				return
+			}
+		}
+		resumes, err = h.repo.FindBySkillLevel(q.Get("skill_type"), uint(masterID), minLevel)
+	} else {
+		resumes, err = h.repo.GetAll()
+	}
 	if err != nil {
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
+	loc := requestLocale(r)
+	for i := range resumes {
+		resumes[i] = resumes[i].Localized(loc)
+	}
+	setContentLanguage(w.Header(), loc)
 	json.NewEncoder(w).Encode(resumes)
 }
 
@@ -191,17 +599,30 @@ func (h *ResumeHandler) GetResumeByID(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
 	}
-	// domain.Resume → dto.ResumeDTO 変換
+	// domain.Resume → dto.ResumeDTO 変換（表示言語で解決）
+	loc := requestLocale(c.Request())
+	localized := resume.Localized(loc)
 	dtoResume := dto.ResumeDTO{
-		UserID:      resume.UserID,
-		Title:       resume.Title,
-		Summary:     resume.Summary,
-		Skills:      convertDomainSkillsToDTO(resume.Skills),
-		Experiences: convertDomainExperiencesToDTO(resume.Experiences),
-		CreatedAt:   resume.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   resume.UpdatedAt.Format(time.RFC3339),
-		Verified:    resume.Verified,
+		ID:           resume.ID,
+		UserID:       resume.UserID,
+		Title:        localized.Title,
+		Summary:      localized.Summary,
+		Skills:       convertDomainSkillsToDTO(resume.Skills, loc),
+		Experiences:  convertDomainExperiencesToDTO(localized.Experiences),
+		CreatedAt:    resume.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    resume.UpdatedAt.Format(time.RFC3339),
+		Verified:     resume.Verified,
+		Lang:         string(loc),
+		Translations: convertDomainResumeTranslationsToDTO(resume.Translations),
 	}
+	h.attachMetrics(c.Request(), &dtoResume, resume.Experiences)
+	h.attachLinkChecks(dtoResume.Experiences)
+	if includes(c.Request(), "completeness") {
+		if score, err := h.completeness.Latest(resume.ID, loc); err == nil {
+			dtoResume.Completeness = score
+		}
+	}
+	setContentLanguage(c.Response().Header(), loc)
 	return c.JSON(http.StatusOK, dtoResume)
 }
 
@@ -215,18 +636,42 @@ func (h *ResumeHandler) GetResumesByUserID(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
 	}
+	loc := requestLocale(c.Request())
+	for i := range resumes {
+		resumes[i] = resumes[i].Localized(loc)
+	}
+	setContentLanguage(c.Response().Header(), loc)
 	return c.JSON(http.StatusOK, resumes)
 }
 
 // DELETE /resumes/:id
+// 認証済みユーザー自身の職務経歴書のみ削除できる（存在しない場合は何もしない）
 func (h *ResumeHandler) DeleteResume(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
+	existing, status, msg := h.ownResume(uint(id), userID)
+	if status == http.StatusNotFound {
+		return c.NoContent(http.StatusNoContent)
+	}
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	// 添付ファイルの実体はDBの削除が成功してから削除する
+	keys, err := h.attachments.ObjectKeys(uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
 	if err := h.repo.Delete(uint(id)); err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
 	}
+	h.auditResume(c.Request(), domain.AuditResumeDelete, uint(id), service.ResumeHash(existing), "", nil)
+	h.attachments.RemoveObjects(c.Request().Context(), keys)
 	return c.NoContent(http.StatusNoContent)
 }
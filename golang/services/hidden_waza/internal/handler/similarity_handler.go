@@ -0,0 +1,40 @@
+// similarity_handler.go: 類似職務経歴書の推薦APIハンドラ
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+type SimilarityHandler struct {
+	svc *service.SimilarityService
+}
+
+func NewSimilarityHandler(svc *service.SimilarityService) *SimilarityHandler {
+	return &SimilarityHandler{svc: svc}
+}
+
+// GET /api/v1/resume/:id/similar?limit=10
+// スキル（年数・レベルで重み付け）と本文TF-IDFの類似度が高い職務経歴書を返す
+func (h *SimilarityHandler) GetSimilar(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	limit, ok := limitParam(c.Request(), service.DefaultSimilarLimit)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+	results, err := h.svc.Similar(uint(id), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusOK, results)
+}
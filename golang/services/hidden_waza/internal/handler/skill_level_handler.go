@@ -0,0 +1,31 @@
+// skill_level_handler.go: スキルレベル段階一覧取得APIハンドラ
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+)
+
+type SkillLevelHandler struct{}
+
+func NewSkillLevelHandler() *SkillLevelHandler {
+	return &SkillLevelHandler{}
+}
+
+// GET /api/v1/skill-levels
+func (h *SkillLevelHandler) GetSkillLevelList(c echo.Context) error {
+	loc := requestLocale(c.Request())
+	dtoList := make([]dto.SkillLevelDTO, 0, len(domain.SkillLevels))
+	for _, l := range domain.SkillLevels {
+		dtoList = append(dtoList, dto.SkillLevelDTO{
+			Code:  string(l),
+			Rank:  l.Ordinal(),
+			Label: l.Label(loc),
+		})
+	}
+	setContentLanguage(c.Response().Header(), loc)
+	return c.JSON(http.StatusOK, dtoList)
+}
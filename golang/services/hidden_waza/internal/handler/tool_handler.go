@@ -10,11 +10,12 @@ import (
 )
 
 type ToolHandler struct {
-	repo *repository.ToolRepository
+	repo   *repository.ToolRepository
+	trRepo *repository.TranslationRepository
 }
 
-func NewToolHandler(repo *repository.ToolRepository) *ToolHandler {
-	return &ToolHandler{repo: repo}
+func NewToolHandler(repo *repository.ToolRepository, trRepo *repository.TranslationRepository) *ToolHandler {
+	return &ToolHandler{repo: repo, trRepo: trRepo}
 }
 
 // GET /api/v1/tools
@@ -23,12 +24,19 @@ func (h *ToolHandler) GetToolList(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to fetch tool list"})
 	}
+	// 表示言語の翻訳があれば名称を差し替え
+	loc := requestLocale(c.Request())
+	names, err := h.trRepo.FindMasterNames("tool", nil, loc)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to fetch tool list"})
+	}
 	var dtoList []dto.ToolDTO
 	for _, tool := range toolList {
 		dtoList = append(dtoList, dto.ToolDTO{
 			ID:   tool.ID,
-			Name: tool.Name,
+			Name: localizedName(names, tool.ID, tool.Name),
 		})
 	}
+	setContentLanguage(c.Response().Header(), loc)
 	return c.JSON(http.StatusOK, dtoList)
 }
@@ -0,0 +1,122 @@
+// translation_handler.go: 多言語翻訳の言語単位登録/削除APIハンドラ
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+type TranslationHandler struct {
+	svc          *service.TranslationService
+	resumes      *repository.ResumeRepository
+	verification *service.EmailVerificationService
+}
+
+func NewTranslationHandler(svc *service.TranslationService, resumes *repository.ResumeRepository, verification *service.EmailVerificationService) *TranslationHandler {
+	return &TranslationHandler{svc: svc, resumes: resumes, verification: verification}
+}
+
+// PUT /api/v1/resume/:id/translations/:lang
+// 認証済みユーザー自身の職務経歴書のみ（メールアドレスの確認が必要）
+func (h *TranslationHandler) PutResumeTranslation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if status, msg := authorizeResumeWrite(c, h.resumes, h.verification, uint(id)); status != 0 {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	var req dto.ResumeLocaleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	tr := domain.ResumeTranslation{Title: req.Title, Summary: req.Summary}
+	var exps []domain.ExperienceTranslation
+	for _, e := range req.Experiences {
+		exps = append(exps, domain.ExperienceTranslation{
+			ExperienceID: e.ExperienceID,
+			Position:     e.Position,
+			Description:  e.Description,
+		})
+	}
+	if err := h.svc.SetResumeTranslation(uint(id), pathLocale(c), tr, exps); err != nil {
+		return translationError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DELETE /api/v1/resume/:id/translations/:lang
+// 認証済みユーザー自身の職務経歴書のみ（メールアドレスの確認が必要）
+func (h *TranslationHandler) DeleteResumeTranslation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if status, msg := authorizeResumeWrite(c, h.resumes, h.verification, uint(id)); status != 0 {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	if err := h.svc.DeleteResumeTranslation(uint(id), pathLocale(c)); err != nil {
+		return translationError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PUT /api/v1/admin/masters/:type/:id/translations/:lang （:typeはlanguage/tool/os、管理者のみ）
+func (h *TranslationHandler) PutMasterTranslation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req dto.MasterTranslationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	tr := domain.MasterTranslation{
+		MasterType: c.Param("type"),
+		MasterID:   uint(id),
+		Locale:     pathLocale(c),
+		Name:       req.Name,
+	}
+	if err := h.svc.SetMasterTranslation(tr); err != nil {
+		return translationError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DELETE /api/v1/admin/masters/:type/:id/translations/:lang
+func (h *TranslationHandler) DeleteMasterTranslation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if err := h.svc.DeleteMasterTranslation(c.Param("type"), uint(id), pathLocale(c)); err != nil {
+		return translationError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// パスパラメータ:langを対応言語に変換（非対応なら空値）
+func pathLocale(c echo.Context) domain.Locale {
+	loc, _ := domain.ParseLocale(c.Param("lang"))
+	return loc
+}
+
+// サービス層エラー → HTTPレスポンス変換
+func translationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrUnsupportedLocale):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "unsupported locale"})
+	case errors.Is(err, service.ErrInvalidTranslation):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid translation"})
+	case errors.Is(err, service.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+}
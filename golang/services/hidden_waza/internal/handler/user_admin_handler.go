@@ -0,0 +1,60 @@
+// user_admin_handler.go: ユーザーの管理APIハンドラ（/api/v1/admin、管理者のみ）
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+	"gorm.io/gorm"
+)
+
+type UserAdminHandler struct {
+	users *repository.UserRepository
+	audit *service.AuditService
+}
+
+func NewUserAdminHandler(users *repository.UserRepository, audit *service.AuditService) *UserAdminHandler {
+	return &UserAdminHandler{users: users, audit: audit}
+}
+
+// PUT /api/v1/admin/users/:id/role
+// ユーザーの権限を変更（user / verifier / admin。変更前に発行したそのユーザーのJWTは無効になる）
+// 管理者がいなくならないよう、自分自身の権限は変更できない
+func (h *UserAdminHandler) PutUserRole(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req dto.UserRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	if !domain.IsValidRole(req.Role) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid role"})
+	}
+	if adminID, _ := currentUserID(c); adminID == uint(id) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "cannot change your own role"})
+	}
+	previous, err := h.users.UpdateRole(uint(id), req.Role)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	if previous != req.Role {
+		h.audit.Record(c.Request().Context(), service.AuditEvent{
+			Action:     domain.AuditUserRoleChange,
+			TargetType: domain.AuditTargetUser,
+			TargetID:   strconv.FormatUint(id, 10),
+			Details:    map[string]interface{}{"from": previous, "to": req.Role},
+		})
+	}
+	return c.JSON(http.StatusOK, dto.UserRoleRequest{Role: req.Role})
+}
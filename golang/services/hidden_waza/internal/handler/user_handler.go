@@ -3,22 +3,46 @@
 package handler
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
 	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+	"gorm.io/gorm"
 )
 
 type UserHandler struct {
 	Repo UserRepository
+	// メール通知（nilなら送信しない）
+	Notifications *service.NotificationService
+	// メールアドレスの確認
+	Verification *service.EmailVerificationService
+	// パスワードの再設定
+	PasswordReset *service.PasswordResetService
+	// ログイン試行の制限
+	Throttle *service.LoginThrottle
+	// 2段階認証
+	MFA *service.MFAService
+	// 外部IdP（OpenID Connect）でのログイン
+	OIDC *service.OIDCService
+	// 監査ログ
+	Audit *service.AuditService
 }
 
+// ユーザーがいない場合の照合用（パスワードの照合にかかる時間を揃える）
+var dummyPasswordHash, _ = domain.NewPasswordHash("hidden-waza-dummy-password")
+
 type UserRepository interface {
 	CreateUser(user *domain.User) error
 	FindByEmail(email domain.Email) (*domain.User, error)
+	GetByID(id uint) (*domain.User, error)
+	RecordLoginIP(userID uint, ip string, now time.Time) (isNew, firstLogin bool, err error)
 }
 
 func (h *UserHandler) Register(c echo.Context) error {
@@ -26,6 +50,9 @@ func (h *UserHandler) Register(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
 	}
+	if !req.Email.IsValid() {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid email"})
+	}
 	hash, err := domain.NewPasswordHash(req.Password)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid password"})
@@ -35,16 +62,23 @@ func (h *UserHandler) Register(c echo.Context) error {
 		Username:     req.Username,
 		Email:        req.Email,
 		PasswordHash: hash,
+		Locale:       requestLocale(c.Request()),
+		EmailStatus:  domain.EmailPending,
 		CreatedAt:    nowStr,
 		UpdatedAt:    nowStr,
 	}
 	if err := h.Repo.CreateUser(user); err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to create user"})
 	}
+	// 送信はジョブで非同期に行うため、失敗してもログのみ（POST /api/v1/signup/resendで再送できる）
+	if err := h.Verification.Send(user); err != nil {
+		log.Printf("verification mail for user %d: %v", user.ID, err)
+	}
 	resp := dto.UserRegisterResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		EmailStatus: user.EmailStatus,
 	}
 	return c.JSON(http.StatusCreated, resp)
 }
@@ -54,32 +88,218 @@ func (h *UserHandler) Login(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
 	}
+	ip := c.RealIP()
+	if ok, err := h.checkThrottle(c, req.Email); !ok {
+		h.auditLogin(c, nil, req.Email, domain.AuditFailure, "locked")
+		return err
+	}
+	// ユーザーの有無・パスワードの誤りは同じエラーにする（登録済みのメールアドレスを推測されないように）
 	user, err := h.Repo.FindByEmail(req.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	if user == nil {
+		// 応答時間の差からも推測されないよう、ユーザーがいない場合もパスワードを照合する
+		dummyPasswordHash.Verify(req.Password)
+	}
+	if user == nil || !user.PasswordHash.Verify(req.Password) {
+		if err := h.Throttle.Failure(req.Email, ip); err != nil {
+			log.Printf("login throttle: %v", err)
+		}
+		var userID *uint
+		if user != nil {
+			userID = &user.ID
+		}
+		h.auditLogin(c, userID, req.Email, domain.AuditFailure, "invalid_credentials")
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid email or password"})
+	}
+	return h.completeLogin(c, user)
+}
+
+// 本人確認（パスワード・外部IdP）を終えたユーザーのログインのレスポンス
+func (h *UserHandler) completeLogin(c echo.Context, user *domain.User) error {
+	// 2段階認証が有効・権限により必須なら、JWTの代わりにMFAトークンを返す（POST /api/v1/login/mfaでJWTと交換）
+	required, err := h.MFA.Required(user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	if required {
+		mfaToken, expiresAt := h.MFA.NewChallenge(user)
+		return c.JSON(http.StatusOK, dto.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			MFAEnrolled: user.MFAEnabled,
+			ExpiresAt:   expiresAt,
+		})
+	}
+
+	tokenStr, err := h.issueLoginToken(c, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "token generation failed"})
+	}
+	return c.JSON(http.StatusOK, loginResponse(user, tokenStr))
+}
+
+// POST /api/v1/signup/verify
+// 確認メールのリンクのトークンでメールアドレスを確認済みにする（トークンは1回のみ使用可能）
+func (h *UserHandler) VerifyEmail(c echo.Context) error {
+	var req dto.EmailVerifyRequest
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	user, err := h.Verification.Verify(req.Token)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "user not found"})
+		if errors.Is(err, service.ErrInvalidVerificationToken) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+	}
+	return c.JSON(http.StatusOK, dto.UserRegisterResponse{
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		EmailStatus: user.EmailStatus,
+	})
+}
+
+// POST /api/v1/signup/resend（要認証）
+// 確認メールを再送（前回の送信から一定時間は429、以前のリンクは無効になる）
+func (h *UserHandler) ResendVerification(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
 	}
-	if !user.PasswordHash.Verify(req.Password) {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid password"})
+	err := h.Verification.Resend(userID)
+	var cooldown *service.ResendCooldownError
+	switch {
+	case err == nil:
+		return c.NoContent(http.StatusAccepted)
+	case errors.As(err, &cooldown):
+		c.Response().Header().Set("Retry-After", strconv.Itoa(cooldown.Seconds()))
+		return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrEmailAlreadyVerified):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
 	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+}
 
-	// JWT生成
-	secret := []byte("your-secret-key") // TODO: .env等で管理
+// POST /api/v1/password/forgot
+// パスワード再設定のメールを送信（アカウントの有無が分からないよう、常に202を返す）
+func (h *UserHandler) ForgotPassword(c echo.Context) error {
+	var req dto.PasswordForgotRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	if err := h.PasswordReset.Forgot(req.Email); err != nil {
+		log.Printf("password reset mail: %v", err)
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+// POST /api/v1/password/reset
+// 再設定メールのトークンでパスワードを変更（トークンは1回のみ使用可能。変更前に発行したJWTはすべて無効）
+func (h *UserHandler) ResetPassword(c echo.Context) error {
+	var req dto.PasswordResetRequest
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	err := h.PasswordReset.Reset(req.Token, req.Password)
+	switch {
+	case err == nil:
+		return c.NoContent(http.StatusNoContent)
+	case errors.Is(err, service.ErrInvalidPassword):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid password"})
+	case errors.Is(err, service.ErrInvalidResetToken):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+}
+
+// ログインのJWTを発行する（ログインの成功としてメールアドレスの失敗回数を戻し、ログインしたIPアドレスを記録）
+func (h *UserHandler) issueLoginToken(c echo.Context, user *domain.User) (string, error) {
+	if err := h.Throttle.Success(user.Email); err != nil {
+		log.Printf("login throttle: %v", err)
+	}
+	if len(jwtSecret) == 0 {
+		return "", errors.New("jwt signing key is not set")
+	}
+	// tvはJWTの世代（パスワードの再設定で以前のJWTを無効にする）。roleは表示用（認可にはDBの権限を使う）
+	now := time.Now()
 	claims := jwt.MapClaims{
 		"user_id": user.ID,
 		"email":   string(user.Email),
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+		"role":    user.Role,
+		"tv":      user.TokenVersion,
+		"iat":     now.Unix(),
+		"exp":     now.Add(24 * time.Hour).Unix(),
 	}
-	tokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenStr, err := tokenObj.SignedString(secret)
+	tokenStr, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "token generation failed"})
+		return "", err
 	}
+	h.recordLogin(c, user)
+	h.auditLogin(c, &user.ID, user.Email, domain.AuditSuccess, "")
+	return tokenStr, nil
+}
 
-	resp := dto.UserLoginResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		Token:    tokenStr,
+// ログインの成功・失敗を監査ログに記録する（pathでログインの方法（パスワード・外部IdP・2段階認証）が分かる）
+func (h *UserHandler) auditLogin(c echo.Context, userID *uint, email domain.Email, outcome, reason string) {
+	details := map[string]interface{}{"email": string(email), "path": c.Path()}
+	if reason != "" {
+		details["reason"] = reason
+	}
+	ev := service.AuditEvent{
+		Action:      domain.AuditLogin,
+		Outcome:     outcome,
+		ActorUserID: userID,
+		TargetType:  domain.AuditTargetUser,
+		Details:     details,
+	}
+	if userID != nil {
+		ev.TargetID = strconv.FormatUint(uint64(*userID), 10)
+	}
+	h.Audit.Record(c.Request().Context(), ev)
+}
+
+func loginResponse(user *domain.User, token string) dto.UserLoginResponse {
+	return dto.UserLoginResponse{
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		EmailStatus: user.EmailStatus,
+		Token:       token,
+	}
+}
+
+// メールアドレス・IPアドレスのログインが禁止されていれば429（Retry-After付き）を返す（okがfalseならerrをそのまま返す）
+func (h *UserHandler) checkThrottle(c echo.Context, email domain.Email) (ok bool, err error) {
+	err = h.Throttle.Check(email, c.RealIP())
+	if err == nil {
+		return true, nil
+	}
+	var locked *service.LoginLockedError
+	if errors.As(err, &locked) {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(locked.Seconds()))
+		return false, c.JSON(http.StatusTooManyRequests, map[string]string{"error": locked.Error()})
+	}
+	return false, c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+}
+
+// ログインしたIPアドレスを記録し、初回ログイン以外で初めてのIPアドレスならメールで通知（失敗してもログインは成功させる）
+func (h *UserHandler) recordLogin(c echo.Context, user *domain.User) {
+	now := time.Now()
+	ip := c.RealIP()
+	isNew, firstLogin, err := h.Repo.RecordLoginIP(user.ID, ip, now)
+	if err != nil {
+		log.Printf("record login ip for user %d: %v", user.ID, err)
+		return
+	}
+	if !isNew || firstLogin || h.Notifications == nil {
+		return
+	}
+	if err := h.Notifications.SendNewLoginAlert(user, ip, c.Request().UserAgent(), now); err != nil {
+		log.Printf("new login alert for user %d: %v", user.ID, err)
 	}
-	return c.JSON(http.StatusOK, resp)
 }
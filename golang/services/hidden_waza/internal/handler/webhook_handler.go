@@ -0,0 +1,186 @@
+// webhook_handler.go: Webhookの購読・配信ログの管理APIハンドラ（/api/v1/admin、管理者のみ）
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/api/v1/dto"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/service"
+)
+
+// 配信一覧の既定件数
+const defaultDeliveryListLimit = 50
+
+type WebhookHandler struct {
+	webhooks *service.WebhookService
+}
+
+func NewWebhookHandler(webhooks *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhooks: webhooks}
+}
+
+// POST /api/v1/admin/webhooks
+func (h *WebhookHandler) CreateWebhook(c echo.Context) error {
+	var req dto.WebhookSubscriptionDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	sub := convertWebhookSubscriptionDTO(req)
+	sub.Secret = req.Secret
+	if err := h.webhooks.CreateSubscription(sub, req.Events); err != nil {
+		return webhookError(c, err)
+	}
+	res := convertDomainWebhookSubscriptionToDTO(sub)
+	res.Secret = sub.Secret
+	return c.JSON(http.StatusCreated, res)
+}
+
+// GET /api/v1/admin/webhooks
+func (h *WebhookHandler) GetWebhooks(c echo.Context) error {
+	subs, err := h.webhooks.Subscriptions()
+	if err != nil {
+		return webhookError(c, err)
+	}
+	dtoList := make([]dto.WebhookSubscriptionDTO, 0, len(subs))
+	for i := range subs {
+		dtoList = append(dtoList, convertDomainWebhookSubscriptionToDTO(&subs[i]))
+	}
+	return c.JSON(http.StatusOK, dtoList)
+}
+
+// GET /api/v1/admin/webhooks/:id
+func (h *WebhookHandler) GetWebhook(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	sub, err := h.webhooks.GetSubscription(uint(id))
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, convertDomainWebhookSubscriptionToDTO(sub))
+}
+
+// PUT /api/v1/admin/webhooks/:id （署名の鍵は変更しない）
+func (h *WebhookHandler) UpdateWebhook(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req dto.WebhookSubscriptionDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	sub := convertWebhookSubscriptionDTO(req)
+	sub.ID = uint(id)
+	updated, err := h.webhooks.UpdateSubscription(sub, req.Events)
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, convertDomainWebhookSubscriptionToDTO(updated))
+}
+
+// DELETE /api/v1/admin/webhooks/:id （配信ログも削除）
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if err := h.webhooks.DeleteSubscription(uint(id)); err != nil {
+		return webhookError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GET /api/v1/admin/webhooks/:id/deliveries?limit=50&offset=0
+func (h *WebhookHandler) GetWebhookDeliveries(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	limit, ok := limitParam(c.Request(), defaultDeliveryListLimit)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+	offset := 0
+	if v := c.QueryParam("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid offset"})
+		}
+		offset = n
+	}
+	deliveries, err := h.webhooks.Deliveries(uint(id), limit, offset)
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// GET /api/v1/admin/webhook-deliveries/:id
+// 配信と試行ごとのログ（attempt_logs）
+func (h *WebhookHandler) GetWebhookDelivery(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	d, err := h.webhooks.Delivery(uint(id))
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, d)
+}
+
+// POST /api/v1/admin/webhook-deliveries/:id/redeliver
+// 同じイベントを新しい配信として再送（202で新しい配信を返す）
+func (h *WebhookHandler) RedeliverWebhook(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	d, err := h.webhooks.Redeliver(uint(id))
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, d)
+}
+
+func webhookError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	case errors.Is(err, service.ErrInvalidWebhookURL), errors.Is(err, service.ErrInvalidWebhookEvents):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "DB error"})
+}
+
+func convertWebhookSubscriptionDTO(req dto.WebhookSubscriptionDTO) *domain.WebhookSubscription {
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	return &domain.WebhookSubscription{
+		URL:         req.URL,
+		Description: req.Description,
+		Active:      active,
+	}
+}
+
+func convertDomainWebhookSubscriptionToDTO(sub *domain.WebhookSubscription) dto.WebhookSubscriptionDTO {
+	active := sub.Active
+	return dto.WebhookSubscriptionDTO{
+		ID:          sub.ID,
+		URL:         sub.URL,
+		Events:      sub.EventList(),
+		Description: sub.Description,
+		Active:      &active,
+		CreatedAt:   sub.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   sub.UpdatedAt.Format(time.RFC3339),
+	}
+}
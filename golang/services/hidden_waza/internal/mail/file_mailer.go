@@ -0,0 +1,48 @@
+// file_mailer.go: 開発用の送信（メールを.emlファイルとしてディレクトリに保存）
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailerはメールを送信せずdirに保存します。
+type FileMailer struct {
+	dir  string
+	from string
+	now  func() time.Time
+}
+
+// NewFileMailerはFileMailerを生成します（dirがなければ作成）。
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if _, err := parseAddress(from); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir, from: from, now: time.Now}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	from, err := parseAddress(m.from)
+	if err != nil {
+		return err
+	}
+	to, err := parseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+	now := m.now()
+	r := make([]byte, 4)
+	if _, err := rand.Read(r); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405"), hex.EncodeToString(r))
+	return os.WriteFile(filepath.Join(m.dir, name), buildMessage(from, to, msg, now), 0o644)
+}
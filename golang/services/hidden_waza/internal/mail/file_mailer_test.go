@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	m, err := NewFileMailer(dir, "hidden-waza <noreply@example.com>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.now = func() time.Time { return time.Date(2025, 8, 1, 9, 30, 0, 0, time.UTC) }
+
+	for i := 0; i < 2; i++ {
+		if err := m.Send(context.Background(), testMessage); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同じ時刻に送っても別のファイルになる
+	if len(files) != 2 {
+		t.Fatalf("%d files, want 2", len(files))
+	}
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name(), "20250801T093000-") || filepath.Ext(f.Name()) != ".eml" {
+			t.Errorf("file name = %q", f.Name())
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkMessage(t, string(raw))
+	}
+}
+
+func TestFileMailerInvalidAddress(t *testing.T) {
+	m, err := NewFileMailer(t.TempDir(), "noreply@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(context.Background(), &Message{To: "not an address", Subject: "s", Text: "t"}); !IsPermanent(err) {
+		t.Errorf("Send = %v, want ErrInvalidAddress", err)
+	}
+}
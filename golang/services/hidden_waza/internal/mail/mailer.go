@@ -0,0 +1,32 @@
+// Package mail はメール送信（SMTP・開発用のファイル出力）とテンプレートを提供します。
+package mail
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+)
+
+// Messageは送信するメール1通です（本文はプレーンテキスト）。
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+// Mailerはメールを送信します。
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// ErrInvalidAddressは宛先・差出人のアドレスが不正な場合のエラー
+var ErrInvalidAddress = errors.New("mail: invalid address")
+
+// IsPermanentは再送しても成功しないエラー（不正なアドレス・SMTPの5xx応答）かを返します。
+func IsPermanent(err error) bool {
+	if errors.Is(err, ErrInvalidAddress) {
+		return true
+	}
+	var te *textproto.Error
+	return errors.As(err, &te) && te.Code >= 500
+}
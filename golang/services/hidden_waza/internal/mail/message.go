@@ -0,0 +1,60 @@
+// message.go: 送信用のメッセージ（RFC 5322形式）の組み立て
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// 宛先・差出人のアドレスを検証する（ヘッダーインジェクション対策で改行も拒否）
+func parseAddress(s string) (*mail.Address, error) {
+	if strings.ContainsAny(s, "\r\n") {
+		return nil, ErrInvalidAddress
+	}
+	a, err := mail.ParseAddress(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddress, s)
+	}
+	return a, nil
+}
+
+// メッセージ本体（ヘッダー＋base64の本文）を返す
+func buildMessage(from *mail.Address, to *mail.Address, msg *Message, now time.Time) []byte {
+	var b bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.BEncoding.Encode("UTF-8", strings.NewReplacer("\r", "", "\n", " ").Replace(msg.Subject)))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from.Address))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="UTF-8"`)
+	header("Content-Transfer-Encoding", "base64")
+	b.WriteString("\r\n")
+	text := strings.ReplaceAll(strings.ReplaceAll(msg.Text, "\r\n", "\n"), "\n", "\r\n")
+	enc := base64.StdEncoding.EncodeToString([]byte(text))
+	// 1行76文字で折り返す
+	for len(enc) > 76 {
+		b.WriteString(enc[:76] + "\r\n")
+		enc = enc[76:]
+	}
+	b.WriteString(enc + "\r\n")
+	return b.Bytes()
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	r := make([]byte, 16)
+	rand.Read(r)
+	return "<" + hex.EncodeToString(r) + "@" + domain + ">"
+}
@@ -0,0 +1,107 @@
+// smtp_mailer.go: SMTPサーバー経由の送信
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfigはSMTPサーバーの接続設定です。
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // 空なら認証しない
+	Password string
+	// サーバーがSTARTTLSに対応していない場合に送信を失敗させる（認証する場合は常に必須）
+	RequireTLS bool
+	Timeout    time.Duration
+}
+
+// SMTPMailerはSMTPでメールを送信します（サーバーが対応していればSTARTTLSを使用）。
+type SMTPMailer struct {
+	cfg  SMTPConfig
+	from string
+	now  func() time.Time
+	// サーバー証明書の検証に使うCA（nilならシステムのCA。テスト用）
+	rootCAs *x509.CertPool
+}
+
+// NewSMTPMailerはSMTPMailerを生成します。
+func NewSMTPMailer(cfg SMTPConfig, from string) (*SMTPMailer, error) {
+	if cfg.Host == "" || cfg.Port == 0 {
+		return nil, errors.New("mail: smtp host and port are required")
+	}
+	if _, err := parseAddress(from); err != nil {
+		return nil, err
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &SMTPMailer{cfg: cfg, from: from, now: time.Now}, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	from, err := parseAddress(m.from)
+	if err != nil {
+		return err
+	}
+	to, err := parseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	dialer := net.Dialer{Timeout: m.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	// 接続の期限は実時刻（nowはDateヘッダー用）
+	deadline := time.Now().Add(m.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	c, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.cfg.Host, RootCAs: m.rootCAs}); err != nil {
+			return err
+		}
+	} else if m.cfg.RequireTLS || m.cfg.Username != "" {
+		return errors.New("mail: smtp server does not support STARTTLS")
+	}
+	if m.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to.Address); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(buildMessage(from, to, msg, m.now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
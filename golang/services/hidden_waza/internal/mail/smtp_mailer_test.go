@@ -0,0 +1,360 @@
+package mail
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPはテスト用のSMTPサーバーです（1接続のみ受け付ける）。
+type fakeSMTP struct {
+	ln net.Listener
+	// nilならSTARTTLSに対応しない
+	tlsConfig *tls.Config
+	// 0以外ならRCPTにこのコードで応答する
+	rcptCode int
+	got      smtpSession
+	done     chan struct{}
+}
+
+// smtpSessionはサーバーが受け取った内容です。
+type smtpSession struct {
+	Commands []string
+	TLS      bool
+	Auth     string // AUTH PLAINの資格情報（デコード済み）
+	AuthTLS  bool   // AUTHがTLS上で行われたか
+	From     string
+	To       string
+	Data     string
+}
+
+func startFakeSMTP(t *testing.T, tlsConfig *tls.Config) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{ln: ln, tlsConfig: tlsConfig, done: make(chan struct{})}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTP) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+// wait はセッションの終了を待って受け取った内容を返す
+func (s *fakeSMTP) wait(t *testing.T) smtpSession {
+	t.Helper()
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("smtp session did not finish")
+	}
+	return s.got
+}
+
+func (s *fakeSMTP) serve() {
+	defer close(s.done)
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 fake.test ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			tp.PrintfLine("500 empty command")
+			continue
+		}
+		cmd := strings.ToUpper(fields[0])
+		s.got.Commands = append(s.got.Commands, cmd)
+		switch cmd {
+		case "EHLO", "HELO":
+			ext := []string{"fake.test"}
+			if s.tlsConfig != nil && !s.got.TLS {
+				ext = append(ext, "STARTTLS")
+			}
+			ext = append(ext, "AUTH PLAIN", "8BITMIME")
+			for i, e := range ext {
+				sep := "-"
+				if i == len(ext)-1 {
+					sep = " "
+				}
+				tp.PrintfLine("250%s%s", sep, e)
+			}
+		case "STARTTLS":
+			tp.PrintfLine("220 2.0.0 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			tp = textproto.NewConn(tlsConn)
+			s.got.TLS = true
+		case "AUTH":
+			if len(fields) != 3 || strings.ToUpper(fields[1]) != "PLAIN" {
+				tp.PrintfLine("504 5.5.4 unsupported")
+				continue
+			}
+			cred, _ := base64.StdEncoding.DecodeString(fields[2])
+			s.got.Auth = string(cred)
+			s.got.AuthTLS = s.got.TLS
+			tp.PrintfLine("235 2.7.0 authenticated")
+		case "MAIL":
+			s.got.From = line
+			tp.PrintfLine("250 2.1.0 ok")
+		case "RCPT":
+			if s.rcptCode != 0 {
+				tp.PrintfLine("%d 5.1.1 no such user", s.rcptCode)
+				continue
+			}
+			s.got.To = line
+			tp.PrintfLine("250 2.1.5 ok")
+		case "DATA":
+			tp.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.got.Data = string(data)
+			tp.PrintfLine("250 2.0.0 queued")
+		case "QUIT":
+			tp.PrintfLine("221 2.0.0 bye")
+			return
+		default:
+			tp.PrintfLine("250 ok")
+		}
+	}
+}
+
+// 127.0.0.1用の自己署名証明書
+func testCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake smtp"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+func newTestSMTPMailer(t *testing.T, cfg SMTPConfig) *SMTPMailer {
+	t.Helper()
+	cfg.Host = "127.0.0.1"
+	cfg.Timeout = 5 * time.Second
+	m, err := NewSMTPMailer(cfg, "hidden-waza <noreply@example.com>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.now = func() time.Time { return time.Date(2025, 8, 1, 9, 30, 0, 0, time.UTC) }
+	return m
+}
+
+var testMessage = &Message{
+	To:      "山田 太郎 <taro@example.com>",
+	Subject: "メールアドレスの確認\nをお願いします",
+	Text:    "山田 太郎 さん\n\nhttps://example.com/verify?token=abc\n" + strings.Repeat("長い本文。", 40) + "\n",
+}
+
+// decodeMessageはメッセージをパースし、ヘッダーとデコードした件名・本文を返す
+func decodeMessage(t *testing.T, raw string) (mail.Header, string, string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decode subject: %v", err)
+	}
+	encoded, err := io.ReadAll(msg.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(encoded), "\r\n"), "\n") {
+		if n := len(strings.TrimRight(line, "\r")); n > 76 {
+			t.Errorf("body line is %d chars, want <= 76", n)
+		}
+	}
+	body, err := base64.StdEncoding.DecodeString(strings.NewReplacer("\r", "", "\n", "").Replace(string(encoded)))
+	if err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	return msg.Header, subject, string(body)
+}
+
+// checkMessageはtestMessageが正しくエンコードされているかを確認する
+func checkMessage(t *testing.T, raw string) {
+	t.Helper()
+	header, subject, body := decodeMessage(t, raw)
+	if want := "メールアドレスの確認 をお願いします"; subject != want {
+		t.Errorf("Subject = %q, want %q", subject, want)
+	}
+	if want := strings.ReplaceAll(testMessage.Text, "\n", "\r\n"); body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+	from, err := header.AddressList("From")
+	if err != nil || len(from) != 1 || from[0].Address != "noreply@example.com" || from[0].Name != "hidden-waza" {
+		t.Errorf("From = %v (%v)", from, err)
+	}
+	to, err := header.AddressList("To")
+	if err != nil || len(to) != 1 || to[0].Address != "taro@example.com" || to[0].Name != "山田 太郎" {
+		t.Errorf("To = %v (%v)", to, err)
+	}
+	if date, err := header.Date(); err != nil || !date.Equal(time.Date(2025, 8, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v (%v)", date, err)
+	}
+	if id := header.Get("Message-ID"); !strings.HasPrefix(id, "<") || !strings.HasSuffix(id, "@example.com>") {
+		t.Errorf("Message-ID = %q", id)
+	}
+	for k, want := range map[string]string{
+		"MIME-Version":              "1.0",
+		"Content-Type":              `text/plain; charset="UTF-8"`,
+		"Content-Transfer-Encoding": "base64",
+	} {
+		if got := header.Get(k); got != want {
+			t.Errorf("%s = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestSMTPMailerStartTLSAndAuth(t *testing.T) {
+	cert, pool := testCertificate(t)
+	srv := startFakeSMTP(t, &tls.Config{Certificates: []tls.Certificate{cert}})
+	m := newTestSMTPMailer(t, SMTPConfig{Port: srv.port(), Username: "mailer", Password: "secret"})
+	m.rootCAs = pool
+
+	if err := m.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	got := srv.wait(t)
+	if !got.TLS {
+		t.Error("STARTTLS was not used")
+	}
+	if got.Auth != "\x00mailer\x00secret" || !got.AuthTLS {
+		t.Errorf("Auth = %q (over TLS: %v)", got.Auth, got.AuthTLS)
+	}
+	if got.From != "MAIL FROM:<noreply@example.com>" && !strings.HasPrefix(got.From, "MAIL FROM:<noreply@example.com> ") {
+		t.Errorf("MAIL = %q", got.From)
+	}
+	if got.To != "RCPT TO:<taro@example.com>" {
+		t.Errorf("RCPT = %q", got.To)
+	}
+	checkMessage(t, got.Data)
+}
+
+func TestSMTPMailerUntrustedCertificate(t *testing.T) {
+	cert, _ := testCertificate(t)
+	srv := startFakeSMTP(t, &tls.Config{Certificates: []tls.Certificate{cert}})
+	m := newTestSMTPMailer(t, SMTPConfig{Port: srv.port(), Username: "mailer", Password: "secret"})
+
+	if err := m.Send(context.Background(), testMessage); err == nil {
+		t.Fatal("Send succeeded with an untrusted certificate")
+	}
+	if got := srv.wait(t); got.Auth != "" || got.Data != "" {
+		t.Errorf("credentials or message sent: %+v", got)
+	}
+}
+
+func TestSMTPMailerRequiresTLS(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  SMTPConfig
+	}{
+		{"require_tls", SMTPConfig{RequireTLS: true}},
+		{"auth", SMTPConfig{Username: "mailer", Password: "secret"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := startFakeSMTP(t, nil)
+			tt.cfg.Port = srv.port()
+			err := newTestSMTPMailer(t, tt.cfg).Send(context.Background(), testMessage)
+			if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+				t.Fatalf("Send = %v, want STARTTLS error", err)
+			}
+			got := srv.wait(t)
+			for _, cmd := range got.Commands {
+				if cmd == "AUTH" || cmd == "MAIL" || cmd == "DATA" {
+					t.Errorf("%s sent without TLS", cmd)
+				}
+			}
+		})
+	}
+}
+
+func TestSMTPMailerWithoutTLS(t *testing.T) {
+	srv := startFakeSMTP(t, nil)
+	m := newTestSMTPMailer(t, SMTPConfig{Port: srv.port()})
+	if err := m.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	got := srv.wait(t)
+	if got.TLS || got.Auth != "" {
+		t.Errorf("TLS = %v, Auth = %q", got.TLS, got.Auth)
+	}
+	checkMessage(t, got.Data)
+}
+
+func TestSMTPMailerPermanentError(t *testing.T) {
+	srv := startFakeSMTP(t, nil)
+	srv.rcptCode = 550
+	err := newTestSMTPMailer(t, SMTPConfig{Port: srv.port()}).Send(context.Background(), testMessage)
+	if err == nil || !IsPermanent(err) {
+		t.Fatalf("Send = %v, want permanent error", err)
+	}
+	srv.wait(t)
+}
+
+func TestSMTPMailerInvalidAddress(t *testing.T) {
+	srv := startFakeSMTP(t, nil)
+	m := newTestSMTPMailer(t, SMTPConfig{Port: srv.port()})
+	// ヘッダーインジェクション
+	msg := &Message{To: "taro@example.com\r\nBcc: evil@example.com", Subject: "s", Text: "t"}
+	if err := m.Send(context.Background(), msg); !IsPermanent(err) {
+		t.Fatalf("Send = %v, want ErrInvalidAddress", err)
+	}
+	if _, err := NewSMTPMailer(SMTPConfig{Host: "127.0.0.1", Port: 25}, "not an address"); !IsPermanent(err) {
+		t.Errorf("NewSMTPMailer = %v, want ErrInvalidAddress", err)
+	}
+}
@@ -0,0 +1,78 @@
+// templates.go: メールのテンプレート（templates/<名前>.<言語>.tmpl、ja/en）
+//
+// 各テンプレートは {{define "subject"}}件名{{end}} と {{define "body"}}本文{{end}} を定義します。
+// 指定言語のテンプレートがなければ既定言語（ja）を使用します。
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+)
+
+// テンプレート名
+const (
+	TemplateWelcome        = "welcome"         // 会員登録（メールアドレスの確認後）
+	TemplateVerifyEmail    = "verify_email"    // メールアドレスの確認
+	TemplateNewLogin       = "new_login"       // 新しいIPアドレスからのログイン
+	TemplatePasswordReset  = "password_reset"  // パスワードの再設定
+	TemplateResumeVerified = "resume_verified" // 職務経歴書のverifiedの変更
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Templatesは読み込み済みのテンプレートです。
+type Templates struct {
+	sets map[string]*template.Template // "<名前>.<言語>"
+}
+
+// LoadTemplatesは埋め込みのテンプレートを読み込みます。
+func LoadTemplates() (*Templates, error) {
+	files, err := templateFS.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	t := &Templates{sets: map[string]*template.Template{}}
+	for _, f := range files {
+		key := strings.TrimSuffix(f.Name(), ".tmpl")
+		tmpl, err := template.ParseFS(templateFS, path.Join("templates", f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range []string{"subject", "body"} {
+			if tmpl.Lookup(part) == nil {
+				return nil, fmt.Errorf("mail: template %s has no %q", f.Name(), part)
+			}
+		}
+		t.sets[key] = tmpl
+	}
+	return t, nil
+}
+
+// Renderはテンプレートnameを言語locで描画し、宛先toのメッセージを返します。
+func (t *Templates) Render(name string, loc domain.Locale, to string, data interface{}) (*Message, error) {
+	tmpl, ok := t.sets[name+"."+string(loc)]
+	if !ok {
+		if tmpl, ok = t.sets[name+"."+string(domain.DefaultLocale)]; !ok {
+			return nil, fmt.Errorf("mail: unknown template %q", name)
+		}
+	}
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return nil, err
+	}
+	return &Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimLeft(body.String(), "\n"),
+	}, nil
+}
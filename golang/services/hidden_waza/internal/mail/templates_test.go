@@ -0,0 +1,90 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{
+		"Username":  "taro",
+		"URL":       "https://example.com/link?token=abc",
+		"ExpiresAt": "2025-08-02 09:30",
+		"IP":        "203.0.113.5",
+		"UserAgent": "Mozilla/5.0",
+		"Time":      "2025-08-01 09:30",
+		"Title":     "バックエンドエンジニア",
+		"Verified":  true,
+	}
+	// テンプレートごとに本文に含まれるはずの値
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{TemplateWelcome, []string{"taro"}},
+		{TemplateVerifyEmail, []string{"taro", "https://example.com/link?token=abc", "2025-08-02 09:30"}},
+		{TemplateNewLogin, []string{"taro", "203.0.113.5", "Mozilla/5.0", "2025-08-01 09:30"}},
+		{TemplatePasswordReset, []string{"taro", "https://example.com/link?token=abc"}},
+		{TemplateResumeVerified, []string{"taro", "バックエンドエンジニア"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ja, err := templates.Render(tt.name, domain.LocaleJA, "taro@example.com", data)
+			if err != nil {
+				t.Fatalf("Render(ja): %v", err)
+			}
+			en, err := templates.Render(tt.name, domain.LocaleEN, "taro@example.com", data)
+			if err != nil {
+				t.Fatalf("Render(en): %v", err)
+			}
+			for loc, msg := range map[string]*Message{"ja": ja, "en": en} {
+				if msg.To != "taro@example.com" {
+					t.Errorf("%s: To = %q", loc, msg.To)
+				}
+				if msg.Subject == "" || strings.Contains(msg.Subject, "\n") {
+					t.Errorf("%s: Subject = %q", loc, msg.Subject)
+				}
+				if strings.HasPrefix(msg.Text, "\n") || strings.Contains(msg.Text, "<no value>") {
+					t.Errorf("%s: Text = %q", loc, msg.Text)
+				}
+				for _, w := range tt.want {
+					if !strings.Contains(msg.Text, w) {
+						t.Errorf("%s: Text does not contain %q:\n%s", loc, w, msg.Text)
+					}
+				}
+			}
+			if ja.Subject == en.Subject {
+				t.Errorf("ja and en have the same subject %q", ja.Subject)
+			}
+		})
+	}
+}
+
+func TestRenderTemplateFallback(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{"Username": "taro"}
+	ja, err := templates.Render(TemplateWelcome, domain.LocaleJA, "taro@example.com", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 対応していない言語は既定言語（ja）
+	other, err := templates.Render(TemplateWelcome, domain.Locale("fr"), "taro@example.com", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Subject != ja.Subject || other.Text != ja.Text {
+		t.Errorf("fallback = %+v, want %+v", other, ja)
+	}
+	if _, err := templates.Render("no_such_template", domain.LocaleJA, "taro@example.com", data); err == nil {
+		t.Error("Render of an unknown template succeeded")
+	}
+}
@@ -0,0 +1,462 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	testClientID     = "client-1"
+	testClientSecret = "secret/1"
+	testRedirectURL  = "https://app.example.com/api/v1/oidc/test/callback"
+)
+
+var testNow = time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)
+
+// テスト用のIdP（ディスカバリー・トークンエンドポイント・JWKS）
+type testIdP struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu sync.Mutex
+	// ディスカバリーで返すissuer（空ならサーバーのURL）
+	issuer string
+	// JWKSで公開する鍵
+	keys     map[string]crypto.Signer
+	jwksHits int
+	// 認可リクエストのcode_challenge（トークンリクエストのcode_verifierと照合する）
+	challenge string
+	// トークンエンドポイントが返すIDトークン
+	idToken string
+	// トークンエンドポイントのエラー（空なら成功）
+	tokenError string
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	idp := &testIdP{t: t, keys: map[string]crypto.Signer{}}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp.keys["rsa-1"] = rsaKey
+	idp.keys["ec-1"] = ecKey
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		issuer := idp.issuer
+		idp.mu.Unlock()
+		if issuer == "" {
+			issuer = idp.server.URL
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", idp.jwks)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *testIdP) token(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	fail := func(code string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": code, "error_description": "rejected by test idp"})
+	}
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		fail("invalid_request")
+		return
+	}
+	// client_secret_basic（URLエンコード済み）
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != url.QueryEscape(testClientID) || pass != url.QueryEscape(testClientSecret) {
+		fail("invalid_client")
+		return
+	}
+	switch {
+	case idp.tokenError != "":
+		fail(idp.tokenError)
+	case r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != "code-1" ||
+		r.PostForm.Get("redirect_uri") != testRedirectURL:
+		fail("invalid_grant")
+	case CodeChallenge(r.PostForm.Get("code_verifier")) != idp.challenge:
+		// PKCEの検証
+		fail("invalid_grant")
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-1",
+			"token_type":   "Bearer",
+			"id_token":     idp.idToken,
+			"expires_in":   3600,
+		})
+	}
+}
+
+func (idp *testIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.jwksHits++
+	keys := []map[string]string{
+		// 暗号化用・未対応の鍵は無視される
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		{"kty": "OKP", "kid": "ed-1", "crv": "Ed25519", "x": "AQAB"},
+	}
+	for kid, key := range idp.keys {
+		switch pub := key.Public().(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, map[string]string{
+				"kty": "RSA", "kid": kid, "use": "sig",
+				"n": base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case *ecdsa.PublicKey:
+			keys = append(keys, map[string]string{
+				"kty": "EC", "kid": kid, "crv": "P-256",
+				"x": base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32))),
+				"y": base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32))),
+			})
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+}
+
+// setはIdPの応答を変更する
+func (idp *testIdP) set(f func()) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	f()
+}
+
+func (idp *testIdP) hits() int {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	return idp.jwksHits
+}
+
+// 有効なIDトークンのクレーム
+func (idp *testIdP) claims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"sub":            "user-1",
+		"aud":            testClientID,
+		"exp":            testNow.Add(time.Hour).Unix(),
+		"iat":            testNow.Unix(),
+		"nonce":          nonce,
+		"email":          "taro@example.com",
+		"email_verified": true,
+		"name":           "Taro",
+	}
+}
+
+// kidの鍵でIDトークンに署名する
+func (idp *testIdP) sign(kid string, claims jwt.MapClaims) string {
+	idp.t.Helper()
+	idp.mu.Lock()
+	key := idp.keys[kid]
+	idp.mu.Unlock()
+	method := jwt.SigningMethod(jwt.SigningMethodRS256)
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		method = jwt.SigningMethodES256
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	s, err := token.SignedString(key)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	return s
+}
+
+func (idp *testIdP) provider(clock *time.Time) *Provider {
+	p := NewProvider(Config{
+		Issuer:       idp.server.URL + "/",
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+	}, idp.server.Client())
+	p.now = func() time.Time { return *clock }
+	return p
+}
+
+func TestLogin(t *testing.T) {
+	idp := newTestIdP(t)
+	clock := testNow
+	p := idp.provider(&clock)
+	ctx := context.Background()
+
+	verifier, err := NewCodeVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/authorize" {
+		t.Errorf("authorization endpoint = %s", raw)
+	}
+	q := u.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        CodeChallenge(verifier),
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+
+	for _, kid := range []string{"rsa-1", "ec-1"} {
+		t.Run(kid, func(t *testing.T) {
+			idToken := idp.sign(kid, idp.claims("nonce-1"))
+			idp.set(func() {
+				idp.challenge = q.Get("code_challenge")
+				idp.idToken = idToken
+			})
+			claims, err := p.Exchange(ctx, "code-1", verifier, "nonce-1")
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if claims.Subject != "user-1" || claims.Email != "taro@example.com" || !claims.EmailVerified || claims.Name != "Taro" {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+	// 別のcode_verifier（PKCE）では交換できない
+	other, _ := NewCodeVerifier()
+	if _, err := p.Exchange(ctx, "code-1", other, "nonce-1"); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("Exchange with another code_verifier = %v", err)
+	}
+}
+
+func TestExchangeErrors(t *testing.T) {
+	idp := newTestIdP(t)
+	clock := testNow
+	p := idp.provider(&clock)
+	verifier, _ := NewCodeVerifier()
+	idp.set(func() {
+		idp.challenge = CodeChallenge(verifier)
+		idp.tokenError = "access_denied"
+	})
+	if _, err := p.Exchange(context.Background(), "code-1", verifier, "nonce-1"); err == nil || !strings.Contains(err.Error(), "access_denied") {
+		t.Errorf("Exchange = %v, want access_denied", err)
+	}
+	// id_tokenのないレスポンス
+	idp.set(func() { idp.tokenError = "" })
+	if _, err := p.Exchange(context.Background(), "code-1", verifier, "nonce-1"); err == nil {
+		t.Error("Exchange without id_token succeeded")
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	idp := newTestIdP(t)
+	idp.set(func() { idp.issuer = "https://evil.example.com" })
+	clock := testNow
+	p := idp.provider(&clock)
+	if _, err := p.AuthCodeURL(context.Background(), "s", "n", "v"); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("AuthCodeURL = %v, want issuer mismatch", err)
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	idp := newTestIdP(t)
+	clock := testNow
+	p := idp.provider(&clock)
+
+	with := func(k string, v interface{}) jwt.MapClaims {
+		c := idp.claims("nonce-1")
+		if v == nil {
+			delete(c, k)
+		} else {
+			c[k] = v
+		}
+		return c
+	}
+	hmacToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, idp.claims("nonce-1")).SignedString([]byte("shared secret"))
+	noneToken, _ := jwt.NewWithClaims(jwt.SigningMethodNone, idp.claims("nonce-1")).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	valid := idp.sign("rsa-1", idp.claims("nonce-1"))
+	parts := strings.Split(valid, ".")
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"x"}`)) + "." + parts[2]
+
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+	}{
+		{"wrong nonce", valid, "nonce-2"},
+		{"empty nonce", idp.sign("rsa-1", with("nonce", "")), ""},
+		{"wrong audience", idp.sign("rsa-1", with("aud", "client-2")), "nonce-1"},
+		{"multiple audiences without azp", idp.sign("rsa-1", with("aud", []string{testClientID, "client-2"})), "nonce-1"},
+		{"wrong issuer", idp.sign("rsa-1", with("iss", "https://evil.example.com")), "nonce-1"},
+		{"expired", idp.sign("rsa-1", with("exp", testNow.Add(-2*time.Minute).Unix())), "nonce-1"},
+		{"no exp", idp.sign("rsa-1", with("exp", nil)), "nonce-1"},
+		{"issued in the future", idp.sign("rsa-1", with("iat", testNow.Add(5*time.Minute).Unix())), "nonce-1"},
+		{"no sub", idp.sign("rsa-1", with("sub", nil)), "nonce-1"},
+		{"alg HS256", hmacToken, "nonce-1"},
+		{"alg none", noneToken, "nonce-1"},
+		{"tampered payload", tampered, "nonce-1"},
+		{"not a jwt", "not-a-jwt", "nonce-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.VerifyIDToken(context.Background(), tt.token, tt.nonce); !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("VerifyIDToken = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+
+	// 許容範囲内の時計のずれ・azpのある複数のaudは受け付ける
+	accepted := []jwt.MapClaims{
+		with("exp", testNow.Add(-30*time.Second).Unix()),
+		with("iat", testNow.Add(30*time.Second).Unix()),
+	}
+	multi := with("aud", []string{testClientID, "client-2"})
+	multi["azp"] = testClientID
+	accepted = append(accepted, multi)
+	for i, c := range accepted {
+		if _, err := p.VerifyIDToken(context.Background(), idp.sign("ec-1", c), "nonce-1"); err != nil {
+			t.Errorf("accepted[%d]: %v", i, err)
+		}
+	}
+}
+
+func TestEmailVerifiedClaim(t *testing.T) {
+	idp := newTestIdP(t)
+	clock := testNow
+	p := idp.provider(&clock)
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{true, true},
+		{"true", true},
+		{false, false},
+		{"false", false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		c := idp.claims("nonce-1")
+		if tt.value == nil {
+			delete(c, "email_verified")
+		} else {
+			c["email_verified"] = tt.value
+		}
+		claims, err := p.VerifyIDToken(context.Background(), idp.sign("rsa-1", c), "nonce-1")
+		if err != nil {
+			t.Fatalf("email_verified=%v: %v", tt.value, err)
+		}
+		if claims.EmailVerified != tt.want {
+			t.Errorf("email_verified=%#v: EmailVerified = %v, want %v", tt.value, claims.EmailVerified, tt.want)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	idp := newTestIdP(t)
+	clock := testNow
+	p := idp.provider(&clock)
+	ctx := context.Background()
+
+	if _, err := p.VerifyIDToken(ctx, idp.sign("rsa-1", idp.claims("nonce-1")), "nonce-1"); err != nil {
+		t.Fatal(err)
+	}
+	if idp.hits() != 1 {
+		t.Fatalf("jwks fetched %d times, want 1", idp.hits())
+	}
+
+	// IdPが新しい鍵に切り替える
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp.set(func() { idp.keys["ec-2"] = newKey })
+	rotated := func() string {
+		c := idp.claims("nonce-1")
+		c["exp"] = clock.Add(time.Hour).Unix()
+		return idp.sign("ec-2", c)
+	}
+
+	// 前回の取得から最小間隔が経つまでは再取得しない
+	clock = testNow.Add(30 * time.Second)
+	if _, err := p.VerifyIDToken(ctx, rotated(), "nonce-1"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("VerifyIDToken = %v, want unknown key", err)
+	}
+	if idp.hits() != 1 {
+		t.Errorf("jwks fetched %d times within the refresh interval, want 1", idp.hits())
+	}
+
+	// 最小間隔が経てば未知のkidで再取得する
+	clock = testNow.Add(2 * time.Minute)
+	if _, err := p.VerifyIDToken(ctx, rotated(), "nonce-1"); err != nil {
+		t.Errorf("VerifyIDToken after refresh: %v", err)
+	}
+	if idp.hits() != 2 {
+		t.Errorf("jwks fetched %d times, want 2", idp.hits())
+	}
+	// 既知のkidは再取得しない
+	if _, err := p.VerifyIDToken(ctx, rotated(), "nonce-1"); err != nil {
+		t.Error(err)
+	}
+	if idp.hits() != 2 {
+		t.Errorf("jwks fetched %d times for a known key, want 2", idp.hits())
+	}
+
+	// 再取得しても見つからないkid
+	clock = testNow.Add(5 * time.Minute)
+	c := idp.claims("nonce-1")
+	c["exp"] = clock.Add(time.Hour).Unix()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, c)
+	token.Header["kid"] = "unknown"
+	s, _ := token.SignedString(newKey)
+	if _, err := p.VerifyIDToken(ctx, s, "nonce-1"); !errors.Is(err, ErrInvalidIDToken) || !strings.Contains(err.Error(), "unknown key id") {
+		t.Errorf("VerifyIDToken = %v, want unknown key id", err)
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// RFC 7636 付録B
+	if got := CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"); got != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Errorf("CodeChallenge = %s", got)
+	}
+}
@@ -0,0 +1,504 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// 以下の既知の値はJIS X 0510 / ISO/IEC 18004の表による。
+
+// 誤り訂正レベルMの形式情報（マスク0〜7、マスク処理後の15ビット）
+var formatInfoM = [8]int{0x5412, 0x5125, 0x5E7C, 0x5B4B, 0x45F9, 0x40CE, 0x4F97, 0x4AA0}
+
+// 型番情報（18ビット）
+var versionInfo = map[int]int{
+	7: 0x07C94, 8: 0x085BC, 9: 0x09A99, 10: 0x0A4D3, 20: 0x149A6, 40: 0x28C69,
+}
+
+// 位置合わせパターンの中心座標
+var alignmentPositions = [maxVersion + 1][]int{
+	1: nil, 2: {6, 18}, 3: {6, 22}, 4: {6, 26}, 5: {6, 30}, 6: {6, 34},
+	7: {6, 22, 38}, 8: {6, 24, 42}, 9: {6, 26, 46}, 10: {6, 28, 50},
+	11: {6, 30, 54}, 12: {6, 32, 58}, 13: {6, 34, 62},
+	14: {6, 26, 46, 66}, 15: {6, 26, 48, 70}, 16: {6, 26, 50, 74}, 17: {6, 30, 54, 78},
+	18: {6, 30, 56, 82}, 19: {6, 30, 58, 86}, 20: {6, 34, 62, 90},
+	21: {6, 28, 50, 72, 94}, 22: {6, 26, 50, 74, 98}, 23: {6, 30, 54, 78, 102},
+	24: {6, 28, 54, 80, 106}, 25: {6, 32, 58, 84, 110}, 26: {6, 30, 58, 86, 114},
+	27: {6, 34, 62, 90, 118},
+	28: {6, 26, 50, 74, 98, 122}, 29: {6, 30, 54, 78, 102, 126}, 30: {6, 26, 52, 78, 104, 130},
+	31: {6, 30, 56, 82, 108, 134}, 32: {6, 34, 60, 86, 112, 138}, 33: {6, 30, 58, 86, 114, 142},
+	34: {6, 34, 62, 90, 118, 146},
+	35: {6, 30, 54, 78, 102, 126, 150}, 36: {6, 24, 50, 76, 102, 128, 154},
+	37: {6, 28, 54, 80, 106, 132, 158}, 38: {6, 32, 58, 84, 110, 136, 162},
+	39: {6, 26, 54, 82, 110, 138, 166}, 40: {6, 30, 58, 86, 114, 142, 170},
+}
+
+// バイトモード・誤り訂正レベルMの最大バイト数
+var byteCapacityM = [maxVersion + 1]int{
+	0, 14, 26, 42, 62, 84, 106, 122, 152, 180, 213, 251, 287, 331, 362, 412, 450, 504, 560, 624, 666,
+	711, 779, 857, 911, 997, 1059, 1125, 1190, 1264, 1370, 1452, 1538, 1628, 1722, 1809, 1911, 1989, 2099, 2213, 2331,
+}
+
+// 総コード語数（データ＋誤り訂正）
+var totalCodewords = map[int]int{1: 26, 2: 44, 6: 172, 7: 196, 10: 346, 14: 581, 21: 1156, 40: 3706}
+
+func TestFormatBits(t *testing.T) {
+	for mask, want := range formatInfoM {
+		for _, version := range []int{1, 7} {
+			c := newCode(version)
+			c.drawFormatBits(mask)
+			first, second := readFormatBits(c.Modules)
+			if first != want || second != want {
+				t.Errorf("version %d mask %d: format bits = %#x / %#x, want %#x", version, mask, first, second, want)
+			}
+			if !c.Modules[c.Size-8][8] {
+				t.Errorf("version %d: dark module is light", version)
+			}
+		}
+	}
+}
+
+func TestVersionBits(t *testing.T) {
+	for version, want := range versionInfo {
+		c := newCode(version)
+		c.drawVersion()
+		first, second := readVersionBits(c.Modules)
+		if first != want || second != want {
+			t.Errorf("version %d: version bits = %#x / %#x, want %#x", version, first, second, want)
+		}
+	}
+}
+
+func TestAlignmentPatternPositions(t *testing.T) {
+	for version := minVersion; version <= maxVersion; version++ {
+		got := alignmentPatternPositions(version)
+		if fmt.Sprint(got) != fmt.Sprint(alignmentPositions[version]) {
+			t.Errorf("version %d: %v, want %v", version, got, alignmentPositions[version])
+		}
+	}
+}
+
+func TestNumRawDataModules(t *testing.T) {
+	for version, want := range totalCodewords {
+		if got := numRawDataModules(version) / 8; got != want {
+			t.Errorf("version %d: %d codewords, want %d", version, got, want)
+		}
+		// 機能パターンの配置から数えた値とも一致する
+		if got := countDataModules(version) / 8; got != want {
+			t.Errorf("version %d: %d codewords from the function pattern layout, want %d", version, got, want)
+		}
+	}
+}
+
+// JIS X 0510 附属書I（型番1-M、"01234567"）の誤り訂正コード語
+func TestReedSolomonKnownAnswer(t *testing.T) {
+	data := []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	want := []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55}
+	if got := reedSolomonRemainder(data, reedSolomonDivisor(len(want))); !bytes.Equal(got, want) {
+		t.Errorf("ecc = % X, want % X", got, want)
+	}
+}
+
+func TestEncodeVersionSelection(t *testing.T) {
+	for version := minVersion; version <= maxVersion; version++ {
+		c, err := Encode(bytes.Repeat([]byte{'a'}, byteCapacityM[version]))
+		if err != nil {
+			t.Fatalf("version %d: %v", version, err)
+		}
+		if c.Version != version || c.Size != version*4+17 {
+			t.Errorf("%d bytes: version %d (size %d), want %d", byteCapacityM[version], c.Version, c.Size, version)
+		}
+		if version == maxVersion {
+			break
+		}
+		c, err = Encode(bytes.Repeat([]byte{'a'}, byteCapacityM[version]+1))
+		if err != nil {
+			t.Fatalf("version %d: %v", version+1, err)
+		}
+		if c.Version != version+1 {
+			t.Errorf("%d bytes: version %d, want %d", byteCapacityM[version]+1, c.Version, version+1)
+		}
+	}
+	if _, err := Encode(make([]byte, byteCapacityM[maxVersion]+1)); err != ErrTooLong {
+		t.Errorf("Encode(too long) = %v, want ErrTooLong", err)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		rnd.Read(b)
+		return b
+	}
+	inputs := [][]byte{
+		{},
+		[]byte("a"),
+		[]byte("otpauth://totp/hidden-waza:taro%40example.com?algorithm=SHA1&digits=6&issuer=hidden-waza&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"),
+		[]byte(strings.Repeat("日本語", 20)),
+		random(14),
+		random(15),
+		random(123),
+		random(500),
+		random(1000),
+		random(byteCapacityM[maxVersion]),
+	}
+	for _, data := range inputs {
+		c, err := Encode(data)
+		if err != nil {
+			t.Fatalf("Encode(%d bytes): %v", len(data), err)
+		}
+		got, err := decode(c.Modules)
+		if err != nil {
+			t.Fatalf("decode(%d bytes, version %d): %v", len(data), c.Version, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("version %d: decoded %q, want %q", c.Version, got, data)
+		}
+	}
+}
+
+func TestPNG(t *testing.T) {
+	c, err := Encode([]byte("otpauth://totp/hidden-waza:taro?secret=JBSWY3DPEHPK3PXP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const scale = 3
+	b, err := c.PNG(scale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	width := (c.Size + quietZone*2) * scale
+	if r := img.Bounds(); r.Dx() != width || r.Dy() != width {
+		t.Fatalf("image size = %v, want %dx%d", r, width, width)
+	}
+	dark := func(px, py int) bool {
+		r, _, _, _ := img.At(px, py).RGBA()
+		return r < 0x8000
+	}
+	for y := -quietZone; y < c.Size+quietZone; y++ {
+		for x := -quietZone; x < c.Size+quietZone; x++ {
+			want := x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.Modules[y][x]
+			for _, d := range [][2]int{{0, 0}, {scale - 1, scale - 1}} {
+				if got := dark((x+quietZone)*scale+d[0], (y+quietZone)*scale+d[1]); got != want {
+					t.Fatalf("module (%d, %d): dark = %v, want %v", x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+// 以下はテスト用の復号（誤り訂正は行わず、誤り訂正コード語が正しいことを検査する）
+
+func readFormatBits(m [][]bool) (int, int) {
+	size := len(m)
+	var first, second int
+	set := func(v *int, i int, dark bool) {
+		if dark {
+			*v |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		set(&first, i, m[i][8])
+	}
+	set(&first, 6, m[7][8])
+	set(&first, 7, m[8][8])
+	set(&first, 8, m[8][7])
+	for i := 9; i < 15; i++ {
+		set(&first, i, m[8][14-i])
+	}
+	for i := 0; i < 8; i++ {
+		set(&second, i, m[8][size-1-i])
+	}
+	for i := 8; i < 15; i++ {
+		set(&second, i, m[size-15+i][8])
+	}
+	return first, second
+}
+
+func readVersionBits(m [][]bool) (int, int) {
+	size := len(m)
+	var first, second int
+	for i := 0; i < 18; i++ {
+		// 右上（6行×3列）と左下（3行×6列）
+		if m[i/3][size-11+i%3] {
+			first |= 1 << i
+		}
+		if m[size-11+i%3][i/3] {
+			second |= 1 << i
+		}
+	}
+	return first, second
+}
+
+// 機能パターン・形式情報・型番情報の領域
+func functionModules(version int) [][]bool {
+	size := version*4 + 17
+	f := make([][]bool, size)
+	for i := range f {
+		f[i] = make([]bool, size)
+	}
+	mark := func(x0, y0, w, h int) {
+		for y := y0; y < y0+h; y++ {
+			for x := x0; x < x0+w; x++ {
+				f[y][x] = true
+			}
+		}
+	}
+	// 位置検出パターン・分離パターン・形式情報（左下には暗モジュールを含む）
+	mark(0, 0, 9, 9)
+	mark(size-8, 0, 8, 9)
+	mark(0, size-8, 9, 8)
+	// タイミングパターン
+	mark(6, 0, 1, size)
+	mark(0, 6, size, 1)
+	pos := alignmentPositions[version]
+	for _, y := range pos {
+		for _, x := range pos {
+			if x == 6 && y == 6 || x == 6 && y == size-7 || x == size-7 && y == 6 {
+				continue
+			}
+			mark(x-2, y-2, 5, 5)
+		}
+	}
+	if version >= 7 {
+		mark(size-11, 0, 3, 6)
+		mark(0, size-11, 6, 3)
+	}
+	return f
+}
+
+func countDataModules(version int) int {
+	n := 0
+	for _, row := range functionModules(version) {
+		for _, fn := range row {
+			if !fn {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (y+x)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (y+x)%3 == 0
+	case 4:
+		return (y/2+x/3)%2 == 0
+	case 5:
+		return y*x%2+y*x%3 == 0
+	case 6:
+		return (y*x%2+y*x%3)%2 == 0
+	default:
+		return ((y+x)%2+y*x%3)%2 == 0
+	}
+}
+
+// checkFunctionPatternsは位置検出パターン・タイミングパターンを検査する
+func checkFunctionPatterns(m [][]bool) error {
+	size := len(m)
+	for _, corner := range [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+		for dy := -1; dy <= 7; dy++ {
+			for dx := -1; dx <= 7; dx++ {
+				x, y := corner[0]+dx, corner[1]+dy
+				if x < 0 || y < 0 || x >= size || y >= size {
+					continue
+				}
+				ring := max(abs(dx-3), abs(dy-3))
+				if want := ring != 2 && ring != 4; m[y][x] != want {
+					return fmt.Errorf("finder pattern at (%d, %d) is broken", x, y)
+				}
+			}
+		}
+	}
+	for i := 8; i < size-8; i++ {
+		if m[6][i] != (i%2 == 0) || m[i][6] != (i%2 == 0) {
+			return fmt.Errorf("timing pattern at %d is broken", i)
+		}
+	}
+	return nil
+}
+
+func decode(m [][]bool) ([]byte, error) {
+	size := len(m)
+	if (size-17)%4 != 0 {
+		return nil, fmt.Errorf("invalid size %d", size)
+	}
+	version := (size - 17) / 4
+	if err := checkFunctionPatterns(m); err != nil {
+		return nil, err
+	}
+	first, second := readFormatBits(m)
+	if first != second {
+		return nil, fmt.Errorf("format bits differ: %#x / %#x", first, second)
+	}
+	mask := -1
+	for i, f := range formatInfoM {
+		if f == first {
+			mask = i
+		}
+	}
+	if mask < 0 {
+		return nil, fmt.Errorf("unknown format bits %#x", first)
+	}
+	if version >= 7 {
+		v1, v2 := readVersionBits(m)
+		if want, ok := versionInfo[version]; ok && (v1 != want || v2 != want) {
+			return nil, fmt.Errorf("version bits = %#x / %#x, want %#x", v1, v2, want)
+		}
+		if v1 != v2 || v1>>12 != version {
+			return nil, fmt.Errorf("version bits = %#x / %#x", v1, v2)
+		}
+	}
+
+	// マスクを戻し、右下から2列ずつジグザグに読む
+	fn := functionModules(version)
+	var bits []bool
+	upward := true
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right--
+		}
+		for i := 0; i < size; i++ {
+			y := i
+			if upward {
+				y = size - 1 - i
+			}
+			for _, x := range []int{right, right - 1} {
+				if !fn[y][x] {
+					bits = append(bits, m[y][x] != maskBit(mask, x, y))
+				}
+			}
+		}
+		upward = !upward
+	}
+	codewords := make([]byte, len(bits)/8)
+	for i := range codewords {
+		for j := 0; j < 8; j++ {
+			if bits[i*8+j] {
+				codewords[i] |= 1 << (7 - j)
+			}
+		}
+	}
+
+	// インターリーブを戻し、ブロックごとに誤り訂正コード語を検査する
+	numBlocks := numErrorCorrectionBlocks[version]
+	ecc := eccCodewordsPerBlock[version]
+	shortBlocks := numBlocks - len(codewords)%numBlocks
+	shortData := len(codewords)/numBlocks - ecc
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i <= shortData; i++ {
+		for b := range blocks {
+			if i < shortData || b >= shortBlocks {
+				blocks[b] = append(blocks[b], codewords[k])
+				k++
+			}
+		}
+	}
+	for i := 0; i < ecc; i++ {
+		for b := range blocks {
+			blocks[b] = append(blocks[b], codewords[k])
+			k++
+		}
+	}
+	var data []byte
+	for b, block := range blocks {
+		for i := 0; i < ecc; i++ {
+			if s := syndrome(block, i); s != 0 {
+				return nil, fmt.Errorf("block %d: syndrome %d = %#x", b, i, s)
+			}
+		}
+		data = append(data, block[:len(block)-ecc]...)
+	}
+
+	// モード指示子（バイト）・文字数指示子・データ
+	r := bitReader{data: data}
+	if mode := r.read(4); mode != 0x4 {
+		return nil, fmt.Errorf("mode = %#x", mode)
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	n := r.read(countBits)
+	if r.pos+n*8 > len(data)*8 {
+		return nil, fmt.Errorf("character count %d exceeds capacity", n)
+	}
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = byte(r.read(8))
+	}
+	return out, nil
+}
+
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) read(n int) int {
+	v := 0
+	for i := 0; i < n; i++ {
+		v <<= 1
+		if r.data[r.pos>>3]>>(7-uint(r.pos&7))&1 == 1 {
+			v |= 1
+		}
+		r.pos++
+	}
+	return v
+}
+
+// GF(2^8)（原始多項式x^8+x^4+x^3+x^2+1）の指数表
+var gfExp = func() [255]byte {
+	var t [255]byte
+	x := 1
+	for i := range t {
+		t[i] = byte(x)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+	return t
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	var la, lb int
+	for i, v := range gfExp {
+		if v == a {
+			la = i
+		}
+		if v == b {
+			lb = i
+		}
+	}
+	return gfExp[(la+lb)%255]
+}
+
+// ブロックを多項式としてα^iで評価した値（正しいブロックは0）
+func syndrome(block []byte, i int) byte {
+	var s byte
+	for _, c := range block {
+		s = gfMul(s, gfExp[i]) ^ c
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+// access_token_repository.go: パーソナルアクセストークン用リポジトリ
+
+package repository
+
+import (
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+)
+
+type AccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessTokenRepository(db *gorm.DB) *AccessTokenRepository {
+	return &AccessTokenRepository{db: db}
+}
+
+func (r *AccessTokenRepository) Create(t *domain.PersonalAccessToken) error {
+	return r.db.Create(t).Error
+}
+
+func (r *AccessTokenRepository) FindByHash(tokenHash string) (*domain.PersonalAccessToken, error) {
+	var t domain.PersonalAccessToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ユーザーのトークン（新しい順。失効・期限切れも含む）
+func (r *AccessTokenRepository) ListByUser(userID uint) ([]domain.PersonalAccessToken, error) {
+	var tokens []domain.PersonalAccessToken
+	if err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// ユーザーの使用できる（失効・期限切れでない）トークンの件数
+func (r *AccessTokenRepository) CountActive(userID uint, now time.Time) (int, error) {
+	var n int64
+	err := r.db.Model(&domain.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Count(&n).Error
+	return int(n), err
+}
+
+// ユーザーのトークンを失効させる（該当する未失効のトークンがなければgorm.ErrRecordNotFound）
+func (r *AccessTokenRepository) Revoke(userID, id uint, now time.Time) error {
+	res := r.db.Model(&domain.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", now)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// 最終使用日時・IPアドレスを記録する（前回の記録からinterval以上経った場合のみ。リクエストごとの更新を避ける）
+func (r *AccessTokenRepository) TouchLastUsed(id uint, ip string, now time.Time, interval time.Duration) error {
+	return r.db.Model(&domain.PersonalAccessToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-interval)).
+		Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip}).Error
+}
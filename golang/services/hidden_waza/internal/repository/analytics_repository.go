@@ -0,0 +1,172 @@
+// analytics_repository.go: スキル集計用リポジトリ（skills × resumes の集約クエリ）
+package repository
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+)
+
+type AnalyticsRepository struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsRepository(db *gorm.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// 集計の期間単位ごとのDATE_FORMAT書式
+var trendFormats = map[string]string{
+	"month": "%Y-%m",
+	"year":  "%Y",
+}
+
+// 職務経歴書数（検証済みフラグで絞り込み）
+func (r *AnalyticsRepository) CountResumes(f domain.AnalyticsFilter) (int, error) {
+	var n int64
+	q := r.db.Table("resumes r")
+	if f.Verified != nil {
+		q = q.Where("r.verified = ?", *f.Verified)
+	}
+	if err := q.Count(&n).Error; err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// 保有する職務経歴書数の多い順のスキル
+func (r *AnalyticsRepository) TopSkills(f domain.AnalyticsFilter, limit int) ([]domain.SkillCount, error) {
+	var rows []domain.SkillCount
+	err := r.skills(f).
+		Select("s.type, s.master_id, COUNT(DISTINCT s.resume_id) AS resumes, AVG(s.years) AS avg_years").
+		Group("s.type, s.master_id").
+		Order("resumes DESC, s.type, s.master_id").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// 指定スキル同士の組み合わせごとの共起数（同一スキルの組は保有数）
+func (r *AnalyticsRepository) CoOccurrences(f domain.AnalyticsFilter, refs []domain.SkillCount) ([]CoOccurrenceRow, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(refs))
+	for i, ref := range refs {
+		keys[i] = skillKey(ref.Type, ref.MasterID)
+	}
+	var rows []CoOccurrenceRow
+	q := r.db.Table("skills a").
+		Joins("JOIN skills b ON b.resume_id = a.resume_id").
+		Joins("JOIN resumes r ON r.id = a.resume_id").
+		Where("CONCAT(a.type, ':', a.master_id) IN ?", keys).
+		Where("CONCAT(b.type, ':', b.master_id) IN ?", keys)
+	if f.Verified != nil {
+		q = q.Where("r.verified = ?", *f.Verified)
+	}
+	err := q.Select("a.type AS a_type, a.master_id AS a_master_id, b.type AS b_type, b.master_id AS b_master_id, COUNT(DISTINCT a.resume_id) AS resumes").
+		Group("a.type, a.master_id, b.type, b.master_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// 基準スキル（skillType/masterID）と一緒に登録されているスキルの共起数の多い順（f.SkillTypeで相手の種別を絞り込み）
+func (r *AnalyticsRepository) CoOccurring(skillType string, masterID uint, f domain.AnalyticsFilter, limit int) ([]domain.SkillCount, error) {
+	q := r.db.Table("skills a").
+		Joins("JOIN skills b ON b.resume_id = a.resume_id AND NOT (b.type = a.type AND b.master_id = a.master_id)").
+		Joins("JOIN resumes r ON r.id = a.resume_id").
+		Where("a.type = ? AND a.master_id = ?", skillType, masterID)
+	if f.Verified != nil {
+		q = q.Where("r.verified = ?", *f.Verified)
+	}
+	if f.SkillType != "" {
+		q = q.Where("b.type = ?", f.SkillType)
+	}
+	var rows []domain.SkillCount
+	err := q.Select("b.type, b.master_id, COUNT(DISTINCT b.resume_id) AS resumes, AVG(b.years) AS avg_years").
+		Group("b.type, b.master_id").
+		Order("resumes DESC, b.type, b.master_id").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// レベルごとのスキル登録件数
+func (r *AnalyticsRepository) LevelCounts(f domain.AnalyticsFilter) (map[domain.SkillLevel]int, error) {
+	var rows []struct {
+		Level domain.SkillLevel
+		Count int
+	}
+	if err := r.skills(f).Select("s.level, COUNT(*) AS count").Group("s.level").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[domain.SkillLevel]int, len(rows))
+	for _, row := range rows {
+		counts[row.Level] = row.Count
+	}
+	return counts, nil
+}
+
+// 経験年数ごとのスキル登録件数
+func (r *AnalyticsRepository) YearCounts(f domain.AnalyticsFilter) (map[int]int, error) {
+	var rows []struct {
+		Years int
+		Count int
+	}
+	if err := r.skills(f).Select("s.years, COUNT(*) AS count").Group("s.years").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[int]int, len(rows))
+	for _, row := range rows {
+		counts[row.Years] = row.Count
+	}
+	return counts, nil
+}
+
+// 作成日時の期間（interval: "month"/"year"）ごとの職務経歴書数
+// f.SkillType/MasterIDの指定があれば、そのスキルを持つ職務経歴書数も集計します。
+func (r *AnalyticsRepository) Trend(f domain.AnalyticsFilter, interval string) ([]TrendRow, error) {
+	format, ok := trendFormats[interval]
+	if !ok {
+		format = trendFormats["month"]
+	}
+	selectSQL := "DATE_FORMAT(r.created_at, ?) AS period, COUNT(*) AS resumes"
+	args := []interface{}{format}
+	if f.SkillType != "" {
+		cond := []string{"s.resume_id = r.id", "s.type = ?"}
+		args = append(args, f.SkillType)
+		if f.MasterID != 0 {
+			cond = append(cond, "s.master_id = ?")
+			args = append(args, f.MasterID)
+		}
+		selectSQL += ", SUM(EXISTS (SELECT 1 FROM skills s WHERE " + strings.Join(cond, " AND ") + ")) AS with_skill"
+	}
+	q := r.db.Table("resumes r").Select(selectSQL, args...)
+	if f.Verified != nil {
+		q = q.Where("r.verified = ?", *f.Verified)
+	}
+	var rows []TrendRow
+	err := q.Group("period").Order("period").Scan(&rows).Error
+	return rows, err
+}
+
+// skills × resumes（絞り込み条件適用済み）
+func (r *AnalyticsRepository) skills(f domain.AnalyticsFilter) *gorm.DB {
+	q := r.db.Table("skills s").Joins("JOIN resumes r ON r.id = s.resume_id")
+	if f.Verified != nil {
+		q = q.Where("r.verified = ?", *f.Verified)
+	}
+	if f.SkillType != "" {
+		q = q.Where("s.type = ?", f.SkillType)
+		if f.MasterID != 0 {
+			q = q.Where("s.master_id = ?", f.MasterID)
+		}
+	}
+	return q
+}
+
+func skillKey(skillType string, masterID uint) string {
+	return skillType + ":" + strconv.FormatUint(uint64(masterID), 10)
+}
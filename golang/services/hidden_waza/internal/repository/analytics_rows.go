@@ -0,0 +1,18 @@
+// analytics_rows.go: スキル集計クエリの結果行
+package repository
+
+// CoOccurrenceRowはスキルの組（a, b）ごとの共起数
+type CoOccurrenceRow struct {
+	AType     string
+	AMasterID uint
+	BType     string
+	BMasterID uint
+	Resumes   int
+}
+
+// TrendRowは作成日時の期間ごとの職務経歴書数（WithSkillはスキル指定時のみ）
+type TrendRow struct {
+	Period    string
+	Resumes   int
+	WithSkill int
+}
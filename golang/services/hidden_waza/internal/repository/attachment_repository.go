@@ -0,0 +1,51 @@
+// attachment_repository.go: 添付ファイル（attachments）用リポジトリ
+package repository
+
+import (
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+func (r *AttachmentRepository) Create(a *domain.Attachment) error {
+	return r.db.Create(a).Error
+}
+
+func (r *AttachmentRepository) GetByID(id uint) (*domain.Attachment, error) {
+	var a domain.Attachment
+	if err := r.db.First(&a, id).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// 職務経歴書の添付ファイル一覧（登録順）
+func (r *AttachmentRepository) FindByResumeID(resumeID uint) ([]domain.Attachment, error) {
+	var attachments []domain.Attachment
+	if err := r.db.Where("resume_id = ?", resumeID).Order("id").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *AttachmentRepository) Delete(id uint) error {
+	return r.db.Delete(&domain.Attachment{}, id).Error
+}
+
+// 職歴が指定の職務経歴書に属するか
+func (r *AttachmentRepository) ExperienceBelongsTo(experienceID, resumeID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&domain.Experience{}).
+		Where("id = ? AND resume_id = ?", experienceID, resumeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,104 @@
+// audit_log_repository.go: 監査ログ用リポジトリ（追記・検索のみ。更新・削除のメソッドは持たない）
+
+package repository
+
+import (
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 監査ログの検索条件（ゼロ値の項目は条件にしない）
+type AuditLogFilter struct {
+	Action      string
+	Outcome     string
+	ActorUserID uint
+	TargetType  string
+	TargetID    string
+	RequestID   string
+	From        time.Time
+	To          time.Time
+	BeforeID    uint // このIDより前（ページング用）
+}
+
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// エントリーを追記する。チェーンの末尾を行ロックして連番・前のハッシュを設定し、ハッシュを計算して登録する
+func (r *AuditLogRepository) Append(entry *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var head domain.AuditLogHead
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&head, 1).Error; err != nil {
+			return err
+		}
+		entry.Seq = head.Seq + 1
+		entry.PrevHash = head.Hash
+		entry.Hash = entry.ComputeHash()
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		return tx.Model(&head).Updates(map[string]interface{}{"seq": entry.Seq, "hash": entry.Hash}).Error
+	})
+}
+
+// 条件に合うエントリー（新しい順）
+func (r *AuditLogRepository) List(f AuditLogFilter, limit int) ([]domain.AuditLog, error) {
+	q := r.db.Model(&domain.AuditLog{})
+	if f.Action != "" {
+		q = q.Where("action = ?", f.Action)
+	}
+	if f.Outcome != "" {
+		q = q.Where("outcome = ?", f.Outcome)
+	}
+	if f.ActorUserID != 0 {
+		q = q.Where("actor_user_id = ?", f.ActorUserID)
+	}
+	if f.TargetType != "" {
+		q = q.Where("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != "" {
+		q = q.Where("target_id = ?", f.TargetID)
+	}
+	if f.RequestID != "" {
+		q = q.Where("request_id = ?", f.RequestID)
+	}
+	if !f.From.IsZero() {
+		q = q.Where("created_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		q = q.Where("created_at < ?", f.To)
+	}
+	if f.BeforeID != 0 {
+		q = q.Where("id < ?", f.BeforeID)
+	}
+	var logs []domain.AuditLog
+	if err := q.Order("id DESC").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// 連番がafterSeqより後のエントリー（連番順、検証用）
+func (r *AuditLogRepository) ListAfterSeq(afterSeq int64, limit int) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+	if err := r.db.Where("seq > ?", afterSeq).Order("seq").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// チェーンの末尾
+func (r *AuditLogRepository) Head() (*domain.AuditLogHead, error) {
+	var head domain.AuditLogHead
+	if err := r.db.First(&head, 1).Error; err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
@@ -0,0 +1,51 @@
+// completeness_score_repository.go: 充実度スコア（resume_completeness_scores）用リポジトリ
+package repository
+
+import (
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CompletenessScoreRepository struct {
+	db *gorm.DB
+}
+
+func NewCompletenessScoreRepository(db *gorm.DB) *CompletenessScoreRepository {
+	return &CompletenessScoreRepository{db: db}
+}
+
+// スコアを次のリビジョン番号で登録（score.Revisionに採番結果をセット）
+func (r *CompletenessScoreRepository) Create(score *domain.CompletenessScore) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var last int
+		// 同一職務経歴書の同時登録で番号が重複しないよう行ロック
+		if err := tx.Model(&domain.CompletenessScore{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("resume_id = ?", score.ResumeID).
+			Select("COALESCE(MAX(revision), 0)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		score.Revision = last + 1
+		return tx.Create(score).Error
+	})
+}
+
+// 最新リビジョンのスコア取得
+func (r *CompletenessScoreRepository) Latest(resumeID uint) (*domain.CompletenessScore, error) {
+	var score domain.CompletenessScore
+	if err := r.db.Where("resume_id = ?", resumeID).Order("revision DESC").First(&score).Error; err != nil {
+		return nil, err
+	}
+	return &score, nil
+}
+
+// 全リビジョンのスコア取得（新しい順）
+func (r *CompletenessScoreRepository) FindByResumeID(resumeID uint) ([]domain.CompletenessScore, error) {
+	var scores []domain.CompletenessScore
+	if err := r.db.Where("resume_id = ?", resumeID).Order("revision DESC").Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
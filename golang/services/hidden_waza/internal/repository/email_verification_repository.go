@@ -0,0 +1,72 @@
+// email_verification_repository.go: メールアドレス確認のトークン用リポジトリ
+
+package repository
+
+import (
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) *EmailVerificationRepository {
+	return &EmailVerificationRepository{db: db}
+}
+
+// トークンを登録し、同じユーザーの未使用のトークンを無効にする（最後に送ったリンクのみ有効）
+func (r *EmailVerificationRepository) IssueToken(t *domain.EmailVerificationToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.EmailVerificationToken{}).
+			Where("user_id = ? AND used_at IS NULL", t.UserID).
+			Update("used_at", t.CreatedAt).Error; err != nil {
+			return err
+		}
+		return tx.Create(t).Error
+	})
+}
+
+// ユーザーの最後に発行したトークン（再送間隔の判定用）
+func (r *EmailVerificationRepository) LatestToken(userID uint) (*domain.EmailVerificationToken, error) {
+	var t domain.EmailVerificationToken
+	if err := r.db.Where("user_id = ?", userID).Order("id DESC").First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// 未使用・期限内のトークンを使用済みにし、ユーザーのメールアドレスを確認済みにする。
+// 該当するトークンがなければgorm.ErrRecordNotFoundを返す
+func (r *EmailVerificationRepository) ConsumeToken(userID uint, tokenHash string, now time.Time) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var t domain.EmailVerificationToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND user_id = ? AND used_at IS NULL AND expires_at > ?", tokenHash, userID, now).
+			First(&t).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.EmailVerificationToken{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.User{}).Where("id = ? AND email_status <> ?", userID, domain.EmailVerified).
+			Updates(map[string]interface{}{
+				"email_status":      domain.EmailVerified,
+				"email_verified_at": now,
+				"updated_at":        now,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.First(&user, userID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
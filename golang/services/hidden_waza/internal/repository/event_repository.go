@@ -0,0 +1,41 @@
+// event_repository.go: アウトボックスのイベントの参照（SSEの再送・複数インスタンス間の配信用）
+package repository
+
+import (
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+)
+
+type EventRepository struct {
+	db *gorm.DB
+}
+
+func NewEventRepository(db *gorm.DB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// ユーザーのイベントのうちIDがafterIDより大きいもの（古い順に最大limit件）
+func (r *EventRepository) AfterID(userID, afterID uint, limit int) ([]domain.OutboxEvent, error) {
+	var events []domain.OutboxEvent
+	if err := r.db.Where("user_id = ? AND id > ?", userID, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// since以降に記録された全ユーザーのイベントのうちIDがafterIDより大きいもの（古い順に最大limit件）
+func (r *EventRepository) Since(since time.Time, afterID uint, limit int) ([]domain.OutboxEvent, error) {
+	var events []domain.OutboxEvent
+	if err := r.db.Where("created_at >= ? AND id > ?", since, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,78 @@
+// job_posting_repository.go: 求人（job_postings/job_requirements）用リポジトリ
+package repository
+
+import (
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+)
+
+type JobPostingRepository struct {
+	db *gorm.DB
+}
+
+func NewJobPostingRepository(db *gorm.DB) *JobPostingRepository {
+	return &JobPostingRepository{db: db}
+}
+
+// 求人を要件ごと登録
+func (r *JobPostingRepository) Create(job *domain.JobPosting) error {
+	return r.db.Create(job).Error
+}
+
+// 主キーIDで求人を要件込みで1件取得
+func (r *JobPostingRepository) GetByID(id uint) (*domain.JobPosting, error) {
+	var job domain.JobPosting
+	if err := r.db.Preload("Requirements").First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// 求人一覧取得（statusが空なら全件）
+func (r *JobPostingRepository) FindByStatus(status string) ([]domain.JobPosting, error) {
+	var jobs []domain.JobPosting
+	q := r.db.Preload("Requirements").Order("id")
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if err := q.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// 求人本体を更新し、要件を全置換（掲載者は変更しない）
+func (r *JobPostingRepository) Update(job *domain.JobPosting) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.JobPosting{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"title":       job.Title,
+			"company":     job.Company,
+			"description": job.Description,
+			"status":      job.Status,
+			"updated_at":  tx.NowFunc(),
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("job_posting_id = ?", job.ID).Delete(&domain.JobRequirement{}).Error; err != nil {
+			return err
+		}
+		for i := range job.Requirements {
+			job.Requirements[i].ID = 0
+			job.Requirements[i].JobPostingID = job.ID
+			if err := tx.Create(&job.Requirements[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// 求人を要件ごと削除
+func (r *JobPostingRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("job_posting_id = ?", id).Delete(&domain.JobRequirement{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.JobPosting{}, id).Error
+	})
+}
@@ -0,0 +1,283 @@
+/*
+job_repository.go
+
+バックグラウンドジョブ（jobs）・定期実行スケジュール（job_schedules）用リポジトリ。
+ワーカーは SELECT ... FOR UPDATE SKIP LOCKED で実行待ちのジョブを取得するため、
+複数のワーカー（プロセス）が同時に動いても同じジョブを二重に実行しません。
+*/
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrJobStateConflictはジョブが操作できない状態（例: 実行中のジョブの取り消し）の場合のエラー
+var ErrJobStateConflict = errors.New("job state does not allow this operation")
+
+// JobFilterはジョブ一覧の絞り込み条件です（空は条件なし）。
+type JobFilter struct {
+	Status string
+	Type   string
+	Limit  int
+	Offset int
+}
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// ジョブを登録。UniqueKeyが同じ未完了のジョブがあれば登録せずそのジョブを返す（created=false）
+func (r *JobRepository) Enqueue(job *domain.Job) (created bool, err error) {
+	if job.UniqueKey == nil {
+		return true, r.db.Create(job).Error
+	}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(job)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			created = true
+			return nil
+		}
+		job.ID = 0
+		return tx.Where("unique_key = ?", *job.UniqueKey).First(job).Error
+	})
+	return created, err
+}
+
+// 実行時刻を過ぎた実行待ちのジョブを最大limit件取得して実行中にする（typesのジョブのみ）
+func (r *JobRepository) Claim(workerID string, types []string, now time.Time, limit int) ([]domain.Job, error) {
+	var jobs []domain.Job
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ? AND type IN ?", domain.JobPending, now, types).
+			Order("run_at, id").
+			Limit(limit).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+			jobs[i].Status = domain.JobRunning
+			jobs[i].Attempts++
+			jobs[i].LockedBy = workerID
+			jobs[i].LockedAt = &now
+		}
+		return tx.Model(&domain.Job{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":     domain.JobRunning,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"locked_by":  workerID,
+			"locked_at":  now,
+			"updated_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// 成功として完了
+func (r *JobRepository) Complete(id uint, now time.Time) error {
+	return r.finish(id, domain.JobSucceeded, "", now)
+}
+
+// デッドレター（再試行しない）
+func (r *JobRepository) Bury(id uint, lastError string, now time.Time) error {
+	return r.finish(id, domain.JobDead, lastError, now)
+}
+
+// 失敗したジョブをretryAtに再実行
+func (r *JobRepository) RetryLater(id uint, lastError string, retryAt time.Time) error {
+	return r.db.Model(&domain.Job{}).Where("id = ? AND status = ?", id, domain.JobRunning).Updates(map[string]interface{}{
+		"status":     domain.JobPending,
+		"run_at":     retryAt,
+		"last_error": lastError,
+		"locked_by":  "",
+		"locked_at":  nil,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+func (r *JobRepository) finish(id uint, status, lastError string, now time.Time) error {
+	updates := map[string]interface{}{
+		"status":      status,
+		"unique_key":  nil,
+		"locked_by":   "",
+		"locked_at":   nil,
+		"finished_at": now,
+		"updated_at":  now,
+	}
+	if lastError != "" {
+		updates["last_error"] = lastError
+	}
+	return r.db.Model(&domain.Job{}).Where("id = ? AND status = ?", id, domain.JobRunning).Updates(updates).Error
+}
+
+// lockedBefore以前から実行中のままのジョブ（ワーカーの異常終了等）を実行待ちに戻し、件数を返す
+func (r *JobRepository) ReleaseStale(lockedBefore time.Time) (int64, error) {
+	res := r.db.Model(&domain.Job{}).
+		Where("status = ? AND locked_at < ?", domain.JobRunning, lockedBefore).
+		Updates(map[string]interface{}{
+			"status":     domain.JobPending,
+			"last_error": "worker lock expired",
+			"locked_by":  "",
+			"locked_at":  nil,
+			"updated_at": time.Now(),
+		})
+	return res.RowsAffected, res.Error
+}
+
+// デッドレター・取り消し済みのジョブを試行回数0から再実行
+func (r *JobRepository) Retry(id uint, now time.Time) error {
+	res := r.db.Model(&domain.Job{}).
+		Where("id = ? AND status IN ?", id, []string{domain.JobDead, domain.JobCancelled}).
+		Updates(map[string]interface{}{
+			"status":      domain.JobPending,
+			"attempts":    0,
+			"run_at":      now,
+			"finished_at": nil,
+			"updated_at":  now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrJobStateConflict
+	}
+	return nil
+}
+
+// 実行待ちのジョブを取り消し
+func (r *JobRepository) Cancel(id uint, now time.Time) error {
+	res := r.db.Model(&domain.Job{}).Where("id = ? AND status = ?", id, domain.JobPending).Updates(map[string]interface{}{
+		"status":      domain.JobCancelled,
+		"unique_key":  nil,
+		"finished_at": now,
+		"updated_at":  now,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrJobStateConflict
+	}
+	return nil
+}
+
+func (r *JobRepository) GetByID(id uint) (*domain.Job, error) {
+	var job domain.Job
+	if err := r.db.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// 条件に一致するジョブ（新しい順）
+func (r *JobRepository) Find(f JobFilter) ([]domain.Job, error) {
+	q := r.db.Model(&domain.Job{})
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.Type != "" {
+		q = q.Where("type = ?", f.Type)
+	}
+	var jobs []domain.Job
+	if err := q.Order("id DESC").Limit(f.Limit).Offset(f.Offset).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// 状態ごとのジョブ件数
+func (r *JobRepository) CountByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := r.db.Model(&domain.Job{}).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := map[string]int64{}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// finishedBefore以前に完了・取り消しになったジョブを削除（デッドレターは残す）
+func (r *JobRepository) DeleteFinished(finishedBefore time.Time) (int64, error) {
+	res := r.db.Where("status IN ? AND finished_at < ?", []string{domain.JobSucceeded, domain.JobCancelled}, finishedBefore).
+		Delete(&domain.Job{})
+	return res.RowsAffected, res.Error
+}
+
+// スケジュールを登録（既存ならcron式・ジョブ種別・ペイロードを更新し、cron式が変わった場合は次回実行時刻も更新）
+func (r *JobRepository) UpsertSchedule(s *domain.JobSchedule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing domain.JobSchedule
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", s.Name).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(s).Error
+		}
+		if err != nil {
+			return err
+		}
+		updates := map[string]interface{}{"job_type": s.JobType, "payload": s.Payload}
+		if existing.Cron != s.Cron {
+			updates["cron"] = s.Cron
+			updates["next_run_at"] = s.NextRunAt
+		}
+		return tx.Model(&existing).Updates(updates).Error
+	})
+}
+
+// 次回実行時刻を過ぎたスケジュールをロックして取得し、fnでジョブを登録して次回実行時刻を更新する
+// （複数のワーカーがいても同じ回のジョブは1回だけ登録される）
+func (r *JobRepository) ClaimDueSchedules(now time.Time, fn func(tx *JobRepository, s *domain.JobSchedule) (next time.Time, err error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var schedules []domain.JobSchedule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("next_run_at <= ?", now).
+			Find(&schedules).Error; err != nil {
+			return err
+		}
+		txRepo := &JobRepository{db: tx}
+		for i := range schedules {
+			next, err := fn(txRepo, &schedules[i])
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&schedules[i]).Updates(map[string]interface{}{
+				"next_run_at": next,
+				"last_run_at": now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// 全スケジュール（名前順）
+func (r *JobRepository) Schedules() ([]domain.JobSchedule, error) {
+	var schedules []domain.JobSchedule
+	if err := r.db.Order("name").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
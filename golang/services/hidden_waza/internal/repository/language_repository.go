@@ -22,3 +22,15 @@ func (r *LanguageRepository) FindAll() ([]domain.Language, error) {
 	}
 	return langList, nil
 }
+
+// 指定IDの言語取得
+func (r *LanguageRepository) FindByIDs(ids []uint) ([]domain.Language, error) {
+	var langList []domain.Language
+	if len(ids) == 0 {
+		return langList, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&langList).Error; err != nil {
+		return nil, err
+	}
+	return langList, nil
+}
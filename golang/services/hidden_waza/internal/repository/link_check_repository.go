@@ -0,0 +1,93 @@
+// link_check_repository.go: ポートフォリオURLの確認結果（portfolio_link_checks）用リポジトリ
+package repository
+
+import (
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LinkCheckRepository struct {
+	db *gorm.DB
+}
+
+func NewLinkCheckRepository(db *gorm.DB) *LinkCheckRepository {
+	return &LinkCheckRepository{db: db}
+}
+
+// 職歴のポートフォリオURLと確認対象を同期（新しいURLは即時確認対象として追加、どの職歴からも参照されないURLは削除）
+func (r *LinkCheckRepository) SyncURLs(now time.Time) error {
+	var urls []string
+	if err := r.db.Model(&domain.Experience{}).
+		Where("portfolio_url IS NOT NULL AND portfolio_url <> ''").
+		Distinct().Pluck("portfolio_url", &urls).Error; err != nil {
+		return err
+	}
+	var known []string
+	if err := r.db.Model(&domain.LinkCheck{}).Pluck("url", &known).Error; err != nil {
+		return err
+	}
+	exists := make(map[string]bool, len(known))
+	for _, u := range known {
+		exists[u] = true
+	}
+	var added []domain.LinkCheck
+	for _, u := range urls {
+		if !exists[u] {
+			added = append(added, domain.LinkCheck{URL: u, NextCheckAt: now})
+		}
+	}
+	if len(added) > 0 {
+		// 複数インスタンスで同時に追加しても重複しないよう一意制約違反は無視
+		if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(added, 100).Error; err != nil {
+			return err
+		}
+	}
+	return r.db.Where("url NOT IN (?)",
+		r.db.Model(&domain.Experience{}).Select("portfolio_url").Where("portfolio_url IS NOT NULL"),
+	).Delete(&domain.LinkCheck{}).Error
+}
+
+// 確認時刻を過ぎたURLを古い順にlimit件取得
+func (r *LinkCheckRepository) Due(now time.Time, limit int) ([]domain.LinkCheck, error) {
+	var checks []domain.LinkCheck
+	if err := r.db.Where("next_check_at <= ?", now).Order("next_check_at").Limit(limit).Find(&checks).Error; err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+func (r *LinkCheckRepository) Save(check *domain.LinkCheck) error {
+	return r.db.Save(check).Error
+}
+
+// 指定URLの確認結果
+func (r *LinkCheckRepository) FindByURLs(urls []string) ([]domain.LinkCheck, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	var checks []domain.LinkCheck
+	if err := r.db.Where("url IN ?", urls).Find(&checks).Error; err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+// ユーザーの職務経歴書のうちリンク切れのポートフォリオURLを持つ職歴
+func (r *LinkCheckRepository) BrokenByUserID(userID uint) ([]domain.BrokenLink, error) {
+	var links []domain.BrokenLink
+	err := r.db.Table("experiences e").
+		Select("r.id AS resume_id, r.title AS resume_title, e.id AS experience_id, e.company, "+
+			"c.url, c.status_code, c.final_url, c.error_message AS error, c.checked_at").
+		Joins("JOIN resumes r ON r.id = e.resume_id").
+		Joins("JOIN portfolio_link_checks c ON c.url = e.portfolio_url").
+		Where("r.user_id = ? AND c.broken = ?", userID, true).
+		Order("r.id, e.id").
+		Scan(&links).Error
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
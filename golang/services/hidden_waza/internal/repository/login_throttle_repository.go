@@ -0,0 +1,89 @@
+/*
+login_throttle_repository.go
+
+ログイン試行の制限用リポジトリ。
+  - LoginAttemptRepository: 失敗回数・禁止状態のDB保存（複数インスタンス構成用。service.LoginAttemptStoreを実装）
+  - LoginLockoutRepository: 禁止・解除の記録（監査用）
+*/
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+// 失敗を記録し、since以降の失敗回数を返す（since以前の記録は削除）
+func (r *LoginAttemptRepository) AddFailure(key string, at, since time.Time) (int, error) {
+	var n int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("`key` = ? AND created_at <= ?", key, since).Delete(&domain.LoginFailure{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&domain.LoginFailure{Key: key, CreatedAt: at}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.LoginFailure{}).Where("`key` = ? AND created_at > ?", key, since).Count(&n).Error
+	})
+	return int(n), err
+}
+
+func (r *LoginAttemptRepository) ClearFailures(key string) error {
+	return r.db.Where("`key` = ?", key).Delete(&domain.LoginFailure{}).Error
+}
+
+// 禁止状態（なければnil）
+func (r *LoginAttemptRepository) GetLock(key string) (*domain.LoginLock, error) {
+	var lock domain.LoginLock
+	if err := r.db.Where("`key` = ?", key).First(&lock).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &lock, nil
+}
+
+func (r *LoginAttemptRepository) SaveLock(lock *domain.LoginLock) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(lock).Error
+}
+
+func (r *LoginAttemptRepository) DeleteLock(key string) error {
+	return r.db.Where("`key` = ?", key).Delete(&domain.LoginLock{}).Error
+}
+
+type LoginLockoutRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginLockoutRepository(db *gorm.DB) *LoginLockoutRepository {
+	return &LoginLockoutRepository{db: db}
+}
+
+func (r *LoginLockoutRepository) Create(ev *domain.LoginLockoutEvent) error {
+	return r.db.Create(ev).Error
+}
+
+// 禁止・解除の記録（新しい順。keyを指定した場合はそのキーのみ）
+func (r *LoginLockoutRepository) List(key string, limit, offset int) ([]domain.LoginLockoutEvent, error) {
+	q := r.db.Order("id DESC").Limit(limit).Offset(offset)
+	if key != "" {
+		q = q.Where("`key` = ?", key)
+	}
+	var events []domain.LoginLockoutEvent
+	if err := q.Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
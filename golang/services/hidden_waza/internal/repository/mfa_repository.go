@@ -0,0 +1,151 @@
+// mfa_repository.go: 2段階認証（TOTPの秘密鍵・リカバリーコード・必須とする権限）用リポジトリ
+
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MFARepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// 登録中の秘密鍵を保存する（2段階認証が有効なユーザーはgorm.ErrRecordNotFound）
+func (r *MFARepository) SetPendingSecret(userID uint, secret string) error {
+	res := r.db.Model(&domain.User{}).
+		Where("id = ? AND mfa_enabled = ?", userID, false).
+		Update("mfa_secret", secret)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// 2段階認証を有効にし、リカバリーコードを登録する（以前のリカバリーコードは削除）。
+// stepは登録の確認に使用したコードの時間ステップ
+func (r *MFARepository) Enable(userID uint, step int64, codes []domain.MFARecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.User{}).
+			Where("id = ? AND mfa_enabled = ? AND mfa_secret IS NOT NULL", userID, false).
+			Updates(map[string]interface{}{"mfa_enabled": true, "mfa_last_step": step})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// 2段階認証を無効にし、秘密鍵・リカバリーコードを削除する
+func (r *MFARepository) Disable(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"mfa_enabled":   false,
+			"mfa_secret":    nil,
+			"mfa_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error
+	})
+}
+
+// 使用したコードの時間ステップを記録する。
+// 記録済みの時間ステップ以前なら（同じコードの再利用・同時使用）falseを返す
+func (r *MFARepository) UseStep(userID uint, step int64) (bool, error) {
+	res := r.db.Model(&domain.User{}).
+		Where("id = ? AND mfa_last_step < ?", userID, step).
+		Update("mfa_last_step", step)
+	return res.RowsAffected == 1, res.Error
+}
+
+// リカバリーコードを再発行する（以前のリカバリーコードは削除）
+func (r *MFARepository) ReplaceRecoveryCodes(userID uint, codes []domain.MFARecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codes []domain.MFARecoveryCode) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
+
+// 未使用のリカバリーコードを使用済みにする（該当するコードがなければfalse）
+func (r *MFARepository) UseRecoveryCode(userID uint, codeHash string, now time.Time) (bool, error) {
+	used := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var code domain.MFARecoveryCode
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+			First(&code).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		used = true
+		return tx.Model(&code).Update("used_at", now).Error
+	})
+	return used, err
+}
+
+// 未使用のリカバリーコードの件数
+func (r *MFARepository) CountRecoveryCodes(userID uint) (int, error) {
+	var n int64
+	err := r.db.Model(&domain.MFARecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&n).Error
+	return int(n), err
+}
+
+// 2段階認証を必須とする権限の一覧
+func (r *MFARepository) RequiredRoles() ([]domain.MFARequiredRole, error) {
+	var roles []domain.MFARequiredRole
+	if err := r.db.Order("role").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// 権限に2段階認証が必須か
+func (r *MFARepository) IsRoleRequired(role string) (bool, error) {
+	var n int64
+	err := r.db.Model(&domain.MFARequiredRole{}).Where("role = ?", role).Count(&n).Error
+	return n > 0, err
+}
+
+// 権限に2段階認証を必須とする（設定済みなら何もしない）
+func (r *MFARepository) AddRequiredRole(role *domain.MFARequiredRole) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(role).Error
+}
+
+// 権限の2段階認証を必須としない（設定されていなければgorm.ErrRecordNotFound）
+func (r *MFARepository) RemoveRequiredRole(role string) error {
+	res := r.db.Where("role = ?", role).Delete(&domain.MFARequiredRole{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// oidc_repository.go: 外部IdP（OpenID Connect）の認可リクエスト・アカウントの紐付け用リポジトリ
+
+package repository
+
+import (
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 期限切れの認可リクエストを削除するまでの猶予
+const oidcAuthRequestRetention = 24 * time.Hour
+
+type OIDCRepository struct {
+	db *gorm.DB
+}
+
+func NewOIDCRepository(db *gorm.DB) *OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+// 認可リクエストを登録する（期限切れから一定期間経った認可リクエストは削除）
+func (r *OIDCRepository) CreateAuthRequest(req *domain.OIDCAuthRequest) error {
+	if err := r.db.Where("expires_at < ?", req.CreatedAt.Add(-oidcAuthRequestRetention)).
+		Delete(&domain.OIDCAuthRequest{}).Error; err != nil {
+		return err
+	}
+	return r.db.Create(req).Error
+}
+
+// 未使用・期限内の認可リクエストを使用済みにして返す（該当するものがなければgorm.ErrRecordNotFound）
+func (r *OIDCRepository) ConsumeAuthRequest(provider, stateHash string, now time.Time) (*domain.OIDCAuthRequest, error) {
+	var req domain.OIDCAuthRequest
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("state_hash = ? AND provider = ? AND used_at IS NULL AND expires_at > ?", stateHash, provider, now).
+			First(&req).Error; err != nil {
+			return err
+		}
+		req.UsedAt = &now
+		return tx.Model(&req).Update("used_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *OIDCRepository) FindIdentity(provider, subject string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// ユーザーに紐付けたアカウント（IdP名順）
+func (r *OIDCRepository) ListIdentities(userID uint) ([]domain.UserIdentity, error) {
+	var identities []domain.UserIdentity
+	if err := r.db.Where("user_id = ?", userID).Order("provider, id").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *OIDCRepository) CreateIdentity(identity *domain.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// ユーザーとIdPのアカウントの紐付けを同時に登録する（IdPでの初回ログイン）
+func (r *OIDCRepository) CreateUserWithIdentity(user *domain.User, identity *domain.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+// ログイン時のIdPのメールアドレス・日時を記録する
+func (r *OIDCRepository) TouchIdentity(id uint, email string, now time.Time) error {
+	return r.db.Model(&domain.UserIdentity{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"email":         email,
+		"last_login_at": now,
+	}).Error
+}
+
+// ユーザーの紐付けを解除する（該当するものがなければgorm.ErrRecordNotFound）
+func (r *OIDCRepository) DeleteIdentity(userID, id uint) error {
+	res := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&domain.UserIdentity{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
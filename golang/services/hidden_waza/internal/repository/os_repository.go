@@ -23,3 +23,15 @@ func (r *OSRepository) FindAll() ([]domain.OS, error) {
 	}
 	return osList, nil
 }
+
+// 指定IDのOS取得
+func (r *OSRepository) FindByIDs(ids []uint) ([]domain.OS, error) {
+	var osList []domain.OS
+	if len(ids) == 0 {
+		return osList, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&osList).Error; err != nil {
+		return nil, err
+	}
+	return osList, nil
+}
@@ -0,0 +1,46 @@
+// outbox.go: トランザクショナルアウトボックスへのイベント記録
+// 職務経歴書の変更と同じトランザクション（tx）で呼び出し、変更がコミットされた場合のみイベントが残るようにします。
+// 記録したイベントはeventsに追加し、コミット後にOnEventsのリスナーへ通知します。
+package repository
+
+import (
+	"encoding/json"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+)
+
+func addOutboxEvent(tx *gorm.DB, events *[]domain.OutboxEvent, eventType string, data domain.ResumeEventData) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	ev := domain.OutboxEvent{Type: eventType, ResumeID: data.ResumeID, UserID: data.UserID, Data: b}
+	if err := tx.Create(&ev).Error; err != nil {
+		return err
+	}
+	*events = append(*events, ev)
+	return nil
+}
+
+// 職務経歴書の変更内容に応じたイベント（更新前がnilなら作成）
+func addResumeEvents(tx *gorm.DB, events *[]domain.OutboxEvent, before *domain.Resume, after *domain.Resume) error {
+	data := domain.ResumeEventData{
+		ResumeID: after.ID,
+		UserID:   after.UserID,
+		Title:    after.Title,
+		Verified: after.Verified,
+	}
+	if before == nil {
+		return addOutboxEvent(tx, events, domain.EventResumeCreated, data)
+	}
+	if err := addOutboxEvent(tx, events, domain.EventResumeUpdated, data); err != nil {
+		return err
+	}
+	if before.Verified != after.Verified {
+		prev := before.Verified
+		data.PreviousVerified = &prev
+		return addOutboxEvent(tx, events, domain.EventResumeVerifiedChanged, data)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// password_reset_repository.go: パスワード再設定のトークン用リポジトリ
+
+package repository
+
+import (
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PasswordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// トークンを登録し、同じユーザーの未使用のトークンを無効にする（最後に送ったリンクのみ有効）
+func (r *PasswordResetRepository) IssueToken(t *domain.PasswordResetToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", t.UserID).
+			Update("used_at", t.CreatedAt).Error; err != nil {
+			return err
+		}
+		return tx.Create(t).Error
+	})
+}
+
+// ユーザーの最後に発行したトークン（送信間隔の判定用）
+func (r *PasswordResetRepository) LatestToken(userID uint) (*domain.PasswordResetToken, error) {
+	var t domain.PasswordResetToken
+	if err := r.db.Where("user_id = ?", userID).Order("id DESC").First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// 未使用・期限内のトークンを使用済みにしてパスワードを変更し、JWTの世代を1増やす（発行済みのJWTを無効にする）。
+// 該当するトークンがなければgorm.ErrRecordNotFoundを返す
+func (r *PasswordResetRepository) ResetPassword(tokenHash string, hash domain.PasswordHash, now time.Time) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var t domain.PasswordResetToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+			First(&t).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", t.UserID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.User{}).Where("id = ?", t.UserID).Updates(map[string]interface{}{
+			"password_hash": hash,
+			"token_version": gorm.Expr("token_version + 1"),
+			"updated_at":    now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.First(&user, t.UserID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,65 @@
+// rate_limit_repository.go: APIのレート制限のトークンバケットのDB保存（複数インスタンス構成用。service.RateLimitStoreを実装）
+
+package repository
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// この回数ごとに使われていないバケットを削除する
+	rateLimitSweepInterval = 1000
+	// 最後の使用からこの期間が経ったバケットは満杯のため削除してよい（ポリシーの満杯までの時間はこれ以下）
+	rateLimitBucketRetention = 24 * time.Hour
+)
+
+type RateLimitRepository struct {
+	db    *gorm.DB
+	takes atomic.Int64
+}
+
+func NewRateLimitRepository(db *gorm.DB) *RateLimitRepository {
+	return &RateLimitRepository{db: db}
+}
+
+// バケットからトークンを1つ取り出す（経過時間に応じて補充してから消費する。バケットがなければ満杯で作成）
+func (r *RateLimitRepository) Take(key string, perSecond float64, burst int, now time.Time) (*domain.RateLimitResult, error) {
+	if r.takes.Add(1)%rateLimitSweepInterval == 0 {
+		if err := r.db.Where("updated_at < ?", now.Add(-rateLimitBucketRetention)).Delete(&domain.RateLimitBucket{}).Error; err != nil {
+			return nil, err
+		}
+	}
+	var res *domain.RateLimitResult
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&domain.RateLimitBucket{Key: key, Tokens: float64(burst), UpdatedAt: now}).Error; err != nil {
+			return err
+		}
+		var b domain.RateLimitBucket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("`key` = ?", key).First(&b).Error; err != nil {
+			return err
+		}
+		// インスタンス間の時計のずれで経過時間が負になる場合は補充しない
+		elapsed := math.Max(now.Sub(b.UpdatedAt).Seconds(), 0)
+		tokens := math.Min(float64(burst), b.Tokens+elapsed*perSecond)
+		allowed := tokens >= 1
+		if allowed {
+			tokens--
+		}
+		res = domain.NewRateLimitResult(allowed, tokens, perSecond, burst)
+		return tx.Model(&domain.RateLimitBucket{}).Where("`key` = ?", key).Updates(map[string]interface{}{
+			"tokens":     tokens,
+			"updated_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
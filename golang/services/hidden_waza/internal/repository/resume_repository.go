@@ -13,13 +13,18 @@ resume_repository.go
 package repository
 
 import (
+	"strings"
+	"time"
+
 	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ResumeRepositoryは、ResumeドメインモデルのDB操作を提供する構造体です。
 type ResumeRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	listeners []func(events []domain.OutboxEvent)
 }
 
 // NewResumeRepositoryは、DB接続情報を受け取りリポジトリを生成します。
@@ -27,9 +32,39 @@ func NewResumeRepository(db *gorm.DB) *ResumeRepository {
 	return &ResumeRepository{db: db}
 }
 
-// Createは、ResumeドメインモデルをDBに新規登録します。
+// OnEventsは、作成・更新・削除のコミット後に記録したイベントを受け取るリスナーを登録します（起動時に呼び出す）。
+func (r *ResumeRepository) OnEvents(fn func(events []domain.OutboxEvent)) {
+	r.listeners = append(r.listeners, fn)
+}
+
+// コミット後にリスナーへ通知
+func (r *ResumeRepository) notify(events []domain.OutboxEvent) {
+	if len(events) == 0 {
+		return
+	}
+	for _, fn := range r.listeners {
+		fn(events)
+	}
+}
+
+// Createは、ResumeドメインモデルをDBに新規登録します（作成イベントも同じトランザクションで記録）。
 func (r *ResumeRepository) Create(resume *domain.Resume) error {
-	return r.db.Create(resume).Error
+	// 職歴IDは常に新規採番
+	for i := range resume.Experiences {
+		resume.Experiences[i].ID = 0
+	}
+	var events []domain.OutboxEvent
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(resume).Error; err != nil {
+			return err
+		}
+		return addResumeEvents(tx, &events, nil, resume)
+	})
+	if err != nil {
+		return err
+	}
+	r.notify(events)
+	return nil
 }
 
 // GetAllは、全てのResumeレコードを取得します。
@@ -40,6 +75,34 @@ func (r *ResumeRepository) GetAll() ([]domain.Resume, error) {
 		return nil, err
 	}
 	r.AttachSkills(resumes)
+	r.AttachTranslations(resumes)
+	return resumes, nil
+}
+
+// FindBySkillLevelは、指定レベル以上のスキルを持つResumeを取得します。
+// skillType/masterIDが指定されていればそのスキルに限定します（空/0なら全スキル対象）。
+// レベルはFIELD()で段階の序数に変換して数値比較します。
+func (r *ResumeRepository) FindBySkillLevel(skillType string, masterID uint, minLevel domain.SkillLevel) ([]domain.Resume, error) {
+	args := []interface{}{}
+	for _, l := range domain.SkillLevels {
+		args = append(args, string(l))
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+	sub := r.db.Model(&domain.Skill{}).Select("resume_id").
+		Where("FIELD(level, "+placeholders+") >= ?", append(args, minLevel.Ordinal())...)
+	if skillType != "" {
+		sub = sub.Where("type = ?", skillType)
+	}
+	if masterID != 0 {
+		sub = sub.Where("master_id = ?", masterID)
+	}
+
+	var resumes []domain.Resume
+	if err := r.db.Where("id IN (?)", sub).Find(&resumes).Error; err != nil {
+		return nil, err
+	}
+	r.AttachSkills(resumes)
+	r.AttachTranslations(resumes)
 	return resumes, nil
 }
 
@@ -51,6 +114,7 @@ func (r *ResumeRepository) GetByUserID(userID uint) ([]domain.Resume, error) {
 		return nil, err
 	}
 	r.AttachSkills(resumes)
+	r.AttachTranslations(resumes)
 	return resumes, nil
 }
 
@@ -65,9 +129,67 @@ func (r *ResumeRepository) GetByID(id uint) (*domain.Resume, error) {
 	var skills []domain.Skill
 	r.db.Where("resume_id = ?", resume.ID).Find(&skills)
 	resume.Skills = skills
+	// experiencesを取得してセット
+	var experiences []domain.Experience
+	r.db.Where("resume_id = ?", resume.ID).Order("start_date").Find(&experiences)
+	r.attachExperienceTranslations(experiences)
+	r.attachExperienceSkills(experiences)
+	resume.Experiences = experiences
+	// 翻訳を取得してセット
+	var translations []domain.ResumeTranslation
+	r.db.Where("resume_id = ?", resume.ID).Find(&translations)
+	resume.Translations = translations
 	return &resume, nil
 }
 
+// Versionsは、全Resumeの更新日時をIDごとに取得します（索引の差分更新用）。
+func (r *ResumeRepository) Versions() (map[uint]time.Time, error) {
+	var rows []struct {
+		ID        uint
+		UpdatedAt time.Time
+	}
+	if err := r.db.Model(&domain.Resume{}).Select("id, updated_at").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	versions := make(map[uint]time.Time, len(rows))
+	for _, row := range rows {
+		versions[row.ID] = row.UpdatedAt
+	}
+	return versions, nil
+}
+
+// GetByIDsは、指定IDのResumeをスキル・職歴込みでまとめて取得します（翻訳は含みません）。
+func (r *ResumeRepository) GetByIDs(ids []uint) ([]domain.Resume, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var resumes []domain.Resume
+	if err := r.db.Where("id IN ?", ids).Find(&resumes).Error; err != nil {
+		return nil, err
+	}
+	var skills []domain.Skill
+	if err := r.db.Where("resume_id IN ?", ids).Find(&skills).Error; err != nil {
+		return nil, err
+	}
+	var experiences []domain.Experience
+	if err := r.db.Where("resume_id IN ?", ids).Order("start_date").Find(&experiences).Error; err != nil {
+		return nil, err
+	}
+	for i := range resumes {
+		for _, s := range skills {
+			if s.ResumeID == resumes[i].ID {
+				resumes[i].Skills = append(resumes[i].Skills, s)
+			}
+		}
+		for _, e := range experiences {
+			if e.ResumeID == resumes[i].ID {
+				resumes[i].Experiences = append(resumes[i].Experiences, e)
+			}
+		}
+	}
+	return resumes, nil
+}
+
 // 共通: skills取得処理
 func (r *ResumeRepository) AttachSkills(resumes []domain.Resume) {
 	for i := range resumes {
@@ -77,10 +199,107 @@ func (r *ResumeRepository) AttachSkills(resumes []domain.Resume) {
 	}
 }
 
-// Updateは、指定IDのResumeを更新します（Skills/Experiencesも全置換）
+// 共通: 翻訳取得処理
+func (r *ResumeRepository) AttachTranslations(resumes []domain.Resume) {
+	for i := range resumes {
+		var translations []domain.ResumeTranslation
+		r.db.Where("resume_id = ?", resumes[i].ID).Find(&translations)
+		resumes[i].Translations = translations
+	}
+}
+
+// 職歴ごとの翻訳をまとめて取得してセット
+func (r *ResumeRepository) attachExperienceTranslations(exps []domain.Experience) {
+	if len(exps) == 0 {
+		return
+	}
+	ids := make([]uint, len(exps))
+	for i := range exps {
+		ids[i] = exps[i].ID
+	}
+	var translations []domain.ExperienceTranslation
+	r.db.Where("experience_id IN ?", ids).Find(&translations)
+	for i := range exps {
+		for _, t := range translations {
+			if t.ExperienceID == exps[i].ID {
+				exps[i].Translations = append(exps[i].Translations, t)
+			}
+		}
+	}
+}
+
+// 職歴ごとの使用スキルをまとめて取得してセット
+func (r *ResumeRepository) attachExperienceSkills(exps []domain.Experience) {
+	if len(exps) == 0 {
+		return
+	}
+	ids := make([]uint, len(exps))
+	for i := range exps {
+		ids[i] = exps[i].ID
+	}
+	var skills []domain.ExperienceSkill
+	r.db.Where("experience_id IN ?", ids).Find(&skills)
+	for i := range exps {
+		for _, s := range skills {
+			if s.ExperienceID == exps[i].ID {
+				exps[i].Skills = append(exps[i].Skills, s)
+			}
+		}
+	}
+}
+
+// 指定Resumeに紐づく職歴翻訳・職歴スキルを削除（職歴削除前に呼び出す）
+func deleteExperienceChildren(tx *gorm.DB, resumeID uint) error {
+	sub := tx.Model(&domain.Experience{}).Select("id").Where("resume_id = ?", resumeID)
+	if err := tx.Where("experience_id IN (?)", sub).Delete(&domain.ExperienceTranslation{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("experience_id IN (?)", sub).Delete(&domain.ExperienceSkill{}).Error
+}
+
+// 翻訳の指定がない（nil）既存の職歴に現在の翻訳をセットする（職歴の再登録時に同じ内容で再登録される）
+func keepExperienceTranslations(tx *gorm.DB, exps []domain.Experience) error {
+	var ids []uint
+	for i := range exps {
+		if exps[i].ID != 0 && exps[i].Translations == nil {
+			ids = append(ids, exps[i].ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var translations []domain.ExperienceTranslation
+	if err := tx.Where("experience_id IN ?", ids).Find(&translations).Error; err != nil {
+		return err
+	}
+	for i := range exps {
+		if exps[i].ID == 0 || exps[i].Translations != nil {
+			continue
+		}
+		for _, t := range translations {
+			if t.ExperienceID == exps[i].ID {
+				exps[i].Translations = append(exps[i].Translations, t)
+			}
+		}
+	}
+	return nil
+}
+
+// Updateは、指定IDのResumeを更新します（Skills/Experiencesは全置換）
+// 翻訳はTranslationsがnilなら現在の翻訳を維持し、nilでなければ全置換します（既存の職歴の翻訳も同様）。
+// 更新イベント（verifiedが変わった場合はその変更イベントも）を同じトランザクションで記録します。
 func (r *ResumeRepository) Update(resume *domain.Resume) error {
 	tx := r.db.Begin()
 
+	// 更新前の状態（verifiedの変更検知用）
+	var before domain.Resume
+	res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "verified").Where("id = ?", resume.ID).Limit(1).Find(&before)
+	if res.Error != nil {
+		tx.Rollback()
+		return res.Error
+	}
+	exists := res.RowsAffected > 0
+
 	// 本体更新
 	if err := tx.Model(&domain.Resume{}).Where("id = ?", resume.ID).Updates(map[string]interface{}{
 		"title":      resume.Title,
@@ -106,7 +325,32 @@ func (r *ResumeRepository) Update(resume *domain.Resume) error {
 		}
 	}
 
-	// Experiences全削除→再登録
+	// Experiences全削除→再登録（職歴翻訳・職歴スキルはCreate時に関連として再登録される）
+	// この職務経歴書の既存の職歴IDが指定されていれば同じIDで再登録する（添付ファイルの紐づけを維持）
+	var existingIDs []uint
+	if err := tx.Model(&domain.Experience{}).Where("resume_id = ?", resume.ID).Pluck("id", &existingIDs).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	keep := make(map[uint]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		keep[id] = true
+	}
+	for i := range resume.Experiences {
+		if !keep[resume.Experiences[i].ID] {
+			resume.Experiences[i].ID = 0
+		}
+		// 同じIDの重複指定は2件目以降を新規扱い
+		delete(keep, resume.Experiences[i].ID)
+	}
+	if err := keepExperienceTranslations(tx, resume.Experiences); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := deleteExperienceChildren(tx, resume.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
 	if err := tx.Where("resume_id = ?", resume.ID).Delete(&domain.Experience{}).Error; err != nil {
 		tx.Rollback()
 		return err
@@ -119,27 +363,147 @@ func (r *ResumeRepository) Update(resume *domain.Resume) error {
 		}
 	}
 
-	return tx.Commit().Error
+	// 削除された職歴の添付ファイルは職務経歴書全体への添付にする
+	if err := tx.Model(&domain.Attachment{}).
+		Where("resume_id = ? AND experience_id IS NOT NULL", resume.ID).
+		Where("experience_id NOT IN (?)", tx.Model(&domain.Experience{}).Select("id").Where("resume_id = ?", resume.ID)).
+		Update("experience_id", nil).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 翻訳の指定がなければ現在の翻訳を維持、指定があれば全削除→再登録
+	if resume.Translations == nil {
+		if err := tx.Where("resume_id = ?", resume.ID).Find(&resume.Translations).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	} else {
+		if err := tx.Where("resume_id = ?", resume.ID).Delete(&domain.ResumeTranslation{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		for i := range resume.Translations {
+			resume.Translations[i].ResumeID = resume.ID
+			if err := tx.Create(&resume.Translations[i]).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	var events []domain.OutboxEvent
+	if exists {
+		if err := addResumeEvents(tx, &events, &before, resume); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	r.notify(events)
+	return nil
 }
 
 // Deleteは、指定IDのResumeを削除します（Skills/Experiencesも含めて削除）
+// 削除イベントを同じトランザクションで記録します。
 func (r *ResumeRepository) Delete(id uint) error {
 	tx := r.db.Begin()
 
+	// 削除イベント用に削除前の内容を取得
+	var before domain.Resume
+	res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Limit(1).Find(&before)
+	if res.Error != nil {
+		tx.Rollback()
+		return res.Error
+	}
+
 	// Skills削除
 	if err := tx.Where("resume_id = ?", id).Delete(&domain.Skill{}).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
-	// Experiences削除
+	// Experiences削除（職歴翻訳・職歴スキルを先に削除）
+	if err := deleteExperienceChildren(tx, id); err != nil {
+		tx.Rollback()
+		return err
+	}
 	if err := tx.Where("resume_id = ?", id).Delete(&domain.Experience{}).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
+	// 翻訳削除
+	if err := tx.Where("resume_id = ?", id).Delete(&domain.ResumeTranslation{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	// 充実度スコア履歴削除
+	if err := tx.Where("resume_id = ?", id).Delete(&domain.CompletenessScore{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	// 添付ファイル削除（ストレージ上の実体は呼び出し側で削除）
+	if err := tx.Where("resume_id = ?", id).Delete(&domain.Attachment{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
 	// Resume本体削除
 	if err := tx.Delete(&domain.Resume{}, id).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
-	return tx.Commit().Error
+	var events []domain.OutboxEvent
+	if res.RowsAffected > 0 {
+		if err := addOutboxEvent(tx, &events, domain.EventResumeDeleted, domain.ResumeEventData{
+			ResumeID: before.ID,
+			UserID:   before.UserID,
+			Title:    before.Title,
+			Verified: before.Verified,
+		}); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	r.notify(events)
+	return nil
+}
+
+// SetVerifiedは、指定IDのResumeの検証済みを変更し、変更前の値を返します（該当するResumeがなければgorm.ErrRecordNotFound）。
+// 値が変わった場合はverifiedの変更イベントを同じトランザクションで記録します。
+func (r *ResumeRepository) SetVerified(id uint, verified bool) (previous bool, err error) {
+	var events []domain.OutboxEvent
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		var before domain.Resume
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "user_id", "title", "verified").First(&before, id).Error; err != nil {
+			return err
+		}
+		previous = before.Verified
+		if previous == verified {
+			return nil
+		}
+		if err := tx.Model(&domain.Resume{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"verified":   verified,
+			"updated_at": tx.NowFunc(),
+		}).Error; err != nil {
+			return err
+		}
+		prev := previous
+		return addOutboxEvent(tx, &events, domain.EventResumeVerifiedChanged, domain.ResumeEventData{
+			ResumeID:         before.ID,
+			UserID:           before.UserID,
+			Title:            before.Title,
+			Verified:         verified,
+			PreviousVerified: &prev,
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	r.notify(events)
+	return previous, nil
 }
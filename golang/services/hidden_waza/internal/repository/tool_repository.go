@@ -22,3 +22,15 @@ func (r *ToolRepository) FindAll() ([]domain.Tool, error) {
 	}
 	return toolList, nil
 }
+
+// 指定IDのツール取得
+func (r *ToolRepository) FindByIDs(ids []uint) ([]domain.Tool, error) {
+	var toolList []domain.Tool
+	if len(ids) == 0 {
+		return toolList, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&toolList).Error; err != nil {
+		return nil, err
+	}
+	return toolList, nil
+}
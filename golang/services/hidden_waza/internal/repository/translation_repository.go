@@ -0,0 +1,79 @@
+// translation_repository.go: 翻訳テーブル（resume/experience/master）用リポジトリ
+package repository
+
+import (
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TranslationRepository struct {
+	db *gorm.DB
+}
+
+func NewTranslationRepository(db *gorm.DB) *TranslationRepository {
+	return &TranslationRepository{db: db}
+}
+
+// 1言語分のResume・職歴翻訳をまとめて登録/更新（他言語の翻訳には触れない）
+func (r *TranslationRepository) UpsertResumeLocale(tr *domain.ResumeTranslation, exps []domain.ExperienceTranslation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "resume_id"}, {Name: "locale"}},
+			DoUpdates: clause.AssignmentColumns([]string{"title", "summary"}),
+		}).Create(tr).Error; err != nil {
+			return err
+		}
+		for i := range exps {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "experience_id"}, {Name: "locale"}},
+				DoUpdates: clause.AssignmentColumns([]string{"position", "description"}),
+			}).Create(&exps[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// 1言語分のResume・職歴翻訳を削除
+func (r *TranslationRepository) DeleteResumeLocale(resumeID uint, loc domain.Locale) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		sub := tx.Model(&domain.Experience{}).Select("id").Where("resume_id = ?", resumeID)
+		if err := tx.Where("experience_id IN (?) AND locale = ?", sub, loc).Delete(&domain.ExperienceTranslation{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("resume_id = ? AND locale = ?", resumeID, loc).Delete(&domain.ResumeTranslation{}).Error
+	})
+}
+
+// マスタ名称翻訳の登録/更新
+func (r *TranslationRepository) UpsertMaster(tr *domain.MasterTranslation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "master_type"}, {Name: "master_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+	}).Create(tr).Error
+}
+
+// マスタ名称翻訳の削除
+func (r *TranslationRepository) DeleteMaster(masterType string, masterID uint, loc domain.Locale) error {
+	return r.db.Where("master_type = ? AND master_id = ? AND locale = ?", masterType, masterID, loc).
+		Delete(&domain.MasterTranslation{}).Error
+}
+
+// 指定種別・言語のマスタ名称翻訳をID→名称で取得（idsが空なら種別内全件）
+func (r *TranslationRepository) FindMasterNames(masterType string, ids []uint, loc domain.Locale) (map[uint]string, error) {
+	var list []domain.MasterTranslation
+	q := r.db.Where("master_type = ? AND locale = ?", masterType, loc)
+	if len(ids) > 0 {
+		q = q.Where("master_id IN ?", ids)
+	}
+	if err := q.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(list))
+	for _, t := range list {
+		names[t.MasterID] = t.Name
+	}
+	return names, nil
+}
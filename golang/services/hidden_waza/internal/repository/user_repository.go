@@ -3,8 +3,11 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -22,3 +25,62 @@ func (r *UserRepository) FindByEmail(email domain.Email) (*domain.User, error) {
 	}
 	return &user, nil
 }
+
+func (r *UserRepository) GetByID(id uint) (*domain.User, error) {
+	var user domain.User
+	if err := r.DB.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ユーザーのJWTの世代（パスワードの再設定・権限の変更で増える）と現在の権限
+func (r *UserRepository) AuthState(userID uint) (int, string, error) {
+	var user domain.User
+	if err := r.DB.Select("token_version", "role").First(&user, userID).Error; err != nil {
+		return 0, "", err
+	}
+	return user.TokenVersion, user.Role, nil
+}
+
+// ログインしたIPアドレスを記録する。
+// isNewは初めてのIPアドレス、firstLoginはこれまでにIPアドレスの記録がなかった（初回ログイン）場合にtrue
+func (r *UserRepository) RecordLoginIP(userID uint, ip string, now time.Time) (isNew, firstLogin bool, err error) {
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		var known int64
+		if err := tx.Model(&domain.UserLoginIP{}).Where("user_id = ?", userID).Count(&known).Error; err != nil {
+			return err
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&domain.UserLoginIP{UserID: userID, IP: ip, FirstSeenAt: now, LastSeenAt: now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			isNew, firstLogin = true, known == 0
+			return nil
+		}
+		return tx.Model(&domain.UserLoginIP{}).Where("user_id = ? AND ip = ?", userID, ip).Update("last_seen_at", now).Error
+	})
+	return isNew, firstLogin, err
+}
+
+// ユーザーの権限を変更し、変更前の権限を返す（該当するユーザーがいなければgorm.ErrRecordNotFound）
+// JWTには権限を含めるため、変更前に発行したJWTは世代を増やして無効にする
+func (r *UserRepository) UpdateRole(userID uint, role string) (previous string, err error) {
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "role").First(&user, userID).Error; err != nil {
+			return err
+		}
+		previous = user.Role
+		if previous == role {
+			return nil
+		}
+		return tx.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"role":          role,
+			"token_version": gorm.Expr("token_version + 1"),
+		}).Error
+	})
+	return previous, err
+}
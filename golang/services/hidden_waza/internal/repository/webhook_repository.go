@@ -0,0 +1,219 @@
+/*
+webhook_repository.go
+
+Webhookの購読（webhook_subscriptions）・配信（webhook_deliveries）・配信ログ（webhook_delivery_attempts）
+およびアウトボックス（outbox_events）用リポジトリ。
+未配信のイベントは SELECT ... FOR UPDATE SKIP LOCKED で取り出し、配信と配信ジョブの登録を同じトランザクションで行います。
+*/
+package repository
+
+import (
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) CreateSubscription(s *domain.WebhookSubscription) error {
+	return r.db.Create(s).Error
+}
+
+func (r *WebhookRepository) GetSubscription(id uint) (*domain.WebhookSubscription, error) {
+	var s domain.WebhookSubscription
+	if err := r.db.First(&s, id).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *WebhookRepository) Subscriptions() ([]domain.WebhookSubscription, error) {
+	var subs []domain.WebhookSubscription
+	if err := r.db.Order("id").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// 送信先URL・イベント・説明・有効/無効を更新（署名の鍵は変更しない）
+func (r *WebhookRepository) UpdateSubscription(s *domain.WebhookSubscription) error {
+	return r.db.Model(&domain.WebhookSubscription{}).Where("id = ?", s.ID).Updates(map[string]interface{}{
+		"url":         s.URL,
+		"events":      s.Events,
+		"description": s.Description,
+		"active":      s.Active,
+		"updated_at":  r.db.NowFunc(),
+	}).Error
+}
+
+// 購読を配信・配信ログとともに削除
+func (r *WebhookRepository) DeleteSubscription(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		deliveries := tx.Model(&domain.WebhookDelivery{}).Select("id").Where("subscription_id = ?", id)
+		if err := tx.Where("delivery_id IN (?)", deliveries).Delete(&domain.WebhookDeliveryAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("subscription_id = ?", id).Delete(&domain.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.WebhookSubscription{}, id).Error
+	})
+}
+
+// 未配信のイベントを最大limit件取り出し、購読しているWebhookごとに配信を作成してnewJobの配信ジョブを登録する。
+// eventJobsが返すイベントごとのジョブ（メール通知等）も同じトランザクションで登録する。
+// 処理したイベント件数を返す
+func (r *WebhookRepository) DispatchOutbox(limit int, now time.Time, newJob func(d *domain.WebhookDelivery) (*domain.Job, error), eventJobs func(ev *domain.OutboxEvent) ([]*domain.Job, error)) (int, error) {
+	var events []domain.OutboxEvent
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL").
+			Order("id").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		var subs []domain.WebhookSubscription
+		if err := tx.Where("active = ?", true).Find(&subs).Error; err != nil {
+			return err
+		}
+		jobs := &JobRepository{db: tx}
+		ids := make([]uint, 0, len(events))
+		for _, ev := range events {
+			ids = append(ids, ev.ID)
+			extra, err := eventJobs(&ev)
+			if err != nil {
+				return err
+			}
+			for _, job := range extra {
+				if _, err := jobs.Enqueue(job); err != nil {
+					return err
+				}
+			}
+			for i := range subs {
+				if !subs[i].Matches(ev.Type) {
+					continue
+				}
+				if err := createDelivery(tx, jobs, &domain.WebhookDelivery{
+					SubscriptionID: subs[i].ID,
+					EventID:        ev.ID,
+					EventType:      ev.Type,
+					Status:         domain.DeliveryPending,
+				}, newJob); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Model(&domain.OutboxEvent{}).Where("id IN ?", ids).Update("published_at", now).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// 配信を作成して配信ジョブを登録（同じトランザクション）
+func createDelivery(tx *gorm.DB, jobs *JobRepository, d *domain.WebhookDelivery, newJob func(d *domain.WebhookDelivery) (*domain.Job, error)) error {
+	if err := tx.Create(d).Error; err != nil {
+		return err
+	}
+	job, err := newJob(d)
+	if err != nil {
+		return err
+	}
+	_, err = jobs.Enqueue(job)
+	return err
+}
+
+// 配信を複製して再配信する（元の配信・配信ログはそのまま残す）
+func (r *WebhookRepository) Redeliver(original *domain.WebhookDelivery, newJob func(d *domain.WebhookDelivery) (*domain.Job, error)) (*domain.WebhookDelivery, error) {
+	origID := original.ID
+	d := &domain.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Status:         domain.DeliveryPending,
+		RedeliveryOf:   &origID,
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return createDelivery(tx, &JobRepository{db: tx}, d, newJob)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *WebhookRepository) GetEvent(id uint) (*domain.OutboxEvent, error) {
+	var ev domain.OutboxEvent
+	if err := r.db.First(&ev, id).Error; err != nil {
+		return nil, err
+	}
+	return &ev, nil
+}
+
+// 配信（withAttemptsがtrueなら配信ログも）
+func (r *WebhookRepository) GetDelivery(id uint, withAttempts bool) (*domain.WebhookDelivery, error) {
+	q := r.db
+	if withAttempts {
+		q = q.Preload("AttemptLogs", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+	}
+	var d domain.WebhookDelivery
+	if err := q.First(&d, id).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// 購読の配信（新しい順）
+func (r *WebhookRepository) DeliveriesBySubscription(subscriptionID uint, limit, offset int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	if err := r.db.Where("subscription_id = ?", subscriptionID).
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// 配信の試行結果を記録（statusは配信の新しい状態）
+func (r *WebhookRepository) RecordAttempt(d *domain.WebhookDelivery, a *domain.WebhookDeliveryAttempt, status string, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(a).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"status":           status,
+			"attempts":         gorm.Expr("attempts + 1"),
+			"last_status_code": a.StatusCode,
+			"last_error":       a.Error,
+			"updated_at":       now,
+		}
+		if status == domain.DeliverySucceeded {
+			updates["delivered_at"] = now
+		}
+		return tx.Model(&domain.WebhookDelivery{}).Where("id = ?", d.ID).Updates(updates).Error
+	})
+}
+
+// 配信を失敗にする（購読の削除・無効化等で送信しない場合）
+func (r *WebhookRepository) FailDelivery(id uint, reason string, now time.Time) error {
+	return r.db.Model(&domain.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     domain.DeliveryFailed,
+		"last_error": reason,
+		"updated_at": now,
+	}).Error
+}
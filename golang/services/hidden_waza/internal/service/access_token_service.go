@@ -0,0 +1,192 @@
+/*
+access_token_service.go
+
+パーソナルアクセストークン（スクリプト・ATS等の外部連携用）。
+  - ユーザーが名前・権限（resume:read / resume:write）・有効期限を指定して発行し、トークンは発行時のみ返す
+  - トークンはhwz_<乱数>の形式で、DBにはSHA-256のハッシュと識別用の先頭部分のみ保存する
+  - 認証ミドルウェアはJWTと同じAuthorization: Bearerで受け付け、使用するたびに最終使用日時・IPアドレスを記録する（1分間隔）
+  - ユーザーはいつでも失効させられる
+*/
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+)
+
+const (
+	// 有効期限の既定値・上限
+	DefaultAccessTokenTTL = 30 * 24 * time.Hour
+	MaxAccessTokenTTL     = 365 * 24 * time.Hour
+	// ユーザーごとの使用できるトークンの上限
+	maxActiveAccessTokens = 50
+	// トークン名の最大文字数
+	maxAccessTokenNameLength = 100
+	// 一覧で識別するために保存するトークンの先頭の文字数（hwz_ + 8文字）
+	accessTokenPrefixLength = 12
+	// 最終使用日時を記録する間隔
+	accessTokenTouchInterval = time.Minute
+)
+
+var (
+	ErrInvalidAccessToken  = errors.New("invalid, expired or revoked access token")
+	ErrInvalidTokenName    = errors.New("name is required (up to 100 characters)")
+	ErrInvalidScope        = errors.New("invalid scope")
+	ErrInvalidTokenTTL     = errors.New("expires_in_days must be between 1 and 365")
+	ErrTooManyAccessTokens = errors.New("too many active access tokens")
+)
+
+// AccessTokenStoreはトークンの保存先です（repository.AccessTokenRepository）。
+type AccessTokenStore interface {
+	Create(t *domain.PersonalAccessToken) error
+	// FindByHashはハッシュが一致するトークンを返します（なければgorm.ErrRecordNotFound）。
+	FindByHash(tokenHash string) (*domain.PersonalAccessToken, error)
+	ListByUser(userID uint) ([]domain.PersonalAccessToken, error)
+	CountActive(userID uint, now time.Time) (int, error)
+	// Revokeは未失効のトークンを失効させます（なければgorm.ErrRecordNotFound）。
+	Revoke(userID, id uint, now time.Time) error
+	TouchLastUsed(id uint, ip string, now time.Time, interval time.Duration) error
+}
+
+// AccessTokenUsersはトークンの所有者の参照先です（repository.UserRepository）。
+type AccessTokenUsers interface {
+	// GetByIDはユーザーを返します（なければgorm.ErrRecordNotFound）。
+	GetByID(id uint) (*domain.User, error)
+}
+
+// AccessTokenServiceはパーソナルアクセストークンを提供します。
+type AccessTokenService struct {
+	repo  AccessTokenStore
+	users AccessTokenUsers
+	now   func() time.Time
+}
+
+// NewAccessTokenServiceはAccessTokenServiceを生成します。
+func NewAccessTokenService(repo AccessTokenStore, users AccessTokenUsers) *AccessTokenService {
+	return &AccessTokenService{repo: repo, users: users, now: time.Now}
+}
+
+// Createはトークンを発行し、トークン（この1回のみ返す）と保存した内容を返します。
+// ttlが0なら既定の30日です。
+func (s *AccessTokenService) Create(userID uint, name string, scopes []string, ttl time.Duration) (string, *domain.PersonalAccessToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxAccessTokenNameLength {
+		return "", nil, ErrInvalidTokenName
+	}
+	normalized, err := normalizeScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	if ttl == 0 {
+		ttl = DefaultAccessTokenTTL
+	}
+	if ttl < 0 || ttl > MaxAccessTokenTTL {
+		return "", nil, ErrInvalidTokenTTL
+	}
+	now := s.now()
+	n, err := s.repo.CountActive(userID, now)
+	if err != nil {
+		return "", nil, err
+	}
+	if n >= maxActiveAccessTokens {
+		return "", nil, ErrTooManyAccessTokens
+	}
+
+	token, err := newAccessToken()
+	if err != nil {
+		return "", nil, err
+	}
+	pat := &domain.PersonalAccessToken{
+		UserID:      userID,
+		Name:        name,
+		TokenPrefix: token[:accessTokenPrefixLength],
+		TokenHash:   hashToken(token),
+		Scopes:      strings.Join(normalized, " "),
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   now,
+	}
+	if err := s.repo.Create(pat); err != nil {
+		return "", nil, err
+	}
+	return token, pat, nil
+}
+
+// Listはユーザーのトークン（失効・期限切れも含む、新しい順）を返します。
+func (s *AccessTokenService) List(userID uint) ([]domain.PersonalAccessToken, error) {
+	return s.repo.ListByUser(userID)
+}
+
+// Revokeはユーザーのトークンを失効させます（該当する未失効のトークンがなければErrNotFound）。
+func (s *AccessTokenService) Revoke(userID, id uint) error {
+	return notFound(s.repo.Revoke(userID, id, s.now()))
+}
+
+// Authenticateはトークンを検証し、最終使用日時・IPアドレスを記録して返します。
+// 不正・期限切れ・失効済み、またはユーザーが削除済みならErrInvalidAccessTokenを返します。
+func (s *AccessTokenService) Authenticate(token, ip string) (*domain.PersonalAccessToken, error) {
+	if !strings.HasPrefix(token, domain.AccessTokenPrefix) {
+		return nil, ErrInvalidAccessToken
+	}
+	pat, err := s.repo.FindByHash(hashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAccessToken
+		}
+		return nil, err
+	}
+	now := s.now()
+	if !pat.IsActive(now) {
+		return nil, ErrInvalidAccessToken
+	}
+	if _, err := s.users.GetByID(pat.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAccessToken
+		}
+		return nil, err
+	}
+	// 記録に失敗しても認証は成功させる
+	if err := s.repo.TouchLastUsed(pat.ID, truncateRunes(ip, 45), now, accessTokenTouchInterval); err != nil {
+		log.Printf("access token %d: record last use: %v", pat.ID, err)
+	}
+	return pat, nil
+}
+
+// 権限の重複を除いて並べる（1つ以上必要。不明な権限はErrInvalidScope）
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := map[string]bool{}
+	var out []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !domain.IsValidScope(scope) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			out = append(out, scope)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidScope)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// ランダムなトークン（hwz_ + 32バイトの乱数、base64url）
+func newAccessToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return domain.AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+)
+
+// テスト用のトークンの保存先
+type fakeAccessTokenStore struct {
+	tokens  []*domain.PersonalAccessToken
+	lookups []string
+	touched []string
+}
+
+func (f *fakeAccessTokenStore) Create(t *domain.PersonalAccessToken) error {
+	t.ID = uint(len(f.tokens) + 1)
+	f.tokens = append(f.tokens, t)
+	return nil
+}
+
+func (f *fakeAccessTokenStore) FindByHash(tokenHash string) (*domain.PersonalAccessToken, error) {
+	f.lookups = append(f.lookups, tokenHash)
+	for _, t := range f.tokens {
+		if t.TokenHash == tokenHash {
+			cp := *t
+			return &cp, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeAccessTokenStore) ListByUser(userID uint) ([]domain.PersonalAccessToken, error) {
+	var out []domain.PersonalAccessToken
+	for i := len(f.tokens) - 1; i >= 0; i-- {
+		if f.tokens[i].UserID == userID {
+			out = append(out, *f.tokens[i])
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeAccessTokenStore) CountActive(userID uint, now time.Time) (int, error) {
+	n := 0
+	for _, t := range f.tokens {
+		if t.UserID == userID && t.IsActive(now) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeAccessTokenStore) Revoke(userID, id uint, now time.Time) error {
+	for _, t := range f.tokens {
+		if t.ID == id && t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (f *fakeAccessTokenStore) TouchLastUsed(id uint, ip string, now time.Time, interval time.Duration) error {
+	f.touched = append(f.touched, ip)
+	return nil
+}
+
+// テスト用のユーザー（IDのみ）
+type fakeAccessTokenUsers map[uint]bool
+
+func (f fakeAccessTokenUsers) GetByID(id uint) (*domain.User, error) {
+	if !f[id] {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &domain.User{ID: id}, nil
+}
+
+func newTestAccessTokenService() (*AccessTokenService, *fakeAccessTokenStore, *time.Time) {
+	store := &fakeAccessTokenStore{}
+	svc := NewAccessTokenService(store, fakeAccessTokenUsers{1: true, 2: true})
+	now := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, store, &now
+}
+
+func TestAccessTokenCreate(t *testing.T) {
+	svc, store, now := newTestAccessTokenService()
+	token, pat, err := svc.Create(1, "  CI  ", []string{"resume:write", "resume:read", "resume:write"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, domain.AccessTokenPrefix) || len(token) != len(domain.AccessTokenPrefix)+43 {
+		t.Errorf("token = %q", token)
+	}
+	// トークン自体は保存せず、ハッシュと先頭部分のみ
+	if pat.TokenHash != hashToken(token) || pat.TokenPrefix != token[:12] || strings.Contains(pat.TokenHash, token) {
+		t.Errorf("stored hash %q, prefix %q", pat.TokenHash, pat.TokenPrefix)
+	}
+	if pat.Name != "CI" || pat.Scopes != "resume:read resume:write" || !pat.ExpiresAt.Equal(now.Add(DefaultAccessTokenTTL)) {
+		t.Errorf("pat = %+v", pat)
+	}
+	if len(store.tokens) != 1 {
+		t.Errorf("%d tokens stored", len(store.tokens))
+	}
+
+	other, _, err := svc.Create(1, "CI", []string{"resume:read"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == token {
+		t.Error("same token issued twice")
+	}
+
+	tests := []struct {
+		name   string
+		tname  string
+		scopes []string
+		ttl    time.Duration
+		want   error
+	}{
+		{"empty name", " ", []string{"resume:read"}, 0, ErrInvalidTokenName},
+		{"long name", strings.Repeat("あ", 101), []string{"resume:read"}, 0, ErrInvalidTokenName},
+		{"no scope", "x", nil, 0, ErrInvalidScope},
+		{"unknown scope", "x", []string{"admin"}, 0, ErrInvalidScope},
+		{"negative ttl", "x", []string{"resume:read"}, -time.Hour, ErrInvalidTokenTTL},
+		{"ttl over max", "x", []string{"resume:read"}, MaxAccessTokenTTL + time.Second, ErrInvalidTokenTTL},
+	}
+	for _, tt := range tests {
+		if _, _, err := svc.Create(1, tt.tname, tt.scopes, tt.ttl); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Create = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestAccessTokenAuthenticate(t *testing.T) {
+	svc, store, now := newTestAccessTokenService()
+	token, pat, err := svc.Create(1, "CI", []string{"resume:read"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := svc.Authenticate(token, "203.0.113.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != pat.ID || got.UserID != 1 {
+		t.Errorf("Authenticate = %+v", got)
+	}
+	// ハッシュで照合し、使用したIPアドレスを記録する
+	if len(store.lookups) != 1 || store.lookups[0] != hashToken(token) {
+		t.Errorf("lookups = %v", store.lookups)
+	}
+	if len(store.touched) != 1 || store.touched[0] != "203.0.113.1" {
+		t.Errorf("touched = %v", store.touched)
+	}
+
+	// 期限の直前までは有効、期限ちょうどで無効
+	*now = pat.ExpiresAt.Add(-time.Nanosecond)
+	if _, err := svc.Authenticate(token, "203.0.113.1"); err != nil {
+		t.Errorf("Authenticate before expiry: %v", err)
+	}
+	*now = pat.ExpiresAt
+	if _, err := svc.Authenticate(token, "203.0.113.1"); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("Authenticate expired: %v", err)
+	}
+}
+
+func TestAccessTokenAuthenticateInvalid(t *testing.T) {
+	svc, store, now := newTestAccessTokenService()
+	revoked, pat, err := svc.Create(1, "revoked", []string{"resume:read"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Revoke(1, pat.ID); err != nil {
+		t.Fatal(err)
+	}
+	// 失効済み・他人のトークンは失効できない
+	if err := svc.Revoke(1, pat.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Revoke twice: %v", err)
+	}
+	active, pat2, err := svc.Create(1, "other", []string{"resume:read"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Revoke(2, pat2.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Revoke by another user: %v", err)
+	}
+	orphan, _, err := svc.Create(3, "deleted user", []string{"resume:read"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(time.Minute)
+	lookups := len(store.lookups)
+	modified := []byte(active)
+	if modified[len(modified)-1] == 'A' {
+		modified[len(modified)-1] = 'B'
+	} else {
+		modified[len(modified)-1] = 'A'
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"revoked", revoked},
+		{"unknown", domain.AccessTokenPrefix + strings.Repeat("A", 43)},
+		{"modified", string(modified)},
+		{"deleted user", orphan},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		if _, err := svc.Authenticate(tt.token, "203.0.113.1"); !errors.Is(err, ErrInvalidAccessToken) {
+			t.Errorf("%s: Authenticate = %v, want ErrInvalidAccessToken", tt.name, err)
+		}
+	}
+	// hwz_で始まらない値（JWT等）はDBを参照せずに拒否する
+	lookups += len(tests) - 1
+	for _, token := range []string{strings.TrimPrefix(active, domain.AccessTokenPrefix), "eyJhbGciOiJIUzI1NiJ9.e30.sig"} {
+		if _, err := svc.Authenticate(token, "203.0.113.1"); !errors.Is(err, ErrInvalidAccessToken) {
+			t.Errorf("Authenticate(%q) = %v", token, err)
+		}
+	}
+	if len(store.lookups) != lookups {
+		t.Errorf("%d lookups, want %d", len(store.lookups), lookups)
+	}
+	if len(store.touched) != 0 {
+		t.Errorf("touched = %v", store.touched)
+	}
+}
+
+func TestAccessTokenLimit(t *testing.T) {
+	svc, _, now := newTestAccessTokenService()
+	for i := 0; i < maxActiveAccessTokens; i++ {
+		if _, _, err := svc.Create(1, "t", []string{"resume:read"}, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := svc.Create(1, "t", []string{"resume:read"}, time.Hour); !errors.Is(err, ErrTooManyAccessTokens) {
+		t.Errorf("Create over limit: %v", err)
+	}
+	if _, _, err := svc.Create(2, "t", []string{"resume:read"}, time.Hour); err != nil {
+		t.Errorf("Create for another user: %v", err)
+	}
+	// 期限切れのトークンは数えない
+	*now = now.Add(time.Hour)
+	if _, _, err := svc.Create(1, "t", []string{"resume:read"}, time.Hour); err != nil {
+		t.Errorf("Create after expiry: %v", err)
+	}
+}
+
+// resume:writeはresume:readを含むが、resume:readはresume:writeを含まない
+func TestAccessTokenHasScope(t *testing.T) {
+	tests := []struct {
+		scopes string
+		scope  string
+		want   bool
+	}{
+		{"resume:read", domain.ScopeResumeRead, true},
+		{"resume:read", domain.ScopeResumeWrite, false},
+		{"resume:write", domain.ScopeResumeWrite, true},
+		{"resume:write", domain.ScopeResumeRead, true},
+		{"resume:read resume:write", domain.ScopeResumeWrite, true},
+		{"", domain.ScopeResumeRead, false},
+		{"resume:write", "admin", false},
+	}
+	for _, tt := range tests {
+		pat := &domain.PersonalAccessToken{Scopes: tt.scopes}
+		if got := pat.HasScope(tt.scope); got != tt.want {
+			t.Errorf("HasScope(%q) with %q = %v, want %v", tt.scope, tt.scopes, got, tt.want)
+		}
+	}
+}
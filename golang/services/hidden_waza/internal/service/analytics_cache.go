@@ -0,0 +1,50 @@
+// analytics_cache.go: 集計結果のインメモリキャッシュ（有効期限付き）
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// 集計結果をキーごとにttlの間保持する（並行利用可）
+type analyticsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]cacheEntry
+}
+
+func newAnalyticsCache(ttl time.Duration) *analyticsCache {
+	return &analyticsCache{ttl: ttl, now: time.Now, entries: map[string]cacheEntry{}}
+}
+
+// キャッシュが有効ならその値を、なければloadの結果を保存して返す（loadが失敗した場合は保存しない）
+func (c *analyticsCache) get(key string, load func() (interface{}, error)) (interface{}, error) {
+	now := c.now()
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && now.Before(e.expires) {
+		c.mu.Unlock()
+		return e.value, nil
+	}
+	c.mu.Unlock()
+
+	v, err := load()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{value: v, expires: now.Add(c.ttl)}
+	// 期限切れのエントリを掃除
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,288 @@
+/*
+analytics_service.go
+
+skills・resumes・マスタを集計するスキル分析サービス。
+- 利用数ランキング: スキルを持つ職務経歴書数の多い順（平均経験年数・母数に対する割合付き）
+- 共起行列: 上位スキル同士を両方持つ職務経歴書数
+- 共起スキル: 特定スキル（例: Go）と一緒に登録されているスキル
+- 分布: レベル・経験年数ごとのスキル登録件数
+- 推移: 職務経歴書の作成日時（created_at）の月/年ごとの件数とスキル保有率
+
+全ての集計は検証済みフラグ（verified）で絞り込め、結果は条件ごとに一定時間キャッシュします。
+*/
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+)
+
+const (
+	// AnalyticsCacheTTLは集計結果のキャッシュ有効期間
+	AnalyticsCacheTTL = 5 * time.Minute
+	// DefaultAnalyticsLimitはランキング・共起の既定件数
+	DefaultAnalyticsLimit = 10
+)
+
+// ErrInvalidAnalyticsFilterは集計の絞り込み条件が不正な場合のエラー
+var ErrInvalidAnalyticsFilter = errors.New("invalid analytics filter")
+
+// 経験年数の分布の区間（下限、上限。上限-1は上限なし）
+var yearBuckets = []struct {
+	label    string
+	min, max int
+}{
+	{"0", 0, 0},
+	{"1", 1, 1},
+	{"2", 2, 2},
+	{"3-4", 3, 4},
+	{"5-9", 5, 9},
+	{"10+", 10, -1},
+}
+
+// AnalyticsServiceはスキル集計を提供します。
+type AnalyticsService struct {
+	repo  *repository.AnalyticsRepository
+	names *MasterNameResolver
+	cache *analyticsCache
+}
+
+// NewAnalyticsServiceはAnalyticsServiceを生成します。
+func NewAnalyticsService(repo *repository.AnalyticsRepository, names *MasterNameResolver) *AnalyticsService {
+	return &AnalyticsService{repo: repo, names: names, cache: newAnalyticsCache(AnalyticsCacheTTL)}
+}
+
+// TopSkillsは保有する職務経歴書数の多い順にスキルをlimit件返します。
+func (s *AnalyticsService) TopSkills(f domain.AnalyticsFilter, limit int, loc domain.Locale) ([]domain.SkillCount, error) {
+	if !f.IsValid() {
+		return nil, ErrInvalidAnalyticsFilter
+	}
+	key := fmt.Sprintf("top?%s&limit=%d&lang=%s", f.Key(), limit, loc)
+	v, err := s.cache.get(key, func() (interface{}, error) {
+		total, err := s.repo.CountResumes(f)
+		if err != nil {
+			return nil, err
+		}
+		skills, err := s.repo.TopSkills(f, limit)
+		if err != nil {
+			return nil, err
+		}
+		if skills == nil {
+			skills = []domain.SkillCount{}
+		}
+		if err := s.fillSkillCounts(skills, total, loc); err != nil {
+			return nil, err
+		}
+		return skills, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]domain.SkillCount), nil
+}
+
+// CoOccurrenceMatrixは上位limit件のスキル同士の共起行列を返します。
+func (s *AnalyticsService) CoOccurrenceMatrix(f domain.AnalyticsFilter, limit int, loc domain.Locale) (*domain.CoOccurrenceMatrix, error) {
+	if !f.IsValid() {
+		return nil, ErrInvalidAnalyticsFilter
+	}
+	key := fmt.Sprintf("matrix?%s&limit=%d&lang=%s", f.Key(), limit, loc)
+	v, err := s.cache.get(key, func() (interface{}, error) {
+		top, err := s.TopSkills(f, limit, loc)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := s.repo.CoOccurrences(f, top)
+		if err != nil {
+			return nil, err
+		}
+		index := make(map[skillRef]int, len(top))
+		for i, sk := range top {
+			index[skillRef{sk.Type, sk.MasterID}] = i
+		}
+		m := &domain.CoOccurrenceMatrix{Skills: top, Counts: make([][]int, len(top))}
+		for i := range m.Counts {
+			m.Counts[i] = make([]int, len(top))
+		}
+		for _, row := range rows {
+			i, ok1 := index[skillRef{row.AType, row.AMasterID}]
+			j, ok2 := index[skillRef{row.BType, row.BMasterID}]
+			if ok1 && ok2 {
+				m.Counts[i][j] = row.Resumes
+			}
+		}
+		return m, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*domain.CoOccurrenceMatrix), nil
+}
+
+// CoOccurringは指定スキルと一緒に登録されているスキルを共起数の多い順にlimit件返します
+// （f.SkillTypeで相手のスキル種別を絞り込み）。
+func (s *AnalyticsService) CoOccurring(skillType string, masterID uint, f domain.AnalyticsFilter, limit int, loc domain.Locale) (*domain.CoOccurringSkills, error) {
+	base := domain.AnalyticsFilter{Verified: f.Verified, SkillType: skillType, MasterID: masterID}
+	if !f.IsValid() || f.MasterID != 0 || skillType == "" || masterID == 0 || !base.IsValid() {
+		return nil, ErrInvalidAnalyticsFilter
+	}
+	key := fmt.Sprintf("co-occurring?%s&with=%s&limit=%d&lang=%s", base.Key(), f.SkillType, limit, loc)
+	v, err := s.cache.get(key, func() (interface{}, error) {
+		names, err := s.names.Resolve(map[string][]uint{skillType: {masterID}}, loc)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := names[skillType][masterID]; !ok {
+			return nil, ErrNotFound
+		}
+		total, err := s.repo.CountResumes(f)
+		if err != nil {
+			return nil, err
+		}
+		baseCounts, err := s.repo.TopSkills(base, 1)
+		if err != nil {
+			return nil, err
+		}
+		result := &domain.CoOccurringSkills{
+			Skill:  domain.SkillCount{Type: skillType, MasterID: masterID},
+			Skills: []domain.SkillCount{},
+		}
+		if len(baseCounts) > 0 {
+			result.Skill = baseCounts[0]
+		}
+		single := []domain.SkillCount{result.Skill}
+		if err := s.fillSkillCounts(single, total, loc); err != nil {
+			return nil, err
+		}
+		result.Skill = single[0]
+
+		if result.Skill.Resumes > 0 {
+			skills, err := s.repo.CoOccurring(skillType, masterID, f, limit)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.fillSkillCounts(skills, result.Skill.Resumes, loc); err != nil {
+				return nil, err
+			}
+			if skills != nil {
+				result.Skills = skills
+			}
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*domain.CoOccurringSkills), nil
+}
+
+// Histogramはレベル・経験年数ごとのスキル登録件数を返します（f.SkillType/MasterIDで対象スキルを絞り込み）。
+func (s *AnalyticsService) Histogram(f domain.AnalyticsFilter) (*domain.SkillHistogram, error) {
+	if !f.IsValid() {
+		return nil, ErrInvalidAnalyticsFilter
+	}
+	v, err := s.cache.get("histogram?"+f.Key(), func() (interface{}, error) {
+		levels, err := s.repo.LevelCounts(f)
+		if err != nil {
+			return nil, err
+		}
+		years, err := s.repo.YearCounts(f)
+		if err != nil {
+			return nil, err
+		}
+
+		h := &domain.SkillHistogram{}
+		for _, l := range domain.SkillLevels {
+			h.Levels = append(h.Levels, domain.HistogramBucket{Label: string(l), Count: levels[l]})
+			h.Total += levels[l]
+		}
+		// 正規化前の値が残っている場合
+		other := 0
+		for l, n := range levels {
+			if !l.IsValid() {
+				other += n
+			}
+		}
+		if other > 0 {
+			h.Levels = append(h.Levels, domain.HistogramBucket{Label: "other", Count: other})
+			h.Total += other
+		}
+
+		for _, b := range yearBuckets {
+			bucket := domain.HistogramBucket{Label: b.label}
+			for y, n := range years {
+				if y >= b.min && (b.max < 0 || y <= b.max) {
+					bucket.Count += n
+				}
+			}
+			h.Years = append(h.Years, bucket)
+		}
+		return h, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*domain.SkillHistogram), nil
+}
+
+// Trendは職務経歴書の作成日時の期間（"month"/"year"）ごとの件数を返します
+// （f.SkillType/MasterID指定時はそのスキルの保有数・保有率も含む）。
+func (s *AnalyticsService) Trend(f domain.AnalyticsFilter, interval string) ([]domain.TrendPoint, error) {
+	if !f.IsValid() {
+		return nil, ErrInvalidAnalyticsFilter
+	}
+	if interval != "month" && interval != "year" {
+		return nil, ErrInvalidAnalyticsFilter
+	}
+	v, err := s.cache.get("trend?"+f.Key()+"&interval="+interval, func() (interface{}, error) {
+		rows, err := s.repo.Trend(f, interval)
+		if err != nil {
+			return nil, err
+		}
+		points := make([]domain.TrendPoint, 0, len(rows))
+		for _, row := range rows {
+			p := domain.TrendPoint{Period: row.Period, Resumes: row.Resumes}
+			if f.SkillType != "" {
+				with := row.WithSkill
+				share := percent(with, row.Resumes)
+				p.WithSkill = &with
+				p.Share = &share
+			}
+			points = append(points, p)
+		}
+		return points, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]domain.TrendPoint), nil
+}
+
+// スキル名・割合・平均年数の丸めを設定
+func (s *AnalyticsService) fillSkillCounts(skills []domain.SkillCount, total int, loc domain.Locale) error {
+	ids := map[string][]uint{}
+	for _, sk := range skills {
+		ids[sk.Type] = append(ids[sk.Type], sk.MasterID)
+	}
+	names, err := s.names.Resolve(ids, loc)
+	if err != nil {
+		return err
+	}
+	for i := range skills {
+		skills[i].Name = names.Name(skills[i].Type, skills[i].MasterID)
+		skills[i].AvgYears = round1(skills[i].AvgYears)
+		skills[i].Share = percent(skills[i].Resumes, total)
+	}
+	return nil
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return round1(100 * float64(n) / float64(total))
+}
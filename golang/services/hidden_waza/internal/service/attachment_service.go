@@ -0,0 +1,363 @@
+/*
+attachment_service.go
+
+職務経歴書・職歴への添付ファイル（PDF・画像・zip等）のアップロード・ダウンロード・削除。
+  - 実体は[`storage.Storage`](services/hidden_waza/internal/storage/storage.go)（ローカル / S3互換）に保存し、DBにはメタデータのみ保存
+  - MIMEタイプは拡張子やリクエストヘッダではなくファイル先頭512バイトの内容から判定し、許可リストで制限
+  - アップロード時にSHA-256のチェックサムを算出（ダウンロード時のETagにも使用）
+  - ダウンロードURLは期限付き署名URL。S3互換ストレージは直接ダウンロードできる署名URL（presigned URL）、
+    ローカル保存はAPI経由のURL（?expires=&signature=、HMAC-SHA256）を返す
+*/
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/requohylla/hidden-waza/pkg/config"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/storage"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultAttachmentMaxSizeは添付ファイルの既定の上限サイズ（10MB）
+	DefaultAttachmentMaxSize int64 = 10 << 20
+	// DefaultAttachmentURLTTLはダウンロードURLの既定の有効期限
+	DefaultAttachmentURLTTL = 15 * time.Minute
+)
+
+// DefaultAttachmentTypesは既定で許可するMIMEタイプ
+var DefaultAttachmentTypes = []string{
+	"application/pdf",
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"application/zip",
+}
+
+var (
+	ErrAttachmentEmpty          = errors.New("attachment is empty")
+	ErrAttachmentTooLarge       = errors.New("attachment is too large")
+	ErrAttachmentTypeNotAllowed = errors.New("attachment type is not allowed")
+	ErrExperienceNotInResume    = errors.New("experience does not belong to the resume")
+	ErrInvalidDownloadSignature = errors.New("invalid or expired download signature")
+)
+
+// MIMEタイプ判定に使う先頭バイト数（http.DetectContentTypeの仕様）
+const sniffLen = 512
+
+// AttachmentOptionsは添付ファイルの制限・署名の設定です。
+type AttachmentOptions struct {
+	MaxSize      int64
+	AllowedTypes []string
+	URLTTL       time.Duration
+	// ローカル保存時のダウンロードURL署名鍵
+	SigningKey []byte
+}
+
+// AttachmentOptionsFromConfigは設定ファイルの値から添付ファイルの設定を返します（未指定の項目は既定値）。
+func AttachmentOptionsFromConfig(cfg *config.StorageConfig) AttachmentOptions {
+	opts := AttachmentOptions{
+		MaxSize:      DefaultAttachmentMaxSize,
+		AllowedTypes: DefaultAttachmentTypes,
+		URLTTL:       DefaultAttachmentURLTTL,
+	}
+	if cfg == nil {
+		return opts
+	}
+	if cfg.Storage.MaxSizeMB > 0 {
+		opts.MaxSize = int64(cfg.Storage.MaxSizeMB) << 20
+	}
+	if len(cfg.Storage.AllowedTypes) > 0 {
+		opts.AllowedTypes = cfg.Storage.AllowedTypes
+	}
+	if cfg.Storage.URLTTLMinutes > 0 {
+		opts.URLTTL = time.Duration(cfg.Storage.URLTTLMinutes) * time.Minute
+	}
+	opts.SigningKey = []byte(cfg.Storage.SigningKey)
+	return opts
+}
+
+// AttachmentStoreは添付ファイルのメタデータの保存先です（repository.AttachmentRepository）。
+type AttachmentStore interface {
+	Create(a *domain.Attachment) error
+	// GetByIDは添付ファイルを返します（なければgorm.ErrRecordNotFound）。
+	GetByID(id uint) (*domain.Attachment, error)
+	FindByResumeID(resumeID uint) ([]domain.Attachment, error)
+	Delete(id uint) error
+	ExperienceBelongsTo(experienceID, resumeID uint) (bool, error)
+}
+
+// AttachmentServiceは添付ファイルの管理を提供します。
+type AttachmentService struct {
+	repo       AttachmentStore
+	resumeRepo *repository.ResumeRepository
+	store      storage.Storage
+	opts       AttachmentOptions
+	now        func() time.Time
+}
+
+// NewAttachmentServiceはAttachmentServiceを生成します。
+func NewAttachmentService(repo AttachmentStore, resumeRepo *repository.ResumeRepository, store storage.Storage, opts AttachmentOptions) *AttachmentService {
+	return &AttachmentService{repo: repo, resumeRepo: resumeRepo, store: store, opts: opts, now: time.Now}
+}
+
+// MaxSizeは添付ファイルの上限サイズ（バイト）を返します。
+func (s *AttachmentService) MaxSize() int64 {
+	return s.opts.MaxSize
+}
+
+// Uploadはrの内容（sizeバイト）を職務経歴書（experienceID指定時はその職歴）の添付ファイルとして保存します。
+func (s *AttachmentService) Upload(ctx context.Context, resumeID uint, experienceID *uint, fileName string, size int64, r io.Reader) (*domain.Attachment, error) {
+	if err := s.checkResume(resumeID); err != nil {
+		return nil, err
+	}
+	if experienceID != nil {
+		ok, err := s.repo.ExperienceBelongsTo(*experienceID, resumeID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrExperienceNotInResume
+		}
+	}
+	if size <= 0 {
+		return nil, ErrAttachmentEmpty
+	}
+	if size > s.opts.MaxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	// 先頭バイトから内容のMIMEタイプを判定
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+	contentType := detectContentType(head)
+	if !s.allowed(contentType) {
+		return nil, ErrAttachmentTypeNotAllowed
+	}
+
+	key, err := attachmentKey(resumeID)
+	if err != nil {
+		return nil, err
+	}
+	// 保存しながらチェックサムとサイズを算出
+	hash := sha256.New()
+	counter := &countingWriter{}
+	body := io.TeeReader(io.MultiReader(bytes.NewReader(head), r), io.MultiWriter(hash, counter))
+	if err := s.store.Put(ctx, key, body, size, contentType); err != nil {
+		return nil, err
+	}
+	if counter.n != size {
+		s.removeObject(ctx, key)
+		return nil, fmt.Errorf("attachment: size mismatch (expected %d, got %d)", size, counter.n)
+	}
+
+	a := &domain.Attachment{
+		ResumeID:     resumeID,
+		ExperienceID: experienceID,
+		FileName:     sanitizeFileName(fileName),
+		ContentType:  contentType,
+		Size:         size,
+		Checksum:     hex.EncodeToString(hash.Sum(nil)),
+		StorageKey:   key,
+	}
+	if err := s.repo.Create(a); err != nil {
+		s.removeObject(ctx, key)
+		return nil, err
+	}
+	return a, nil
+}
+
+// Listは職務経歴書の添付ファイル一覧を返します。
+func (s *AttachmentService) List(resumeID uint) ([]domain.Attachment, error) {
+	if err := s.checkResume(resumeID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByResumeID(resumeID)
+}
+
+// Getは添付ファイルのメタデータを返します。
+func (s *AttachmentService) Get(id uint) (*domain.Attachment, error) {
+	a, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+// SignedURLは添付ファイルの期限付きダウンロードURLと有効期限を返します。
+func (s *AttachmentService) SignedURL(id uint) (string, time.Time, error) {
+	a, err := s.Get(id)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := s.now().Add(s.opts.URLTTL).Truncate(time.Second)
+	if p, ok := s.store.(storage.Presigner); ok {
+		u, err := p.PresignGet(a.StorageKey, s.opts.URLTTL, a.FileName)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		return u, expiresAt, nil
+	}
+	expires := expiresAt.Unix()
+	u := fmt.Sprintf("/api/v1/attachments/%d/download?expires=%d&signature=%s", a.ID, expires, s.signature(a.ID, expires))
+	return u, expiresAt, nil
+}
+
+// Openは署名を検証して添付ファイルの内容を返します（呼び出し側でClose）。
+func (s *AttachmentService) Open(ctx context.Context, id uint, expires, signature string) (*domain.Attachment, io.ReadCloser, error) {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || s.now().Unix() > exp ||
+		!hmac.Equal([]byte(signature), []byte(s.signature(id, exp))) {
+		return nil, nil, ErrInvalidDownloadSignature
+	}
+	a, err := s.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	rc, err := s.store.Open(ctx, a.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	return a, rc, nil
+}
+
+// Deleteは添付ファイルを削除します（ストレージ上の実体の削除失敗はログのみ）。
+func (s *AttachmentService) Delete(ctx context.Context, id uint) error {
+	a, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(a.ID); err != nil {
+		return err
+	}
+	s.removeObject(ctx, a.StorageKey)
+	return nil
+}
+
+// ObjectKeysは職務経歴書の添付ファイルのストレージキー一覧を返します（職務経歴書の削除前に取得する）。
+func (s *AttachmentService) ObjectKeys(resumeID uint) ([]string, error) {
+	attachments, err := s.repo.FindByResumeID(resumeID)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(attachments))
+	for i, a := range attachments {
+		keys[i] = a.StorageKey
+	}
+	return keys, nil
+}
+
+// RemoveObjectsはストレージ上の実体を削除します（失敗はログのみ）。
+func (s *AttachmentService) RemoveObjects(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		s.removeObject(ctx, key)
+	}
+}
+
+func (s *AttachmentService) removeObject(ctx context.Context, key string) {
+	if err := s.store.Delete(ctx, key); err != nil {
+		log.Printf("attachment: failed to remove object %s: %v", key, err)
+	}
+}
+
+func (s *AttachmentService) checkResume(resumeID uint) error {
+	if _, err := s.resumeRepo.GetByID(resumeID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *AttachmentService) allowed(contentType string) bool {
+	for _, t := range s.opts.AllowedTypes {
+		if strings.EqualFold(t, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// ダウンロードURLの署名（"id:expires"のHMAC-SHA256）
+func (s *AttachmentService) signature(id uint, expires int64) string {
+	m := hmac.New(sha256.New, s.opts.SigningKey)
+	fmt.Fprintf(m, "%d:%d", id, expires)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// 内容から判定したMIMEタイプ（"; charset=..."等のパラメータは除く）
+func detectContentType(head []byte) string {
+	ct := http.DetectContentType(head)
+	if mediaType, _, err := mime.ParseMediaType(ct); err == nil {
+		return mediaType
+	}
+	return ct
+}
+
+// 推測されにくいストレージキー（resumes/{職務経歴書ID}/{ランダム値}）
+func attachmentKey(resumeID uint) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("resumes/%d/%s", resumeID, hex.EncodeToString(b)), nil
+}
+
+// ファイル名からディレクトリ部分・制御文字を除き、255文字以内にする
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	for utf8.RuneCountInString(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+// 書き込まれたバイト数を数える
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
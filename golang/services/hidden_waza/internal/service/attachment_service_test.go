@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/storage"
+	"gorm.io/gorm"
+)
+
+// テスト用の添付ファイルのメタデータ
+type fakeAttachmentStore struct {
+	attachments map[uint]*domain.Attachment
+}
+
+func (f *fakeAttachmentStore) Create(a *domain.Attachment) error {
+	a.ID = uint(len(f.attachments) + 1)
+	f.attachments[a.ID] = a
+	return nil
+}
+
+func (f *fakeAttachmentStore) GetByID(id uint) (*domain.Attachment, error) {
+	a, ok := f.attachments[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return a, nil
+}
+
+func (f *fakeAttachmentStore) FindByResumeID(resumeID uint) ([]domain.Attachment, error) {
+	var out []domain.Attachment
+	for _, a := range f.attachments {
+		if a.ResumeID == resumeID {
+			out = append(out, *a)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeAttachmentStore) Delete(id uint) error {
+	delete(f.attachments, id)
+	return nil
+}
+
+func (f *fakeAttachmentStore) ExperienceBelongsTo(experienceID, resumeID uint) (bool, error) {
+	return false, nil
+}
+
+// ローカル保存の添付ファイル（ID 1）を1件持つAttachmentService
+func newTestAttachmentService(t *testing.T) (*AttachmentService, *time.Time) {
+	t.Helper()
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(context.Background(), "resumes/1/obj", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	repo := &fakeAttachmentStore{attachments: map[uint]*domain.Attachment{
+		1: {ID: 1, ResumeID: 1, FileName: "a.txt", StorageKey: "resumes/1/obj"},
+		2: {ID: 2, ResumeID: 1, FileName: "b.txt", StorageKey: "resumes/1/missing"},
+	}}
+	svc := NewAttachmentService(repo, nil, store, AttachmentOptions{URLTTL: 15 * time.Minute, SigningKey: []byte("test-key")})
+	now := time.Date(2025, 8, 1, 12, 0, 0, 500, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, &now
+}
+
+// 署名URLのexpires・signature
+func signedParams(t *testing.T, svc *AttachmentService, id uint) (expires, signature string) {
+	t.Helper()
+	raw, expiresAt, err := svc.SignedURL(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/api/v1/attachments/" + strconv.Itoa(int(id)) + "/download"; u.Path != want {
+		t.Errorf("path = %s, want %s", u.Path, want)
+	}
+	q := u.Query()
+	if q.Get("expires") != strconv.FormatInt(expiresAt.Unix(), 10) {
+		t.Errorf("expires = %s, expiresAt = %s", q.Get("expires"), expiresAt)
+	}
+	return q.Get("expires"), q.Get("signature")
+}
+
+func TestAttachmentSignedURL(t *testing.T) {
+	svc, now := newTestAttachmentService(t)
+	expires, signature := signedParams(t, svc, 1)
+	if want := now.Add(15 * time.Minute).Unix(); expires != strconv.FormatInt(want, 10) {
+		t.Errorf("expires = %s, want %d", expires, want)
+	}
+	a, rc, err := svc.Open(context.Background(), 1, expires, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	body, _ := io.ReadAll(rc)
+	if a.ID != 1 || string(body) != "hello" {
+		t.Errorf("Open = %+v, %q", a, body)
+	}
+
+	// 期限ちょうどまでは有効
+	*now = time.Unix(mustInt(t, expires), 0)
+	if _, rc, err := svc.Open(context.Background(), 1, expires, signature); err != nil {
+		t.Errorf("Open at expiry: %v", err)
+	} else {
+		rc.Close()
+	}
+}
+
+func TestAttachmentSignedURLExpired(t *testing.T) {
+	svc, now := newTestAttachmentService(t)
+	expires, signature := signedParams(t, svc, 1)
+	*now = time.Unix(mustInt(t, expires)+1, 0)
+	if _, _, err := svc.Open(context.Background(), 1, expires, signature); !errors.Is(err, ErrInvalidDownloadSignature) {
+		t.Errorf("Open after expiry: %v", err)
+	}
+}
+
+func TestAttachmentSignedURLTampered(t *testing.T) {
+	svc, _ := newTestAttachmentService(t)
+	expires, signature := signedParams(t, svc, 1)
+	later := strconv.FormatInt(mustInt(t, expires)+3600, 10)
+	flipped := []byte(signature)
+	if flipped[0] == '0' {
+		flipped[0] = '1'
+	} else {
+		flipped[0] = '0'
+	}
+	other := NewAttachmentService(svc.repo, nil, svc.store, AttachmentOptions{URLTTL: time.Minute, SigningKey: []byte("other-key")})
+	otherExpires, otherSignature := signedParams(t, other, 1)
+
+	tests := []struct {
+		name               string
+		id                 uint
+		expires, signature string
+	}{
+		{"other attachment", 2, expires, signature},
+		{"extended expiry", 1, later, signature},
+		{"modified signature", 1, expires, string(flipped)},
+		{"uppercase signature", 1, expires, strings.ToUpper(signature)},
+		{"empty signature", 1, expires, ""},
+		{"invalid expires", 1, "abc", signature},
+		{"signed with another key", 1, otherExpires, otherSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := svc.Open(context.Background(), tt.id, tt.expires, tt.signature); !errors.Is(err, ErrInvalidDownloadSignature) {
+				t.Errorf("Open: %v", err)
+			}
+		})
+	}
+}
+
+// 署名が正しくても実体がなければErrNotFound
+func TestAttachmentSignedURLMissingObject(t *testing.T) {
+	svc, _ := newTestAttachmentService(t)
+	expires, signature := signedParams(t, svc, 2)
+	if _, _, err := svc.Open(context.Background(), 2, expires, signature); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open: %v", err)
+	}
+	if _, _, err := svc.SignedURL(3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SignedURL unknown: %v", err)
+	}
+}
+
+func mustInt(t *testing.T, s string) int64 {
+	t.Helper()
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
@@ -0,0 +1,191 @@
+/*
+audit_service.go
+
+監査ログ（誰が・いつ・どこから・何をしたか）。
+  - ログイン（成功・失敗）、パーソナルアクセストークンの発行・失効、権限の変更、職務経歴書の作成・更新・削除・検証を記録する
+  - 各エントリーに行為者（ユーザー・トークン）、IPアドレス、User-Agent、リクエストID（X-Request-ID）を含める
+    行為者等はリクエストのcontextから取得する（WithAuditActor。ハンドラーのミドルウェアで設定）
+  - 職務経歴書の変更は変更前後の内容のハッシュ（ResumeHash）を含める
+  - 追記のみ（DBのトリガーで更新・削除を禁止）で、ハッシュチェーンにより改ざん・削除をVerifyで検出できる
+*/
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+)
+
+// 検証で一度に読み込むエントリーの件数
+const auditVerifyBatchSize = 1000
+
+// AuditActorはリクエストの行為者です。
+type AuditActor struct {
+	UserID    *uint
+	TokenID   *uint // パーソナルアクセストークンで認証した場合
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActorはリクエストの行為者を返す関数をcontextに設定します。
+// 認証はミドルウェアの後段で行われるため、記録する時点で呼び出します。
+func WithAuditActor(ctx context.Context, actor func() AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+func auditActorFrom(ctx context.Context) AuditActor {
+	if fn, ok := ctx.Value(auditActorKey{}).(func() AuditActor); ok {
+		return fn()
+	}
+	return AuditActor{}
+}
+
+// AuditEventは記録する操作です。
+type AuditEvent struct {
+	Action  string
+	Outcome string // 省略時は成功
+	// 行為者（未認証のリクエスト（ログイン等）で指定する。省略時はリクエストの認証済みユーザー）
+	ActorUserID *uint
+	TargetType  string
+	TargetID    string
+	BeforeHash  string
+	AfterHash   string
+	Details     map[string]interface{}
+}
+
+// AuditVerifyResultはハッシュチェーンの検証結果です。
+type AuditVerifyResult struct {
+	Valid    bool   `json:"valid"`
+	Checked  int64  `json:"checked"`   // 検証したエントリーの件数
+	HeadSeq  int64  `json:"head_seq"`  // 末尾の連番
+	HeadHash string `json:"head_hash"` // 末尾のハッシュ（外部に控えておくと末尾の削除・作り直しも検出できる）
+	// 不整合が見つかった連番と理由
+	BrokenSeq *int64 `json:"broken_seq,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// AuditServiceは監査ログを提供します。
+type AuditService struct {
+	repo *repository.AuditLogRepository
+	now  func() time.Time
+}
+
+// NewAuditServiceはAuditServiceを生成します。
+func NewAuditService(repo *repository.AuditLogRepository) *AuditService {
+	return &AuditService{repo: repo, now: time.Now}
+}
+
+// Recordは操作を記録します。記録に失敗しても操作は成功させるため、エラーはログのみです。
+func (s *AuditService) Record(ctx context.Context, ev AuditEvent) {
+	actor := auditActorFrom(ctx)
+	if ev.ActorUserID != nil {
+		actor.UserID = ev.ActorUserID
+	}
+	if ev.Outcome == "" {
+		ev.Outcome = domain.AuditSuccess
+	}
+	details := []byte("{}")
+	if len(ev.Details) > 0 {
+		b, err := json.Marshal(ev.Details)
+		if err != nil {
+			log.Printf("audit %s: %v", ev.Action, err)
+			return
+		}
+		details = b
+	}
+	entry := &domain.AuditLog{
+		Action:       ev.Action,
+		Outcome:      ev.Outcome,
+		ActorUserID:  actor.UserID,
+		ActorTokenID: actor.TokenID,
+		TargetType:   ev.TargetType,
+		TargetID:     truncateRunes(ev.TargetID, 64),
+		IP:           truncateRunes(actor.IP, 45),
+		UserAgent:    truncateRunes(actor.UserAgent, 255),
+		RequestID:    truncateRunes(actor.RequestID, 64),
+		BeforeHash:   ev.BeforeHash,
+		AfterHash:    ev.AfterHash,
+		Details:      string(details),
+		// DBの精度（マイクロ秒）に揃える
+		CreatedAt: s.now().UTC().Truncate(time.Microsecond),
+	}
+	if err := s.repo.Append(entry); err != nil {
+		log.Printf("audit %s: %v", ev.Action, err)
+	}
+}
+
+// Listは条件に合うエントリー（新しい順）を返します。
+func (s *AuditService) List(f repository.AuditLogFilter, limit int) ([]domain.AuditLog, error) {
+	return s.repo.List(f, limit)
+}
+
+// Verifyはハッシュチェーンを先頭から検証します（連番の欠番・前のハッシュとの不一致・内容の改ざん・末尾の削除）。
+// 検証中に追記されたエントリーは対象外です。
+func (s *AuditService) Verify() (*AuditVerifyResult, error) {
+	head, err := s.repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	res := &AuditVerifyResult{HeadSeq: head.Seq, HeadHash: head.Hash}
+	broken := func(seq int64, format string, args ...interface{}) (*AuditVerifyResult, error) {
+		res.BrokenSeq = &seq
+		res.Reason = fmt.Sprintf(format, args...)
+		return res, nil
+	}
+
+	prevSeq, prevHash := int64(0), domain.AuditGenesisHash
+	for prevSeq < head.Seq {
+		logs, err := s.repo.ListAfterSeq(prevSeq, auditVerifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(logs) == 0 {
+			return broken(prevSeq+1, "entries %d-%d are missing", prevSeq+1, head.Seq)
+		}
+		for i := range logs {
+			l := &logs[i]
+			if prevSeq == head.Seq {
+				break
+			}
+			if l.Seq != prevSeq+1 {
+				return broken(prevSeq+1, "entry %d is missing", prevSeq+1)
+			}
+			if l.PrevHash != prevHash {
+				return broken(l.Seq, "prev_hash does not match the previous entry")
+			}
+			if l.ComputeHash() != l.Hash {
+				return broken(l.Seq, "hash does not match the entry content")
+			}
+			prevSeq, prevHash = l.Seq, l.Hash
+			res.Checked++
+		}
+	}
+	if prevHash != head.Hash {
+		return broken(head.Seq, "last entry does not match the chain head")
+	}
+	res.Valid = true
+	return res, nil
+}
+
+// ResumeHashは職務経歴書の内容（スキル・職歴・翻訳を含む）のハッシュを返します（監査ログの変更前後のハッシュ用）。
+func ResumeHash(resume *domain.Resume) string {
+	if resume == nil {
+		return ""
+	}
+	b, err := json.Marshal(resume)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,89 @@
+// career_consistency.go: 申告スキル年数と職歴（使用スキル×期間）の突き合わせ
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"gorm.io/gorm"
+)
+
+// 申告年数は整数のため、裏付け年数に対してこの月数までの超過は許容する
+const skillYearsToleranceMonths = 6
+
+// Consistencyは指定IDの職務経歴書について、申告スキル年数を職歴から裏付けられる年数と比較します。
+func (s *CareerService) Consistency(resumeID uint) (*domain.SkillConsistency, error) {
+	resume, err := s.resumeRepo.GetByID(resumeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	c := s.CheckConsistency(resume.Skills, resume.Experiences)
+	return &c, nil
+}
+
+// CheckConsistencyは申告スキルと職歴から整合性チェック結果を算出します（DBアクセスなし）。
+func (s *CareerService) CheckConsistency(skills []domain.Skill, exps []domain.Experience) domain.SkillConsistency {
+	type skillKey struct {
+		skillType string
+		masterID  uint
+	}
+	// スキルごとに使用した職歴の期間を集める
+	periodsBySkill := map[skillKey][]period{}
+	for _, p := range toPeriods(exps, s.now()) {
+		for _, es := range p.exp.Skills {
+			k := skillKey{es.Type, es.MasterID}
+			periodsBySkill[k] = append(periodsBySkill[k], p)
+		}
+	}
+
+	result := domain.SkillConsistency{
+		Skills:   []domain.SkillEvidence{},
+		Warnings: []domain.ConsistencyWarning{},
+	}
+	for _, sk := range skills {
+		periods := periodsBySkill[skillKey{sk.Type, sk.MasterID}]
+		months := 0
+		for _, p := range mergePeriods(periods) {
+			months += monthsIn(p.from, p.to)
+		}
+		ids := make([]uint, 0, len(periods))
+		for _, p := range periods {
+			ids = append(ids, p.exp.ID)
+		}
+		ev := domain.SkillEvidence{
+			Type:            sk.Type,
+			MasterID:        sk.MasterID,
+			DeclaredYears:   sk.Years,
+			SupportedMonths: months,
+			SupportedYears:  monthsToYears(months),
+			ExperienceIDs:   ids,
+		}
+		result.Skills = append(result.Skills, ev)
+
+		switch {
+		case len(periods) == 0 && sk.Years > 0:
+			result.Warnings = append(result.Warnings, domain.ConsistencyWarning{
+				Code:           domain.ConsistencyNoEvidence,
+				Type:           sk.Type,
+				MasterID:       sk.MasterID,
+				DeclaredYears:  sk.Years,
+				SupportedYears: 0,
+				Message:        fmt.Sprintf("declared %d years but no experience lists this skill", sk.Years),
+			})
+		case sk.Years*12 > months+skillYearsToleranceMonths:
+			result.Warnings = append(result.Warnings, domain.ConsistencyWarning{
+				Code:           domain.ConsistencyExceedsEvidence,
+				Type:           sk.Type,
+				MasterID:       sk.MasterID,
+				DeclaredYears:  sk.Years,
+				SupportedYears: ev.SupportedYears,
+				Message:        fmt.Sprintf("declared %d years but experiences support %.1f years", sk.Years, ev.SupportedYears),
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,226 @@
+/*
+career_service.go
+
+職歴（Experience）から派生データを算出するサービス。
+- 総経験年数: 重複する期間をマージした実働期間
+- 空白期間: マージ後の期間の間で、閾値（Nヶ月）を超えるもの
+- 兼務: 期間が重なる職歴の組み合わせ
+- 在籍期間: 会社ごとの在籍期間（同一社内の重複はマージ）
+
+期間は日単位の閉区間 [開始日の期間初日, 終了日の期間末日] として扱い、
+年月精度の日付は月初〜月末、「現在」は算出時点の日付として計算します。
+*/
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DefaultGapThresholdMonthsは空白期間として検出する既定の閾値（月）
+const DefaultGapThresholdMonths = 3
+
+// 1ヶ月の平均日数（365.2425 / 12）
+const daysPerMonth = 30.436875
+
+// CareerServiceは職歴の集計処理を提供します。
+type CareerService struct {
+	resumeRepo *repository.ResumeRepository
+	now        func() time.Time
+}
+
+// NewCareerServiceはCareerServiceを生成します。
+func NewCareerService(resumeRepo *repository.ResumeRepository) *CareerService {
+	return &CareerService{resumeRepo: resumeRepo, now: time.Now}
+}
+
+// Metricsは指定IDの職務経歴書の職歴から派生データを算出します。
+func (s *CareerService) Metrics(resumeID uint, gapThreshold int) (*domain.CareerMetrics, error) {
+	resume, err := s.resumeRepo.GetByID(resumeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	m := s.Compute(resume.Experiences, gapThreshold)
+	return &m, nil
+}
+
+// Computeは職歴一覧から派生データを算出します（DBアクセスなし）。
+func (s *CareerService) Compute(exps []domain.Experience, gapThreshold int) domain.CareerMetrics {
+	if gapThreshold < 0 {
+		gapThreshold = DefaultGapThresholdMonths
+	}
+	now := s.now()
+	periods := toPeriods(exps, now)
+
+	merged := mergePeriods(periods)
+	totalMonths := 0
+	for _, p := range merged {
+		totalMonths += monthsIn(p.from, p.to)
+	}
+
+	return domain.CareerMetrics{
+		TotalMonths:    totalMonths,
+		TotalYears:     monthsToYears(totalMonths),
+		GapThreshold:   gapThreshold,
+		Gaps:           findGaps(merged, gapThreshold),
+		Overlaps:       findOverlaps(periods),
+		CompanyTenures: companyTenures(periods),
+	}
+}
+
+// 集計用の期間（日単位の閉区間）
+type period struct {
+	from, to time.Time
+	exp      domain.Experience
+}
+
+// 職歴を開始日順の期間に変換（開始日がない・期間が不正な職歴は除外）
+func toPeriods(exps []domain.Experience, now time.Time) []period {
+	periods := make([]period, 0, len(exps))
+	for _, e := range exps {
+		if e.ValidatePeriod() != nil {
+			continue
+		}
+		from := e.StartDate.FirstDay(now)
+		to := e.EndDate.LastDay(now)
+		if to.Before(from) {
+			// 未来日付開始の在籍中など
+			continue
+		}
+		periods = append(periods, period{from: from, to: to, exp: e})
+	}
+	sort.SliceStable(periods, func(i, j int) bool { return periods[i].from.Before(periods[j].from) })
+	return periods
+}
+
+// 重複・隣接する期間をマージ（入力は開始日順）
+func mergePeriods(periods []period) []period {
+	var merged []period
+	for _, p := range periods {
+		if n := len(merged); n > 0 && !p.from.After(merged[n-1].to.AddDate(0, 0, 1)) {
+			if p.to.After(merged[n-1].to) {
+				merged[n-1].to = p.to
+			}
+			continue
+		}
+		merged = append(merged, period{from: p.from, to: p.to})
+	}
+	return merged
+}
+
+// マージ後の期間の間の空白のうち、閾値（月）を超えるもの
+func findGaps(merged []period, threshold int) []domain.CareerGap {
+	gaps := []domain.CareerGap{}
+	for i := 1; i < len(merged); i++ {
+		from := merged[i-1].to.AddDate(0, 0, 1)
+		to := merged[i].from.AddDate(0, 0, -1)
+		months := monthsIn(from, to)
+		if months > threshold {
+			gaps = append(gaps, domain.CareerGap{
+				From:   formatDay(from),
+				To:     formatDay(to),
+				Months: months,
+			})
+		}
+	}
+	return gaps
+}
+
+// 期間が重なる職歴の組み合わせ（入力は開始日順）
+func findOverlaps(periods []period) []domain.CareerOverlap {
+	overlaps := []domain.CareerOverlap{}
+	for i := range periods {
+		for j := i + 1; j < len(periods); j++ {
+			a, b := periods[i], periods[j]
+			if b.from.After(a.to) {
+				// 開始日順なので以降も重ならない
+				break
+			}
+			to := a.to
+			if b.to.Before(to) {
+				to = b.to
+			}
+			overlaps = append(overlaps, domain.CareerOverlap{
+				ExperienceIDs: []uint{a.exp.ID, b.exp.ID},
+				Companies:     []string{a.exp.Company, b.exp.Company},
+				From:          formatDay(b.from),
+				To:            formatDay(to),
+				Months:        monthsIn(b.from, to),
+			})
+		}
+	}
+	return overlaps
+}
+
+// 会社ごとの在籍期間（会社名は前後空白・大文字小文字を無視して同一視）
+func companyTenures(periods []period) []domain.CompanyTenure {
+	type group struct {
+		name    string
+		periods []period
+		present bool
+	}
+	var order []string
+	groups := map[string]*group{}
+	for _, p := range periods {
+		key := strings.ToLower(strings.TrimSpace(p.exp.Company))
+		g, ok := groups[key]
+		if !ok {
+			g = &group{name: strings.TrimSpace(p.exp.Company)}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.periods = append(g.periods, p)
+		if p.exp.EndDate.IsPresent() {
+			g.present = true
+		}
+	}
+
+	tenures := make([]domain.CompanyTenure, 0, len(order))
+	for _, key := range order {
+		g := groups[key]
+		merged := mergePeriods(g.periods)
+		months := 0
+		for _, p := range merged {
+			months += monthsIn(p.from, p.to)
+		}
+		to := formatDay(merged[len(merged)-1].to)
+		if g.present {
+			to = domain.PresentLiteral
+		}
+		tenures = append(tenures, domain.CompanyTenure{
+			Company: g.name,
+			From:    formatDay(merged[0].from),
+			To:      to,
+			Months:  months,
+			Years:   monthsToYears(months),
+		})
+	}
+	return tenures
+}
+
+// 閉区間 [from, to] の月数（平均月日数で換算し四捨五入）
+func monthsIn(from, to time.Time) int {
+	if to.Before(from) {
+		return 0
+	}
+	days := to.Sub(from).Hours()/24 + 1
+	return int(math.Round(days / daysPerMonth))
+}
+
+func monthsToYears(months int) float64 {
+	return math.Round(float64(months)/12*10) / 10
+}
+
+func formatDay(t time.Time) string {
+	return t.Format("2006-01-02")
+}
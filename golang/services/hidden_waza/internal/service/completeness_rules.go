@@ -0,0 +1,246 @@
+/*
+completeness_rules.go
+
+職務経歴書の充実度スコアのルール定義。
+各ルールは充足度（0〜1）を返し、スコア = 100 × Σ(重み×充足度) / Σ重み（有効なルールのみ）。
+充足度が1未満のルールは改善提案になり、提案の`gain`は対応した場合に上がる点数です。
+
+ルールの有効/無効・重み・閾値（min_length / min_count）は設定ファイル
+（services/hidden_waza/config/completeness.yaml）で上書きできます。
+*/
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/requohylla/hidden-waza/pkg/config"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+)
+
+// CompletenessRuleは充実度スコアのルール1件（重み・閾値は設定で変更可）
+type CompletenessRule struct {
+	ID        string
+	Weight    int
+	MinLength int // 文字数の下限（文字数を見るルールのみ）
+	MinCount  int // 件数の下限（件数を見るルールのみ）
+}
+
+// 提案文に使う閾値（文字数・件数のいずれか）
+func (r CompletenessRule) threshold() int {
+	if r.MinLength > 0 {
+		return r.MinLength
+	}
+	return r.MinCount
+}
+
+// ルールの評価結果
+type ruleResult struct {
+	ratio         float64 // 充足度（0〜1）
+	experienceIDs []uint  // 対象の職歴
+	count         int     // 対象件数
+	// 上位のルールで提案済みのため提案を出さない（例: 職歴がない場合の職歴単位のルール）
+	silent bool
+}
+
+type ruleEvaluator func(rule CompletenessRule, r *domain.Resume) ruleResult
+
+// ルールIDごとの評価処理
+var completenessEvaluators = map[string]ruleEvaluator{
+	"summary_missing": func(_ CompletenessRule, r *domain.Resume) ruleResult {
+		return boolResult(strings.TrimSpace(r.Summary) != "")
+	},
+	"summary_too_short": func(rule CompletenessRule, r *domain.Resume) ruleResult {
+		n := textLength(r.Summary)
+		if n == 0 {
+			return ruleResult{silent: true}
+		}
+		return ruleResult{ratio: minRatio(n, rule.MinLength)}
+	},
+	"experiences_missing": func(rule CompletenessRule, r *domain.Resume) ruleResult {
+		return ruleResult{ratio: minRatio(len(r.Experiences), rule.MinCount), count: len(r.Experiences)}
+	},
+	"experience_description_missing": experienceRule(func(_ CompletenessRule, e domain.Experience) bool {
+		return textLength(e.Description) > 0
+	}),
+	"experience_description_too_short": experienceRule(func(rule CompletenessRule, e domain.Experience) bool {
+		// 未記入は experience_description_missing で扱う
+		n := textLength(e.Description)
+		return n == 0 || n >= rule.MinLength
+	}),
+	"experience_dates_missing": experienceRule(func(_ CompletenessRule, e domain.Experience) bool {
+		return e.ValidatePeriod() == nil
+	}),
+	"skills_missing": func(rule CompletenessRule, r *domain.Resume) ruleResult {
+		return ruleResult{ratio: minRatio(len(r.Skills), rule.MinCount), count: len(r.Skills)}
+	},
+	"skill_years_missing": func(_ CompletenessRule, r *domain.Resume) ruleResult {
+		if len(r.Skills) == 0 {
+			return ruleResult{silent: true}
+		}
+		missing := 0
+		for _, s := range r.Skills {
+			if s.Years <= 0 {
+				missing++
+			}
+		}
+		return ruleResult{ratio: float64(len(r.Skills)-missing) / float64(len(r.Skills)), count: missing}
+	},
+	"portfolio_missing": func(_ CompletenessRule, r *domain.Resume) ruleResult {
+		if len(r.Experiences) == 0 {
+			return ruleResult{silent: true}
+		}
+		for _, e := range r.Experiences {
+			if strings.TrimSpace(e.PortfolioURL) != "" {
+				return ruleResult{ratio: 1}
+			}
+		}
+		return ruleResult{}
+	},
+}
+
+// 提案文の%dに閾値（min_length / min_count）を使うルール（それ以外は対象件数）
+var thresholdMessages = map[string]bool{
+	"summary_too_short":                true,
+	"experiences_missing":              true,
+	"experience_description_too_short": true,
+	"skills_missing":                   true,
+}
+
+// 提案文（%dは閾値または対象件数）
+var completenessMessages = map[string]map[domain.Locale]string{
+	"summary_missing": {
+		domain.LocaleJA: "職務要約を記入しましょう",
+		domain.LocaleEN: "Add a summary",
+	},
+	"summary_too_short": {
+		domain.LocaleJA: "職務要約を%d文字以上に充実させましょう",
+		domain.LocaleEN: "Expand your summary to at least %d characters",
+	},
+	"experiences_missing": {
+		domain.LocaleJA: "職歴を%d件以上登録しましょう",
+		domain.LocaleEN: "Add at least %d work experience(s)",
+	},
+	"experience_description_missing": {
+		domain.LocaleJA: "業務内容が未記入の職歴が%d件あります",
+		domain.LocaleEN: "%d experience(s) have no description",
+	},
+	"experience_description_too_short": {
+		domain.LocaleJA: "業務内容が%d文字未満の職歴があります。担当範囲や成果を具体的に書きましょう",
+		domain.LocaleEN: "Some descriptions are shorter than %d characters; describe your scope and results",
+	},
+	"experience_dates_missing": {
+		domain.LocaleJA: "期間が未入力または不正な職歴が%d件あります",
+		domain.LocaleEN: "%d experience(s) have missing or invalid dates",
+	},
+	"skills_missing": {
+		domain.LocaleJA: "スキルを%d件以上登録しましょう",
+		domain.LocaleEN: "Add at least %d skills",
+	},
+	"skill_years_missing": {
+		domain.LocaleJA: "経験年数が未入力のスキルが%d件あります",
+		domain.LocaleEN: "%d skill(s) have no years of experience",
+	},
+	"portfolio_missing": {
+		domain.LocaleJA: "成果物のURL（ポートフォリオ）を職歴に追加しましょう",
+		domain.LocaleEN: "Add a portfolio URL to one of your experiences",
+	},
+}
+
+// DefaultCompletenessRulesは既定のルール一覧（重みの合計100）を返します。
+func DefaultCompletenessRules() []CompletenessRule {
+	return []CompletenessRule{
+		{ID: "summary_missing", Weight: 15},
+		{ID: "summary_too_short", Weight: 10, MinLength: 100},
+		{ID: "experiences_missing", Weight: 15, MinCount: 1},
+		{ID: "experience_description_missing", Weight: 15},
+		{ID: "experience_description_too_short", Weight: 5, MinLength: 50},
+		{ID: "experience_dates_missing", Weight: 10},
+		{ID: "skills_missing", Weight: 10, MinCount: 3},
+		{ID: "skill_years_missing", Weight: 10},
+		{ID: "portfolio_missing", Weight: 10},
+	}
+}
+
+// CompletenessRulesFromConfigは既定のルールに設定の上書きを適用したルール一覧を返します。
+// cfgがnilなら既定のルールのまま。未知のルールIDや負の値はエラーです。
+func CompletenessRulesFromConfig(cfg *config.CompletenessConfig) ([]CompletenessRule, error) {
+	rules := DefaultCompletenessRules()
+	if cfg == nil {
+		return rules, nil
+	}
+	overrides := cfg.Completeness.Rules
+	for id := range overrides {
+		if _, ok := completenessEvaluators[id]; !ok {
+			return nil, fmt.Errorf("completeness: unknown rule %q", id)
+		}
+	}
+	enabled := make([]CompletenessRule, 0, len(rules))
+	for _, rule := range rules {
+		o, ok := overrides[rule.ID]
+		if !ok {
+			enabled = append(enabled, rule)
+			continue
+		}
+		if o.Enabled != nil && !*o.Enabled {
+			continue
+		}
+		for _, v := range []*int{o.Weight, o.MinLength, o.MinCount} {
+			if v != nil && *v < 0 {
+				return nil, fmt.Errorf("completeness: negative value for rule %q", rule.ID)
+			}
+		}
+		if o.Weight != nil {
+			rule.Weight = *o.Weight
+		}
+		if o.MinLength != nil {
+			rule.MinLength = *o.MinLength
+		}
+		if o.MinCount != nil {
+			rule.MinCount = *o.MinCount
+		}
+		enabled = append(enabled, rule)
+	}
+	return enabled, nil
+}
+
+// 職歴ごとの条件を満たす割合（満たさない職歴を対象として返す）
+func experienceRule(ok func(CompletenessRule, domain.Experience) bool) ruleEvaluator {
+	return func(rule CompletenessRule, r *domain.Resume) ruleResult {
+		if len(r.Experiences) == 0 {
+			return ruleResult{silent: true}
+		}
+		var ids []uint
+		for _, e := range r.Experiences {
+			if !ok(rule, e) {
+				ids = append(ids, e.ID)
+			}
+		}
+		return ruleResult{
+			ratio:         float64(len(r.Experiences)-len(ids)) / float64(len(r.Experiences)),
+			experienceIDs: ids,
+			count:         len(ids),
+		}
+	}
+}
+
+func boolResult(ok bool) ruleResult {
+	if ok {
+		return ruleResult{ratio: 1}
+	}
+	return ruleResult{}
+}
+
+func textLength(s string) int {
+	return utf8.RuneCountInString(strings.TrimSpace(s))
+}
+
+// 下限minに対する充足度
+func minRatio(n, min int) float64 {
+	if min <= 0 {
+		return 1
+	}
+	return math.Min(1, float64(n)/float64(min))
+}
@@ -0,0 +1,144 @@
+// completeness_service.go: 職務経歴書の充実度スコア（0〜100）と改善提案の算出・リビジョンごとの記録
+//
+// ルールは[`completeness_rules.go`](services/hidden_waza/internal/service/completeness_rules.go)を参照。
+// 職務経歴書の登録・更新のたびにRecordでスコアを記録し、リビジョン番号を1から採番します。
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"gorm.io/gorm"
+)
+
+// CompletenessServiceは充実度スコアの算出・記録を提供します。
+type CompletenessService struct {
+	resumeRepo *repository.ResumeRepository
+	scoreRepo  *repository.CompletenessScoreRepository
+	rules      []CompletenessRule
+}
+
+// NewCompletenessServiceはCompletenessServiceを生成します（rulesは評価するルール一覧）。
+func NewCompletenessService(resumeRepo *repository.ResumeRepository, scoreRepo *repository.CompletenessScoreRepository, rules []CompletenessRule) *CompletenessService {
+	return &CompletenessService{resumeRepo: resumeRepo, scoreRepo: scoreRepo, rules: rules}
+}
+
+// Evaluateは職務経歴書の充実度スコアと改善提案を算出します（DBアクセスなし・提案文は未設定）。
+// 提案は上がる点数の大きい順です。
+func (s *CompletenessService) Evaluate(resume *domain.Resume) domain.CompletenessScore {
+	score := domain.CompletenessScore{ResumeID: resume.ID, Suggestions: []domain.CompletenessSuggestion{}}
+	total := 0
+	for _, rule := range s.rules {
+		total += rule.Weight
+	}
+	if total == 0 {
+		score.Score = 100
+		return score
+	}
+
+	var earned float64
+	for _, rule := range s.rules {
+		eval, ok := completenessEvaluators[rule.ID]
+		if !ok || rule.Weight == 0 {
+			continue
+		}
+		res := eval(rule, resume)
+		earned += float64(rule.Weight) * res.ratio
+		if res.ratio >= 1 || res.silent {
+			continue
+		}
+		sg := domain.CompletenessSuggestion{
+			RuleID:        rule.ID,
+			Gain:          round1(100 * float64(rule.Weight) * (1 - res.ratio) / float64(total)),
+			ExperienceIDs: res.experienceIDs,
+			Count:         res.count,
+		}
+		if thresholdMessages[rule.ID] {
+			sg.Threshold = rule.threshold()
+		}
+		score.Suggestions = append(score.Suggestions, sg)
+	}
+	score.Score = int(math.Round(100 * earned / float64(total)))
+	sort.SliceStable(score.Suggestions, func(i, j int) bool {
+		return score.Suggestions[i].Gain > score.Suggestions[j].Gain
+	})
+	return score
+}
+
+// Recordは職務経歴書のスコアを算出し、次のリビジョンとして記録します。
+func (s *CompletenessService) Record(resume *domain.Resume) (*domain.CompletenessScore, error) {
+	score := s.Evaluate(resume)
+	if err := s.scoreRepo.Create(&score); err != nil {
+		return nil, err
+	}
+	return &score, nil
+}
+
+// Latestは指定IDの職務経歴書の最新リビジョンのスコアを返します。
+// 記録がない場合（機能追加前に登録された職務経歴書など）は現在の内容から算出したスコア（リビジョン0）を返します。
+func (s *CompletenessService) Latest(resumeID uint, loc domain.Locale) (*domain.CompletenessScore, error) {
+	score, err := s.scoreRepo.Latest(resumeID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		resume, err := s.resumeRepo.GetByID(resumeID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+		evaluated := s.Evaluate(resume)
+		score = &evaluated
+	}
+	LocalizeSuggestions(score.Suggestions, loc)
+	return score, nil
+}
+
+// Historyは指定IDの職務経歴書の全リビジョンのスコアを新しい順に返します。
+func (s *CompletenessService) History(resumeID uint, loc domain.Locale) ([]domain.CompletenessScore, error) {
+	if _, err := s.resumeRepo.GetByID(resumeID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	scores, err := s.scoreRepo.FindByResumeID(resumeID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range scores {
+		LocalizeSuggestions(scores[i].Suggestions, loc)
+	}
+	return scores, nil
+}
+
+// LocalizeSuggestionsは改善提案の提案文を指定言語で設定します。
+func LocalizeSuggestions(suggestions []domain.CompletenessSuggestion, loc domain.Locale) {
+	for i := range suggestions {
+		sg := &suggestions[i]
+		format, ok := completenessMessages[sg.RuleID][loc]
+		if !ok {
+			format, ok = completenessMessages[sg.RuleID][domain.DefaultLocale]
+		}
+		if !ok {
+			sg.Message = sg.RuleID
+			continue
+		}
+		arg := sg.Count
+		if thresholdMessages[sg.RuleID] {
+			arg = sg.Threshold
+		}
+		if strings.Contains(format, "%d") {
+			sg.Message = fmt.Sprintf(format, arg)
+		} else {
+			sg.Message = format
+		}
+	}
+}
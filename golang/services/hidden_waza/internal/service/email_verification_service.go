@@ -0,0 +1,234 @@
+/*
+email_verification_service.go
+
+会員登録時のメールアドレスの確認。
+  - 確認メールのリンクには署名付きのトークン（<ユーザーID>.<有効期限>.<乱数>.<署名>）を含める
+    署名（HMAC-SHA256）と有効期限はDBを参照せずに検証し、DBにはトークンのハッシュのみを保存して1回だけ使用可能にする
+  - 確認メールの再送は一定間隔を空ける（再送すると以前のリンクは無効）
+  - メールアドレスが未確認のユーザーは職務経歴書を作成・更新できない（RequireVerified）
+*/
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/requohylla/hidden-waza/pkg/config"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"gorm.io/gorm"
+)
+
+// メールアドレス確認の既定値
+const (
+	DefaultEmailVerificationURL = "http://localhost:3000/signup/verify"
+	DefaultEmailVerificationTTL = 24 * time.Hour
+	DefaultEmailResendCooldown  = time.Minute
+)
+
+// 署名の対象に含める用途（同じ署名鍵を他のトークンに使っても流用できないようにする）
+const emailVerificationPurpose = "email_verification"
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrEmailNotVerified         = errors.New("email address is not verified")
+	ErrEmailAlreadyVerified     = errors.New("email address is already verified")
+)
+
+// ResendCooldownErrorは確認メールの再送間隔が空いていない場合のエラーです。
+type ResendCooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ResendCooldownError) Error() string {
+	return fmt.Sprintf("verification email was sent recently; retry after %d seconds", e.Seconds())
+}
+
+// Secondsは再送できるまでの秒数（切り上げ）を返します（Retry-Afterヘッダー用）。
+func (e *ResendCooldownError) Seconds() int {
+	return int((e.RetryAfter + time.Second - 1) / time.Second)
+}
+
+// EmailVerificationOptionsはメールアドレス確認の設定です。
+type EmailVerificationOptions struct {
+	// トークンの署名鍵
+	SigningKey []byte
+	// 確認メールのリンク先（?token=<トークン>を付与）
+	URL            string
+	TTL            time.Duration
+	ResendCooldown time.Duration
+}
+
+// EmailVerificationOptionsFromConfigは設定ファイルの値からメールアドレス確認の設定を返します（未指定の項目は既定値）。
+func EmailVerificationOptionsFromConfig(cfg *config.AuthConfig) EmailVerificationOptions {
+	opts := EmailVerificationOptions{
+		URL:            DefaultEmailVerificationURL,
+		TTL:            DefaultEmailVerificationTTL,
+		ResendCooldown: DefaultEmailResendCooldown,
+	}
+	if cfg == nil {
+		return opts
+	}
+	ev := cfg.Auth.EmailVerification
+	if ev.URL != "" {
+		opts.URL = ev.URL
+	}
+	if ev.TTLHours > 0 {
+		opts.TTL = time.Duration(ev.TTLHours) * time.Hour
+	}
+	if ev.ResendCooldownSeconds > 0 {
+		opts.ResendCooldown = time.Duration(ev.ResendCooldownSeconds) * time.Second
+	}
+	opts.SigningKey = []byte(cfg.Auth.TokenSigningKey)
+	return opts
+}
+
+// EmailVerificationServiceはメールアドレスの確認を提供します。
+type EmailVerificationService struct {
+	repo          *repository.EmailVerificationRepository
+	users         *repository.UserRepository
+	notifications *NotificationService
+	opts          EmailVerificationOptions
+	now           func() time.Time
+}
+
+// NewEmailVerificationServiceはEmailVerificationServiceを生成します。
+func NewEmailVerificationService(repo *repository.EmailVerificationRepository, users *repository.UserRepository, notifications *NotificationService, opts EmailVerificationOptions) *EmailVerificationService {
+	return &EmailVerificationService{repo: repo, users: users, notifications: notifications, opts: opts, now: time.Now}
+}
+
+// Sendは新しいトークンを発行して確認メールを送信します（以前のトークンは無効）。
+func (s *EmailVerificationService) Send(user *domain.User) error {
+	now := s.now()
+	expiresAt := now.Add(s.opts.TTL)
+	token, err := s.newToken(user.ID, expiresAt)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.IssueToken(&domain.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}); err != nil {
+		return err
+	}
+	return s.notifications.SendEmailVerification(user, s.link(token), expiresAt)
+}
+
+// Resendは確認メールを再送します。
+// 確認済みならErrEmailAlreadyVerified、前回の送信から再送間隔が空いていなければ*ResendCooldownErrorを返します。
+func (s *EmailVerificationService) Resend(userID uint) error {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return notFound(err)
+	}
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+	last, err := s.repo.LatestToken(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if last != nil {
+		if wait := last.CreatedAt.Add(s.opts.ResendCooldown).Sub(s.now()); wait > 0 {
+			return &ResendCooldownError{RetryAfter: wait}
+		}
+	}
+	return s.Send(user)
+}
+
+// Verifyはトークンを検証してメールアドレスを確認済みにし、会員登録完了のメールを送信します。
+// トークンが不正・期限切れ・使用済みならErrInvalidVerificationTokenを返します。
+func (s *EmailVerificationService) Verify(token string) (*domain.User, error) {
+	userID, ok := s.parseToken(token)
+	if !ok {
+		return nil, ErrInvalidVerificationToken
+	}
+	user, err := s.repo.ConsumeToken(userID, hashToken(token), s.now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+	// 送信はジョブで非同期に行うため、失敗してもログのみ
+	if err := s.notifications.SendWelcome(user); err != nil {
+		log.Printf("welcome mail for user %d: %v", user.ID, err)
+	}
+	return user, nil
+}
+
+// RequireVerifiedはユーザーのメールアドレスが確認済みでなければErrEmailNotVerifiedを返します。
+func (s *EmailVerificationService) RequireVerified(userID uint) error {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return notFound(err)
+	}
+	if !user.IsEmailVerified() {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// トークン（<ユーザーID>.<有効期限（Unix秒）>.<乱数>.<署名>）を生成
+func (s *EmailVerificationService) newToken(userID uint, expiresAt time.Time) (string, error) {
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	payload := fmt.Sprintf("%d.%d.%s", userID, expiresAt.Unix(), base64.RawURLEncoding.EncodeToString(nonce))
+	return payload + "." + s.sign(payload), nil
+}
+
+// トークンの署名と有効期限を検証し、ユーザーIDを返す
+func (s *EmailVerificationService) parseToken(token string) (uint, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return 0, false
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(s.sign(payload))) {
+		return 0, false
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || userID == 0 {
+		return 0, false
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || !s.now().Before(time.Unix(exp, 0)) {
+		return 0, false
+	}
+	return uint(userID), true
+}
+
+func (s *EmailVerificationService) sign(payload string) string {
+	m := hmac.New(sha256.New, s.opts.SigningKey)
+	m.Write([]byte(emailVerificationPurpose + "." + payload))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+}
+
+// 確認メールのリンク
+func (s *EmailVerificationService) link(token string) string {
+	sep := "?"
+	if strings.Contains(s.opts.URL, "?") {
+		sep = "&"
+	}
+	return s.opts.URL + sep + "token=" + url.QueryEscape(token)
+}
+
+// DBに保存するトークンのハッシュ（SHA-256の16進）
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
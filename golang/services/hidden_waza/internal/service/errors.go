@@ -0,0 +1,9 @@
+// errors.go: サービス層共通エラー
+package service
+
+import "errors"
+
+var (
+	// ErrNotFoundは対象データが存在しない場合のエラー
+	ErrNotFound = errors.New("not found")
+)
@@ -0,0 +1,99 @@
+// event_broker.go: プロセス内のイベント配信（ユーザーごとのpub/sub）
+//
+// 同じイベントが複数の経路（自インスタンスのコミット通知とDBのポーリング）から届いても1回だけ配信します。
+// 受信が追いつかない購読者はチャネルを閉じて切断し、クライアントの再接続（Last-Event-ID）で取りこぼしを補います。
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+)
+
+const (
+	// 購読者ごとの未送信イベントの上限
+	eventSubscriptionBuffer = 64
+	// 配信済みのイベントIDを重複排除のために覚えておく期間
+	eventDedupWindow = 5 * time.Minute
+)
+
+// EventSubscriptionはユーザー1人分の購読です。Cが閉じられたら購読は終了しています。
+type EventSubscription struct {
+	C      chan domain.OutboxEvent
+	userID uint
+}
+
+// EventBrokerはイベントを購読しているユーザーに配信します。
+type EventBroker struct {
+	mu   sync.Mutex
+	subs map[uint]map[*EventSubscription]struct{}
+	seen map[uint]time.Time // 配信済みのイベントID
+	now  func() time.Time
+}
+
+// NewEventBrokerはEventBrokerを生成します。
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		subs: map[uint]map[*EventSubscription]struct{}{},
+		seen: map[uint]time.Time{},
+		now:  time.Now,
+	}
+}
+
+// Subscribeはユーザーのイベントを購読します（終了時はUnsubscribeを呼び出す）。
+func (b *EventBroker) Subscribe(userID uint) *EventSubscription {
+	sub := &EventSubscription{C: make(chan domain.OutboxEvent, eventSubscriptionBuffer), userID: userID}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs[userID] == nil {
+		b.subs[userID] = map[*EventSubscription]struct{}{}
+	}
+	b.subs[userID][sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribeは購読を終了します（複数回呼び出しても問題ありません）。
+func (b *EventBroker) Unsubscribe(sub *EventSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remove(sub)
+}
+
+func (b *EventBroker) remove(sub *EventSubscription) {
+	subs := b.subs[sub.userID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subs, sub.userID)
+	}
+	close(sub.C)
+}
+
+// Publishはイベントを所有者の購読者に配信します（配信済みのイベントは無視）。
+func (b *EventBroker) Publish(events []domain.OutboxEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	for id, t := range b.seen {
+		if now.Sub(t) > eventDedupWindow {
+			delete(b.seen, id)
+		}
+	}
+	for _, ev := range events {
+		if _, ok := b.seen[ev.ID]; ok {
+			continue
+		}
+		b.seen[ev.ID] = now
+		for sub := range b.subs[ev.UserID] {
+			select {
+			case sub.C <- ev:
+			default:
+				// 受信が追いつかない購読者は切断（再接続時にLast-Event-IDから再送）
+				b.remove(sub)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+event_stream_service.go
+
+職務経歴書のイベント（作成・更新・削除・verifiedの変更）をユーザーにリアルタイムで配信する（SSE用）。
+  - 自インスタンスでの変更は、ResumeRepositoryのコミット後の通知からそのままEventBrokerに配信
+  - 複数インスタンスで動かす場合はRunPollerでアウトボックス（outbox_events）を定期的に確認し、
+    他のインスタンスで記録されたイベントも配信する（重複はEventBrokerが除外）
+  - 再接続時はLast-Event-ID（イベントID）より後のイベントをDBから再送
+*/
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+)
+
+const (
+	// 再送・ポーリングで1回に取得するイベント数
+	eventPageSize = 200
+	// ポーリングで遡る時間（コミットが遅れてIDの小さいイベントが後から見えるようになる分）
+	eventPollLookback = 30 * time.Second
+)
+
+// EventEnvelopeはイベントの送信形式です（SSEのdata・Webhookのボディ）。
+type EventEnvelope struct {
+	ID        uint            `json:"id"` // イベントID（再配信でも同じ。受信側の重複排除用）
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewEventEnvelopeはイベントの送信形式を返します。
+func NewEventEnvelope(ev *domain.OutboxEvent) EventEnvelope {
+	return EventEnvelope{ID: ev.ID, Type: ev.Type, CreatedAt: ev.CreatedAt, Data: ev.Data}
+}
+
+// EventStreamServiceはユーザーごとのイベントの購読・再送を提供します。
+type EventStreamService struct {
+	repo   *repository.EventRepository
+	broker *EventBroker
+	now    func() time.Time
+}
+
+// NewEventStreamServiceはEventStreamServiceを生成します。
+func NewEventStreamService(repo *repository.EventRepository) *EventStreamService {
+	return &EventStreamService{repo: repo, broker: NewEventBroker(), now: time.Now}
+}
+
+// Publishはコミットされたイベントを購読者に配信します（ResumeRepository.OnEventsに登録する）。
+func (s *EventStreamService) Publish(events []domain.OutboxEvent) {
+	s.broker.Publish(events)
+}
+
+// Subscribeはユーザーのイベントを購読します（終了時はUnsubscribeを呼び出す）。
+func (s *EventStreamService) Subscribe(userID uint) *EventSubscription {
+	return s.broker.Subscribe(userID)
+}
+
+func (s *EventStreamService) Unsubscribe(sub *EventSubscription) {
+	s.broker.Unsubscribe(sub)
+}
+
+// ReplayはユーザーのイベントのうちIDがafterIDより大きいものを古い順にfnへ渡します。
+func (s *EventStreamService) Replay(userID, afterID uint, fn func(ev *domain.OutboxEvent) error) error {
+	for {
+		events, err := s.repo.AfterID(userID, afterID, eventPageSize)
+		if err != nil {
+			return err
+		}
+		for i := range events {
+			if err := fn(&events[i]); err != nil {
+				return err
+			}
+			afterID = events[i].ID
+		}
+		if len(events) < eventPageSize {
+			return nil
+		}
+	}
+}
+
+// RunPollerはctxがキャンセルされるまでintervalごとにアウトボックスを確認し、
+// 他のインスタンスで記録されたイベントを配信します（複数インスタンス構成のみ起動）。
+func (s *EventStreamService) RunPoller(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := s.now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := s.now()
+		if err := s.poll(last.Add(-eventPollLookback)); err != nil {
+			log.Printf("event poller: %v", err)
+			continue
+		}
+		last = now
+	}
+}
+
+func (s *EventStreamService) poll(since time.Time) error {
+	var afterID uint
+	for {
+		events, err := s.repo.Since(since, afterID, eventPageSize)
+		if err != nil {
+			return err
+		}
+		s.broker.Publish(events)
+		if len(events) < eventPageSize {
+			return nil
+		}
+		afterID = events[len(events)-1].ID
+	}
+}
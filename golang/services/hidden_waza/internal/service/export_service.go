@@ -0,0 +1,63 @@
+/*
+export_service.go
+
+職務経歴書のファイル出力（docx等）に関する業務ロジックを集約するサービス。
+- Resume集約（スキル・職歴込み）をリポジトリから取得
+- スキルのマスタID→名称を[`MasterNameResolver`](services/hidden_waza/internal/service/master_name_resolver.go)で解決（出力言語の翻訳があれば翻訳名）
+- 職務経歴書本文も出力言語で解決（翻訳がなければ原文）
+- エクスポーター（[`DocxExporter`](services/hidden_waza/internal/export/docx_exporter.go)）へ委譲
+*/
+package service
+
+import (
+	"errors"
+	"io"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/export"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ExportServiceは職務経歴書のエクスポート処理を提供します。
+type ExportService struct {
+	resumeRepo *repository.ResumeRepository
+	names      *MasterNameResolver
+	docx       *export.DocxExporter
+}
+
+// NewExportServiceはExportServiceを生成します。
+func NewExportService(resumeRepo *repository.ResumeRepository, names *MasterNameResolver) *ExportService {
+	return &ExportService{
+		resumeRepo: resumeRepo,
+		names:      names,
+		docx:       export.NewDocxExporter(),
+	}
+}
+
+// DocxContentTypeはdocxのMIMEタイプを返します。
+func (s *ExportService) DocxContentType() string {
+	return s.docx.ContentType()
+}
+
+// ExportDocxは指定IDの職務経歴書をdocxとしてwに書き出します。
+func (s *ExportService) ExportDocx(w io.Writer, resumeID uint, opt export.Options) error {
+	resume, err := s.resumeRepo.GetByID(resumeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	loc, ok := domain.ParseLocale(opt.Lang)
+	if !ok {
+		loc = domain.DefaultLocale
+	}
+	opt.Lang = string(loc)
+	names, err := s.names.ResolveSkills(resume.Skills, loc)
+	if err != nil {
+		return err
+	}
+	localized := resume.Localized(loc)
+	return s.docx.Export(w, &localized, names, opt)
+}
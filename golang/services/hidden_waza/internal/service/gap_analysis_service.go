@@ -0,0 +1,194 @@
+/*
+gap_analysis_service.go
+
+職務経歴書のスキルと目標ロールのスキルプロファイル（SkillTarget）の差分を分析するサービス。
+- missing: 目標にあるが職務経歴書にないスキル
+- below:   職務経歴書にあるが最低年数・最低レベルのいずれかを満たさないスキル
+- met:     目標を満たすスキル
+- surplus: 職務経歴書にあるが目標にないスキル
+
+同一スキルが職務経歴書に複数登録されている場合は年数・レベルそれぞれ最大の値を採用し（マッチングと同じ規則）、
+目標に同一スキルが重複している場合は最低年数・最低レベルそれぞれ厳しい方に統合します。
+*/
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/export"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidSkillTargetは目標スキルの指定が不正な場合のエラー
+	ErrInvalidSkillTarget = errors.New("invalid skill target")
+	// ErrUnknownMasterは目標スキルがマスタに存在しない場合のエラー
+	ErrUnknownMaster = errors.New("unknown master")
+)
+
+// ギャップ分析結果の種別の並び順
+var skillTypes = []string{"language", "tool", "os"}
+
+// GapAnalysisServiceはスキルギャップ分析を提供します。
+type GapAnalysisService struct {
+	resumeRepo *repository.ResumeRepository
+	names      *MasterNameResolver
+}
+
+// NewGapAnalysisServiceはGapAnalysisServiceを生成します。
+func NewGapAnalysisService(resumeRepo *repository.ResumeRepository, names *MasterNameResolver) *GapAnalysisService {
+	return &GapAnalysisService{resumeRepo: resumeRepo, names: names}
+}
+
+// Analyzeは指定IDの職務経歴書と目標スキルのギャップを分析します（スキル名はlocで解決）。
+func (s *GapAnalysisService) Analyze(resumeID uint, targets []domain.SkillTarget, loc domain.Locale) (*domain.SkillGap, error) {
+	if len(targets) == 0 {
+		return nil, ErrInvalidSkillTarget
+	}
+	for i, t := range targets {
+		if !t.IsValid() {
+			return nil, fmt.Errorf("targets[%d]: %w", i, ErrInvalidSkillTarget)
+		}
+	}
+	resume, err := s.resumeRepo.GetByID(resumeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	ids := map[string][]uint{}
+	for _, t := range targets {
+		ids[t.Type] = append(ids[t.Type], t.MasterID)
+	}
+	for _, sk := range resume.Skills {
+		ids[sk.Type] = append(ids[sk.Type], sk.MasterID)
+	}
+	names, err := s.names.Resolve(ids, loc)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range targets {
+		if _, ok := names[t.Type][t.MasterID]; !ok {
+			return nil, fmt.Errorf("targets[%d]: %w: %s %d", i, ErrUnknownMaster, t.Type, t.MasterID)
+		}
+	}
+
+	gap := AnalyzeGap(targets, resume.Skills, names)
+	gap.ResumeID = resume.ID
+	return &gap, nil
+}
+
+// AnalyzeGapは目標スキルとスキル一覧のギャップを分析します（DBアクセスなし）。
+func AnalyzeGap(targets []domain.SkillTarget, skills []domain.Skill, names export.MasterNames) domain.SkillGap {
+	best := bestSkills(skills)
+	groups := map[string]*domain.SkillGapGroup{}
+	for _, t := range skillTypes {
+		groups[t] = &domain.SkillGapGroup{
+			Type:    t,
+			Missing: []domain.SkillGapItem{},
+			Below:   []domain.SkillGapItem{},
+			Met:     []domain.SkillGapItem{},
+			Surplus: []domain.SkillGapItem{},
+		}
+	}
+
+	var summary domain.SkillGapSummary
+	targeted := map[skillRef]bool{}
+	for _, t := range mergeTargets(targets) {
+		ref := skillRef{t.Type, t.MasterID}
+		targeted[ref] = true
+		g := groups[t.Type]
+		item := domain.SkillGapItem{
+			MasterID: t.MasterID,
+			Name:     names.Name(t.Type, t.MasterID),
+			MinYears: t.MinYears,
+			MinLevel: t.MinLevel,
+		}
+		summary.Targets++
+
+		sk, ok := best[ref]
+		if !ok {
+			item.YearsShortfall = t.MinYears
+			item.LevelShortfall = t.MinLevel.Ordinal()
+			g.Missing = append(g.Missing, item)
+			summary.Missing++
+			continue
+		}
+		item.ActualYears = sk.Years
+		item.ActualLevel = sk.Level
+		if sk.Years < t.MinYears {
+			item.YearsShortfall = t.MinYears - sk.Years
+		}
+		if t.MinLevel.IsValid() && !sk.Level.AtLeast(t.MinLevel) {
+			item.LevelShortfall = t.MinLevel.Ordinal() - sk.Level.Ordinal()
+		}
+		if item.YearsShortfall > 0 || item.LevelShortfall > 0 {
+			g.Below = append(g.Below, item)
+			summary.Below++
+		} else {
+			g.Met = append(g.Met, item)
+			summary.Met++
+		}
+	}
+
+	for ref, sk := range best {
+		g, ok := groups[ref.skillType]
+		if !ok || targeted[ref] {
+			continue
+		}
+		g.Surplus = append(g.Surplus, domain.SkillGapItem{
+			MasterID:    ref.masterID,
+			Name:        names.Name(ref.skillType, ref.masterID),
+			ActualYears: sk.Years,
+			ActualLevel: sk.Level,
+		})
+		summary.Surplus++
+	}
+
+	result := domain.SkillGap{Groups: make([]domain.SkillGapGroup, 0, len(skillTypes))}
+	for _, t := range skillTypes {
+		g := groups[t]
+		// 余剰スキルは年数の長い順 → マスタID順
+		sort.Slice(g.Surplus, func(i, j int) bool {
+			a, b := g.Surplus[i], g.Surplus[j]
+			if a.ActualYears != b.ActualYears {
+				return a.ActualYears > b.ActualYears
+			}
+			return a.MasterID < b.MasterID
+		})
+		result.Groups = append(result.Groups, *g)
+	}
+	if summary.Targets > 0 {
+		summary.Coverage = round1(100 * float64(summary.Met) / float64(summary.Targets))
+	}
+	result.Summary = summary
+	return result
+}
+
+// 同一スキルの目標を統合（最低年数・最低レベルとも厳しい方を採用、出現順は維持）
+func mergeTargets(targets []domain.SkillTarget) []domain.SkillTarget {
+	merged := make([]domain.SkillTarget, 0, len(targets))
+	index := map[skillRef]int{}
+	for _, t := range targets {
+		ref := skillRef{t.Type, t.MasterID}
+		i, ok := index[ref]
+		if !ok {
+			index[ref] = len(merged)
+			merged = append(merged, t)
+			continue
+		}
+		if t.MinYears > merged[i].MinYears {
+			merged[i].MinYears = t.MinYears
+		}
+		if t.MinLevel.Ordinal() > merged[i].MinLevel.Ordinal() {
+			merged[i].MinLevel = t.MinLevel
+		}
+	}
+	return merged
+}
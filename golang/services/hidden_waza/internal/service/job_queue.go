@@ -0,0 +1,134 @@
+// job_queue.go: バックグラウンドジョブの登録と管理（一覧・再実行・取り消し）
+//
+// 実行は[`JobWorker`](services/hidden_waza/internal/service/job_worker.go)（hidden_waza worker）が行います。
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DefaultJobMaxAttemptsはジョブの既定の最大試行回数
+const DefaultJobMaxAttempts = 5
+
+var (
+	ErrInvalidJob       = errors.New("invalid job")
+	ErrJobStateConflict = repository.ErrJobStateConflict
+)
+
+// EnqueueOptionsはジョブ登録時の任意設定です。
+type EnqueueOptions struct {
+	RunAt       time.Time // ゼロ値は即時
+	MaxAttempts int       // 0は既定値（DefaultJobMaxAttempts）
+	// 空でなければ同じキーの未完了ジョブがある間は登録しない（重複排除）
+	UniqueKey string
+}
+
+// JobQueueはジョブの登録と管理を提供します。
+type JobQueue struct {
+	repo *repository.JobRepository
+	now  func() time.Time
+}
+
+// NewJobQueueはJobQueueを生成します。
+func NewJobQueue(repo *repository.JobRepository) *JobQueue {
+	return &JobQueue{repo: repo, now: time.Now}
+}
+
+// Enqueueはジョブを登録します（payloadはJSONに変換）。
+// UniqueKeyが同じ未完了のジョブがあれば新たに登録せず、そのジョブを返します。
+func (q *JobQueue) Enqueue(jobType string, payload interface{}, opts EnqueueOptions) (*domain.Job, error) {
+	job, err := newJob(jobType, payload, opts, q.now())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := q.repo.Enqueue(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Listは条件に一致するジョブを新しい順に返します。
+func (q *JobQueue) List(f repository.JobFilter) ([]domain.Job, error) {
+	if f.Status != "" && !domain.IsValidJobStatus(f.Status) {
+		return nil, ErrInvalidJob
+	}
+	return q.repo.Find(f)
+}
+
+// Getはジョブを返します。
+func (q *JobQueue) Get(id uint) (*domain.Job, error) {
+	job, err := q.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// Statsは状態ごとのジョブ件数を返します。
+func (q *JobQueue) Stats() (map[string]int64, error) {
+	return q.repo.CountByStatus()
+}
+
+// Schedulesは定期実行スケジュールの一覧を返します。
+func (q *JobQueue) Schedules() ([]domain.JobSchedule, error) {
+	return q.repo.Schedules()
+}
+
+// Retryはデッドレター・取り消し済みのジョブを再実行します。
+func (q *JobQueue) Retry(id uint) (*domain.Job, error) {
+	if _, err := q.Get(id); err != nil {
+		return nil, err
+	}
+	if err := q.repo.Retry(id, q.now()); err != nil {
+		return nil, err
+	}
+	return q.Get(id)
+}
+
+// Cancelは実行待ちのジョブを取り消します。
+func (q *JobQueue) Cancel(id uint) (*domain.Job, error) {
+	if _, err := q.Get(id); err != nil {
+		return nil, err
+	}
+	if err := q.repo.Cancel(id, q.now()); err != nil {
+		return nil, err
+	}
+	return q.Get(id)
+}
+
+func newJob(jobType string, payload interface{}, opts EnqueueOptions, now time.Time) (*domain.Job, error) {
+	if jobType == "" {
+		return nil, ErrInvalidJob
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := &domain.Job{
+		Type:        jobType,
+		Payload:     data,
+		Status:      domain.JobPending,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       opts.RunAt,
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = DefaultJobMaxAttempts
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	if opts.UniqueKey != "" {
+		key := opts.UniqueKey
+		job.UniqueKey = &key
+	}
+	return job, nil
+}
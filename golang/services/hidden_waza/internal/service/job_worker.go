@@ -0,0 +1,234 @@
+/*
+job_worker.go
+
+バックグラウンドジョブのワーカー（hidden_waza worker で起動）。
+  - ジョブ種別ごとのハンドラをHandleで登録し、PollIntervalごとに実行待ちのジョブを取得して並列に実行
+  - 失敗したジョブは指数バックオフ（RetryBase×2^(試行回数-1)、上限RetryMax、±20%のゆらぎ）で再試行し、
+    MaxAttemptsに達するか ErrPermanentJob を返した場合はデッドレター（dead）にする
+  - Scheduleで登録したcron形式のスケジュールは、次回実行時刻を過ぎたらジョブを登録（同じ回は1回だけ）
+  - LockTimeoutを過ぎても実行中のままのジョブ（ワーカーの異常終了等）は実行待ちに戻す
+*/
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+)
+
+// ErrPermanentJobをラップしたエラーを返したジョブは再試行せずデッドレターにします。
+var ErrPermanentJob = errors.New("permanent job failure")
+
+// JobHandlerはジョブ1件を実行します（ctxはJobTimeoutでキャンセル）。
+type JobHandler func(ctx context.Context, job *domain.Job) error
+
+// JobWorkerOptionsはワーカーの設定です。
+type JobWorkerOptions struct {
+	Concurrency  int
+	PollInterval time.Duration
+	JobTimeout   time.Duration
+	LockTimeout  time.Duration // JobTimeoutより長くする
+	RetryBase    time.Duration
+	RetryMax     time.Duration
+}
+
+// DefaultJobWorkerOptionsは既定の設定を返します。
+func DefaultJobWorkerOptions() JobWorkerOptions {
+	return JobWorkerOptions{
+		Concurrency:  4,
+		PollInterval: time.Second,
+		JobTimeout:   5 * time.Minute,
+		LockTimeout:  15 * time.Minute,
+		RetryBase:    10 * time.Second,
+		RetryMax:     time.Hour,
+	}
+}
+
+type jobScheduleEntry struct {
+	schedule *domain.JobSchedule
+	cron     *CronSchedule
+}
+
+// JobWorkerはジョブを取得して実行します。
+type JobWorker struct {
+	repo      *repository.JobRepository
+	opts      JobWorkerOptions
+	id        string
+	handlers  map[string]JobHandler
+	schedules []jobScheduleEntry
+	now       func() time.Time
+}
+
+// NewJobWorkerはJobWorkerを生成します。
+func NewJobWorker(repo *repository.JobRepository, opts JobWorkerOptions) *JobWorker {
+	host, _ := os.Hostname()
+	return &JobWorker{
+		repo:     repo,
+		opts:     opts,
+		id:       fmt.Sprintf("%s-%d", host, os.Getpid()),
+		handlers: map[string]JobHandler{},
+		now:      time.Now,
+	}
+}
+
+// Handleはジョブ種別のハンドラを登録します（Run前に呼び出す）。
+func (w *JobWorker) Handle(jobType string, h JobHandler) {
+	w.handlers[jobType] = h
+}
+
+// Scheduleはcron形式の定期実行スケジュールを登録します（Run前に呼び出す）。
+func (w *JobWorker) Schedule(name, cron, jobType string, payload interface{}) error {
+	c, err := ParseCron(cron)
+	if err != nil {
+		return fmt.Errorf("schedule %s: %w", name, err)
+	}
+	if c.Next(w.now()).IsZero() {
+		return fmt.Errorf("schedule %s: %q never matches", name, cron)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	w.schedules = append(w.schedules, jobScheduleEntry{
+		schedule: &domain.JobSchedule{Name: name, Cron: cron, JobType: jobType, Payload: data},
+		cron:     c,
+	})
+	return nil
+}
+
+// RunはctxがキャンセルされるまでジョブとスケジュールをPollIntervalごとに処理します。
+// 停止時は実行中のジョブの完了を待ちます。
+func (w *JobWorker) Run(ctx context.Context) error {
+	if len(w.handlers) == 0 {
+		return errors.New("job worker: no handlers registered")
+	}
+	for _, e := range w.schedules {
+		e.schedule.NextRunAt = e.cron.Next(w.now())
+		if err := w.repo.UpsertSchedule(e.schedule); err != nil {
+			return err
+		}
+	}
+	types := make([]string, 0, len(w.handlers))
+	for t := range w.handlers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	log.Printf("job worker %s: started (types: %v)", w.id, types)
+
+	concurrency := max(1, w.opts.Concurrency)
+	// 同時実行数の上限（空きがある分だけ取得する）
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		w.maintain()
+		if free := concurrency - len(slots); free > 0 {
+			jobs, err := w.repo.Claim(w.id, types, w.now(), free)
+			if err != nil {
+				log.Printf("job worker: claim: %v", err)
+			}
+			for i := range jobs {
+				slots <- struct{}{}
+				wg.Add(1)
+				go func(job *domain.Job) {
+					defer func() { <-slots; wg.Done() }()
+					w.execute(job)
+				}(&jobs[i])
+			}
+		}
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			log.Printf("job worker %s: stopped", w.id)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// スケジュールの登録と、実行中のまま放置されたジョブの回収
+func (w *JobWorker) maintain() {
+	now := w.now()
+	err := w.repo.ClaimDueSchedules(now, func(tx *repository.JobRepository, s *domain.JobSchedule) (time.Time, error) {
+		c, err := ParseCron(s.Cron)
+		if err != nil {
+			// 別バージョンのワーカーが登録した不正な式は1日後に再確認
+			log.Printf("job worker: schedule %s: %v", s.Name, err)
+			return now.Add(24 * time.Hour), nil
+		}
+		// 同じ回のジョブは一意キーで重複を防ぐ（前回の分が未完了なら登録しない）
+		job, err := newJob(s.JobType, s.Payload, EnqueueOptions{UniqueKey: "schedule:" + s.Name}, now)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if _, err := tx.Enqueue(job); err != nil {
+			return time.Time{}, err
+		}
+		return c.Next(now), nil
+	})
+	if err != nil {
+		log.Printf("job worker: schedules: %v", err)
+	}
+	if n, err := w.repo.ReleaseStale(now.Add(-w.opts.LockTimeout)); err != nil {
+		log.Printf("job worker: release stale jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("job worker: released %d stale job(s)", n)
+	}
+}
+
+// ジョブ1件を実行して結果を記録
+func (w *JobWorker) execute(job *domain.Job) {
+	// 停止要求で中断しないよう、実行中のジョブはワーカーのctxとは別のタイムアウトで実行する
+	jobCtx, cancel := context.WithTimeout(context.Background(), w.opts.JobTimeout)
+	defer cancel()
+	err := w.safeRun(jobCtx, job)
+	now := w.now()
+	switch {
+	case err == nil:
+		err = w.repo.Complete(job.ID, now)
+	case errors.Is(err, ErrPermanentJob) || job.Attempts >= job.MaxAttempts:
+		log.Printf("job %d (%s): dead after %d attempt(s): %v", job.ID, job.Type, job.Attempts, err)
+		err = w.repo.Bury(job.ID, err.Error(), now)
+	default:
+		retryAt := now.Add(w.retryDelay(job.Attempts))
+		log.Printf("job %d (%s): attempt %d failed, retry at %s: %v", job.ID, job.Type, job.Attempts, retryAt.Format(time.RFC3339), err)
+		err = w.repo.RetryLater(job.ID, err.Error(), retryAt)
+	}
+	if err != nil {
+		log.Printf("job %d (%s): failed to record result: %v", job.ID, job.Type, err)
+	}
+}
+
+// ハンドラのpanicもジョブの失敗として扱う
+func (w *JobWorker) safeRun(ctx context.Context, job *domain.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return w.handlers[job.Type](ctx, job)
+}
+
+// 試行回数に応じた再試行までの間隔（±20%のゆらぎ）
+func (w *JobWorker) retryDelay(attempts int) time.Duration {
+	d := w.opts.RetryBase
+	for i := 1; i < attempts && d < w.opts.RetryMax; i++ {
+		d *= 2
+	}
+	if d > w.opts.RetryMax {
+		d = w.opts.RetryMax
+	}
+	jitter := time.Duration((rand.Float64()*0.4 - 0.2) * float64(d))
+	return d + jitter
+}
@@ -0,0 +1,211 @@
+/*
+link_check_service.go
+
+職歴のポートフォリオURLの定期確認（死活・リダイレクト・タイトル）。
+  - 確認結果はURLごとに1件保存し、同じURLを登録した職歴間で共有する
+  - 正常なURLはInterval（既定24時間）ごと、リンク切れのURLは連続失敗回数に応じた指数バックオフ
+    （BaseBackoff×2^(失敗回数-1)、上限MaxBackoff）で再確認。429/503のRetry-Afterはそれ以上待つ
+  - 同一ホストへのアクセスはHostInterval（既定2秒）に1回まで
+*/
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"golang.org/x/time/rate"
+)
+
+// LinkCheckOptionsはURL確認の間隔・並列数等の設定です。
+type LinkCheckOptions struct {
+	Interval     time.Duration // 正常なURLの再確認間隔
+	BaseBackoff  time.Duration // リンク切れのURLの再確認間隔（初回）
+	MaxBackoff   time.Duration // リンク切れのURLの再確認間隔の上限
+	BatchSize    int           // 1回の実行で確認する最大件数
+	Concurrency  int           // 同時に確認する件数
+	HostInterval time.Duration // 同一ホストへのアクセス間隔
+	Timeout      time.Duration // 1件あたりのタイムアウト
+	AllowPrivate bool          // プライベートアドレスへの接続を許可（テスト用）
+}
+
+// DefaultLinkCheckOptionsは既定の設定を返します。
+func DefaultLinkCheckOptions() LinkCheckOptions {
+	return LinkCheckOptions{
+		Interval:     24 * time.Hour,
+		BaseBackoff:  time.Hour,
+		MaxBackoff:   7 * 24 * time.Hour,
+		BatchSize:    100,
+		Concurrency:  4,
+		HostInterval: 2 * time.Second,
+		Timeout:      10 * time.Second,
+	}
+}
+
+// LinkCheckServiceはポートフォリオURLの確認と結果の参照を提供します。
+type LinkCheckService struct {
+	repo    *repository.LinkCheckRepository
+	checker *LinkChecker
+	opts    LinkCheckOptions
+	now     func() time.Time
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter // ホストごとのアクセス間隔
+}
+
+// NewLinkCheckServiceはLinkCheckServiceを生成します。
+func NewLinkCheckService(repo *repository.LinkCheckRepository, opts LinkCheckOptions) *LinkCheckService {
+	return &LinkCheckService{
+		repo:     repo,
+		checker:  NewLinkChecker(opts.Timeout, opts.AllowPrivate),
+		opts:     opts,
+		now:      time.Now,
+		limiters: map[string]*rate.Limiter{},
+	}
+}
+
+// RunOnceは確認時刻を過ぎたURLを確認し、確認した件数を返します。
+func (s *LinkCheckService) RunOnce(ctx context.Context) (int, error) {
+	if err := s.repo.SyncURLs(s.now()); err != nil {
+		return 0, err
+	}
+	due, err := s.repo.Due(s.now(), s.opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	jobs := make(chan *domain.LinkCheck)
+	var wg sync.WaitGroup
+	var saveMu sync.Mutex
+	var saveErr error
+	for i := 0; i < max(1, s.opts.Concurrency); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for check := range jobs {
+				if err := s.check(ctx, check); err != nil {
+					saveMu.Lock()
+					saveErr = err
+					saveMu.Unlock()
+				}
+			}
+		}()
+	}
+	checked := 0
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- &due[i]
+		checked++
+	}
+	close(jobs)
+	wg.Wait()
+	return checked, saveErr
+}
+
+// URL1件を確認して結果を保存
+func (s *LinkCheckService) check(ctx context.Context, check *domain.LinkCheck) error {
+	if err := s.limiter(check.URL).Wait(ctx); err != nil {
+		return nil
+	}
+	result := s.checker.Check(ctx, check.URL)
+	if ctx.Err() != nil {
+		// 停止による中断は記録しない
+		return nil
+	}
+	s.apply(check, result, s.now())
+	return s.repo.Save(check)
+}
+
+// 確認結果を反映し、次回の確認時刻を決める
+func (s *LinkCheckService) apply(check *domain.LinkCheck, result LinkCheckResult, now time.Time) {
+	check.StatusCode = result.StatusCode
+	check.RedirectURL = result.RedirectURL
+	check.FinalURL = result.FinalURL
+	check.Title = result.Title
+	check.Error = ""
+	if result.Err != nil {
+		check.Error = truncateRunes(result.Err.Error(), 255)
+	}
+	check.Broken = result.Broken()
+	check.CheckedAt = &now
+	if !check.Broken {
+		check.Failures = 0
+		check.NextCheckAt = now.Add(s.opts.Interval)
+		return
+	}
+	check.Failures++
+	wait := s.backoff(check.Failures)
+	if result.RetryAfter > wait {
+		wait = result.RetryAfter
+	}
+	check.NextCheckAt = now.Add(wait)
+}
+
+// 連続失敗回数に応じた再確認間隔
+func (s *LinkCheckService) backoff(failures int) time.Duration {
+	wait := s.opts.BaseBackoff
+	for i := 1; i < failures && wait < s.opts.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > s.opts.MaxBackoff {
+		wait = s.opts.MaxBackoff
+	}
+	return wait
+}
+
+// ホストごとのレートリミッタ
+func (s *LinkCheckService) limiter(raw string) *rate.Limiter {
+	host := raw
+	if u, err := url.Parse(strings.TrimSpace(raw)); err == nil {
+		host = strings.ToLower(u.Hostname())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.limiters[host]
+	if !ok {
+		l = rate.NewLimiter(rate.Every(s.opts.HostInterval), 1)
+		s.limiters[host] = l
+	}
+	return l
+}
+
+// ForURLsは指定URLの確認結果をURLごとに返します（未確認のURLは含みません）。
+func (s *LinkCheckService) ForURLs(urls []string) (map[string]domain.LinkCheck, error) {
+	checks, err := s.repo.FindByURLs(urls)
+	if err != nil {
+		return nil, err
+	}
+	byURL := make(map[string]domain.LinkCheck, len(checks))
+	for _, c := range checks {
+		if c.CheckedAt != nil {
+			byURL[c.URL] = c
+		}
+	}
+	return byURL, nil
+}
+
+// BrokenLinksはユーザーの職務経歴書のうちリンク切れのポートフォリオURLを持つ職歴を返します。
+func (s *LinkCheckService) BrokenLinks(userID uint) ([]domain.BrokenLink, error) {
+	links, err := s.repo.BrokenByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if links == nil {
+		links = []domain.BrokenLink{}
+	}
+	return links, nil
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
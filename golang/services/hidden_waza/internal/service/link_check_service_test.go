@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+)
+
+func newTestLinkCheckService(opts LinkCheckOptions) *LinkCheckService {
+	return NewLinkCheckService(nil, opts)
+}
+
+func TestLinkCheckBackoff(t *testing.T) {
+	opts := DefaultLinkCheckOptions()
+	opts.BaseBackoff = time.Hour
+	opts.MaxBackoff = 10 * time.Hour
+	s := newTestLinkCheckService(opts)
+	want := []time.Duration{time.Hour, 2 * time.Hour, 4 * time.Hour, 8 * time.Hour, 10 * time.Hour, 10 * time.Hour}
+	for i, w := range want {
+		if got := s.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+	// 失敗回数が大きくてもオーバーフローしない
+	if got := s.backoff(1000); got != opts.MaxBackoff {
+		t.Errorf("backoff(1000) = %v", got)
+	}
+}
+
+func TestLinkCheckApply(t *testing.T) {
+	opts := DefaultLinkCheckOptions()
+	s := newTestLinkCheckService(opts)
+	now := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	check := &domain.LinkCheck{URL: "https://example.com/"}
+
+	// 404: 連続失敗回数に応じて間隔が倍になる
+	notFound := LinkCheckResult{StatusCode: 404, FinalURL: "https://example.com/"}
+	s.apply(check, notFound, now)
+	if !check.Broken || check.Failures != 1 || !check.NextCheckAt.Equal(now.Add(opts.BaseBackoff)) {
+		t.Fatalf("after 1st 404: Broken = %v, Failures = %d, NextCheckAt = %v", check.Broken, check.Failures, check.NextCheckAt)
+	}
+	s.apply(check, notFound, now)
+	if check.Failures != 2 || !check.NextCheckAt.Equal(now.Add(2*opts.BaseBackoff)) {
+		t.Fatalf("after 2nd 404: Failures = %d, NextCheckAt = %v", check.Failures, check.NextCheckAt)
+	}
+
+	// 429: Retry-Afterがバックオフより長ければRetry-Afterまで待つ
+	s.apply(check, LinkCheckResult{StatusCode: 429, RetryAfter: 48 * time.Hour}, now)
+	if check.Failures != 3 || !check.NextCheckAt.Equal(now.Add(48*time.Hour)) {
+		t.Fatalf("after 429: Failures = %d, NextCheckAt = %v", check.Failures, check.NextCheckAt)
+	}
+	// Retry-Afterがバックオフより短ければバックオフ
+	s.apply(check, LinkCheckResult{StatusCode: 429, RetryAfter: time.Minute}, now)
+	if !check.NextCheckAt.Equal(now.Add(8 * opts.BaseBackoff)) {
+		t.Fatalf("after short 429: NextCheckAt = %v", check.NextCheckAt)
+	}
+
+	// 接続失敗はエラーを記録する
+	s.apply(check, LinkCheckResult{Err: errors.New("connection refused")}, now)
+	if check.Error != "connection refused" || check.Failures != 5 {
+		t.Fatalf("after error: Error = %q, Failures = %d", check.Error, check.Failures)
+	}
+
+	// 正常に戻れば失敗回数を戻し、通常の間隔で再確認
+	ok := LinkCheckResult{StatusCode: 200, RedirectURL: "https://example.com/a", FinalURL: "https://example.com/b", Title: "Portfolio"}
+	s.apply(check, ok, now)
+	if check.Broken || check.Failures != 0 || check.Error != "" || !check.NextCheckAt.Equal(now.Add(opts.Interval)) {
+		t.Fatalf("after 200: Broken = %v, Failures = %d, Error = %q, NextCheckAt = %v", check.Broken, check.Failures, check.Error, check.NextCheckAt)
+	}
+	if check.RedirectURL != ok.RedirectURL || check.FinalURL != ok.FinalURL || check.Title != ok.Title || check.StatusCode != 200 {
+		t.Errorf("result not applied: %+v", check)
+	}
+	if check.CheckedAt == nil || !check.CheckedAt.Equal(now) {
+		t.Errorf("CheckedAt = %v", check.CheckedAt)
+	}
+}
+
+func TestLinkCheckHostLimiter(t *testing.T) {
+	opts := DefaultLinkCheckOptions()
+	opts.HostInterval = 200 * time.Millisecond
+	s := newTestLinkCheckService(opts)
+
+	// 同じホスト（大文字小文字・パス・ポートの違いを含む）は同じリミッタ
+	a := s.limiter("https://Example.com/a")
+	if b := s.limiter("http://example.com:8080/b?x=1"); a != b {
+		t.Error("same host got different limiters")
+	}
+	if c := s.limiter("https://other.example.com/"); a == c {
+		t.Error("different hosts share a limiter")
+	}
+
+	ctx := context.Background()
+	start := time.Now()
+	if err := a.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("second access to the same host after %v, want >= %v", elapsed, opts.HostInterval)
+	}
+	// 別のホストは待たない
+	start = time.Now()
+	if err := s.limiter("https://third.example.com/").Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("first access to another host waited %v", elapsed)
+	}
+}
@@ -0,0 +1,191 @@
+// link_checker.go: ポートフォリオURLへのHTTPアクセス（ステータス・リダイレクト・タイトルの取得）
+//
+// ユーザーが登録したURLにサーバーからアクセスするため、既定ではプライベート・ループバック等の
+// アドレスへの接続を拒否します（名前解決後のIPで判定）。
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// リダイレクトの上限回数
+	maxLinkRedirects = 10
+	// <title>を探すために読む本文の上限
+	maxLinkBodyBytes   = 256 << 10
+	linkCheckUserAgent = "hidden-waza-linkcheck/1.0"
+)
+
+var (
+	errLinkScheme          = errors.New("unsupported url scheme")
+	errLinkTooManyRedirect = errors.New("too many redirects")
+	errLinkForbiddenAddr   = errors.New("destination address is not allowed")
+)
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// LinkCheckResultはURL1件の確認結果です。
+type LinkCheckResult struct {
+	StatusCode  int
+	RedirectURL string // 最初のリダイレクト先
+	FinalURL    string
+	Title       string
+	Err         error
+	// 429/503のRetry-After（次回確認までの最短間隔）
+	RetryAfter time.Duration
+}
+
+// Brokenは接続失敗またはステータス400以上かを返します。
+func (r LinkCheckResult) Broken() bool {
+	return r.Err != nil || r.StatusCode >= 400
+}
+
+// LinkCheckerはURLにGETでアクセスして確認します。
+type LinkChecker struct {
+	client *http.Client
+}
+
+// NewLinkCheckerはLinkCheckerを生成します（allowPrivateがtrueならプライベートアドレスへの接続も許可、テスト用）。
+func NewLinkChecker(timeout time.Duration, allowPrivate bool) *LinkChecker {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = denyPrivateAddr
+	}
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConnsPerHost:   2,
+	}
+	return &LinkChecker{client: &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxLinkRedirects {
+				return errLinkTooManyRedirect
+			}
+			return nil
+		},
+	}}
+}
+
+// Checkはrawで指定したURLを確認します。
+func (c *LinkChecker) Check(ctx context.Context, raw string) LinkCheckResult {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return LinkCheckResult{Err: err}
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return LinkCheckResult{Err: errLinkScheme}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return LinkCheckResult{Err: err}
+	}
+	req.Header.Set("User-Agent", linkCheckUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+
+	var result LinkCheckResult
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// リダイレクト中のエラーでも最後のレスポンスは取得できないため接続失敗として扱う
+		result.Err = unwrapURLError(err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	result.FinalURL = resp.Request.URL.String()
+	if first := firstRedirect(resp.Request); first != nil {
+		result.RedirectURL = first.URL.String()
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		result.RetryAfter = retryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	if isHTML(resp.Header.Get("Content-Type")) {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxLinkBodyBytes))
+		result.Title = pageTitle(body)
+	}
+	return result
+}
+
+// リダイレクトの連鎖の2番目のリクエスト（最初のリダイレクト先）
+func firstRedirect(last *http.Request) *http.Request {
+	var first *http.Request
+	for r := last; r.Response != nil; r = r.Response.Request {
+		first = r
+	}
+	return first
+}
+
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "text/html" || mediaType == "application/xhtml+xml")
+}
+
+// <title>の内容（実体参照を戻し、空白を詰めて255文字以内）
+func pageTitle(body []byte) string {
+	m := titlePattern.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	title := strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+	if !utf8.ValidString(title) {
+		title = strings.ToValidUTF8(title, "")
+	}
+	return truncateRunes(title, 255)
+}
+
+// Retry-After（秒数またはHTTP日付）
+func retryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// エラーメッセージからURL（クエリ等）を除く
+func unwrapURLError(err error) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		return uerr.Err
+	}
+	return err
+}
+
+// 接続先がプライベート・ループバック・リンクローカル等のアドレスなら拒否
+func denyPrivateAddr(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s", errLinkForbiddenAddr, host)
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%w: %s", errLinkForbiddenAddr, host)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLinkChecker() *LinkChecker {
+	return NewLinkChecker(5*time.Second, true)
+}
+
+func TestLinkCheckerRedirectAndTitle(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/middle", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/middle", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/portfolio?x=1", http.StatusFound)
+	})
+	mux.HandleFunc("/portfolio", func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != linkCheckUserAgent {
+			t.Errorf("User-Agent = %q", ua)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, "<html><head><TITLE lang=\"ja\">\n  山田 &amp; 作品集\n</TITLE></head></html>")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	res := newTestLinkChecker().Check(context.Background(), srv.URL+"/old")
+	if res.Err != nil {
+		t.Fatalf("Err = %v", res.Err)
+	}
+	if res.StatusCode != http.StatusOK || res.Broken() {
+		t.Errorf("StatusCode = %d, Broken = %v", res.StatusCode, res.Broken())
+	}
+	if want := srv.URL + "/middle"; res.RedirectURL != want {
+		t.Errorf("RedirectURL = %q, want %q", res.RedirectURL, want)
+	}
+	if want := srv.URL + "/portfolio?x=1"; res.FinalURL != want {
+		t.Errorf("FinalURL = %q, want %q", res.FinalURL, want)
+	}
+	if want := "山田 & 作品集"; res.Title != want {
+		t.Errorf("Title = %q, want %q", res.Title, want)
+	}
+}
+
+func TestLinkCheckerNoRedirect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// HTML以外の本文はタイトルを探さない
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "<title>plain</title>")
+	}))
+	defer srv.Close()
+
+	res := newTestLinkChecker().Check(context.Background(), srv.URL)
+	if res.RedirectURL != "" || res.FinalURL != srv.URL || res.Title != "" {
+		t.Errorf("RedirectURL = %q, FinalURL = %q, Title = %q", res.RedirectURL, res.FinalURL, res.Title)
+	}
+}
+
+func TestLinkCheckerTooManyRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	res := newTestLinkChecker().Check(context.Background(), srv.URL+"/")
+	if !errors.Is(res.Err, errLinkTooManyRedirect) || !res.Broken() {
+		t.Errorf("Err = %v, want %v", res.Err, errLinkTooManyRedirect)
+	}
+}
+
+func TestLinkCheckerErrorStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		want       time.Duration
+	}{
+		{"not found", http.StatusNotFound, "120", 0},
+		{"too many requests", http.StatusTooManyRequests, "120", 120 * time.Second},
+		{"unavailable", http.StatusServiceUnavailable, "30", 30 * time.Second},
+		{"http date", http.StatusTooManyRequests, time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), time.Hour},
+		{"invalid", http.StatusTooManyRequests, "soon", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", tt.retryAfter)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			res := newTestLinkChecker().Check(context.Background(), srv.URL)
+			if res.Err != nil || res.StatusCode != tt.status || !res.Broken() {
+				t.Fatalf("Err = %v, StatusCode = %d, Broken = %v", res.Err, res.StatusCode, res.Broken())
+			}
+			// HTTP日付は秒単位のため誤差を許容する
+			if d := res.RetryAfter - tt.want; d < -2*time.Second || d > 0 {
+				t.Errorf("RetryAfter = %v, want %v", res.RetryAfter, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkCheckerDeniesPrivateAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the server")
+	}))
+	defer srv.Close()
+
+	res := NewLinkChecker(5*time.Second, false).Check(context.Background(), srv.URL)
+	if !errors.Is(res.Err, errLinkForbiddenAddr) || !res.Broken() {
+		t.Errorf("Err = %v, want %v", res.Err, errLinkForbiddenAddr)
+	}
+
+	// 名前解決後のアドレスで判定する
+	if err := denyPrivateAddr("tcp", "93.184.216.34:80", nil); err != nil {
+		t.Errorf("public address denied: %v", err)
+	}
+	for _, addr := range []string{"127.0.0.1:80", "10.0.0.1:80", "192.168.1.1:443", "169.254.169.254:80", "[::1]:80", "[fe80::1]:80", "0.0.0.0:80"} {
+		if err := denyPrivateAddr("tcp", addr, nil); !errors.Is(err, errLinkForbiddenAddr) {
+			t.Errorf("denyPrivateAddr(%s) = %v", addr, err)
+		}
+	}
+}
+
+func TestLinkCheckerInvalidURL(t *testing.T) {
+	for _, raw := range []string{"ftp://example.com/", "javascript:alert(1)", "http://", "://"} {
+		if res := newTestLinkChecker().Check(context.Background(), raw); res.Err == nil {
+			t.Errorf("Check(%q) succeeded", raw)
+		}
+	}
+}
+
+func TestPageTitleTruncated(t *testing.T) {
+	title := pageTitle([]byte("<title>" + strings.Repeat("あ", 300) + "</title>"))
+	if n := len([]rune(title)); n != 255 {
+		t.Errorf("len = %d", n)
+	}
+}
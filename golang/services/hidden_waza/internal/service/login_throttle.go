@@ -0,0 +1,348 @@
+/*
+login_throttle.go
+
+ログインの総当たり対策。
+  - メールアドレスごと・IPアドレスごとに直近の一定期間（スライディングウィンドウ）の失敗回数を数え、
+    上限に達したらそのメールアドレス・IPアドレスのログインを一時的に禁止する
+  - 禁止時間は連続して禁止されるたびに倍になる（上限あり）。禁止の終了から24時間禁止されなければ最初の禁止時間に戻る
+  - 禁止・管理者による解除はlogin_lockout_eventsに記録する
+  - 失敗回数・禁止状態の保存先はLoginAttemptStore（メモリ: 単一インスタンス用 / DB: 複数インスタンス構成用）
+  - IPアドレスはc.RealIP()（e.IPExtractorにより接続元、または信用するプロキシのX-Real-IP。
+    クライアントが指定したX-Forwarded-Forで別のIPアドレスとして数えられることはない）
+
+メールアドレスの禁止は登録の有無に関わらず行うため、禁止の有無からアカウントの存在は分からない。
+*/
+package service
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/requohylla/hidden-waza/pkg/config"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+)
+
+// 禁止の終了からこの期間禁止されなければ、禁止時間を最初に戻す
+const loginLockLevelReset = 24 * time.Hour
+
+var ErrNothingToUnlock = errors.New("email or ip is required")
+
+// LoginLockedErrorはログインが一時的に禁止されている場合のエラーです。
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return "too many login attempts; try again later"
+}
+
+// Secondsは禁止が終わるまでの秒数（切り上げ）を返します（Retry-Afterヘッダー用）。
+func (e *LoginLockedError) Seconds() int {
+	return int((e.RetryAfter + time.Second - 1) / time.Second)
+}
+
+// LoginAttemptStoreはログインの失敗回数・禁止状態の保存先です。
+type LoginAttemptStore interface {
+	// AddFailureは失敗を記録し、since以降の失敗回数を返します。
+	AddFailure(key string, at, since time.Time) (int, error)
+	ClearFailures(key string) error
+	// GetLockは禁止状態を返します（なければnil）。
+	GetLock(key string) (*domain.LoginLock, error)
+	SaveLock(lock *domain.LoginLock) error
+	DeleteLock(key string) error
+}
+
+// LoginLockoutLogは禁止・解除の記録の保存先です（repository.LoginLockoutRepository）。
+type LoginLockoutLog interface {
+	Create(ev *domain.LoginLockoutEvent) error
+	List(key string, limit, offset int) ([]domain.LoginLockoutEvent, error)
+}
+
+// LoginThrottleOptionsはログイン試行の制限の設定です。
+type LoginThrottleOptions struct {
+	// 失敗回数を数える期間
+	Window             time.Duration
+	AccountMaxFailures int
+	IPMaxFailures      int
+	// 最初の禁止時間（連続して禁止されるたびに倍、MaxLockoutまで）
+	Lockout    time.Duration
+	MaxLockout time.Duration
+}
+
+// DefaultLoginThrottleOptionsは既定の設定（15分間にメールアドレスごと5回・IPアドレスごと20回の失敗で1分から最大1時間禁止）を返します。
+func DefaultLoginThrottleOptions() LoginThrottleOptions {
+	return LoginThrottleOptions{
+		Window:             15 * time.Minute,
+		AccountMaxFailures: 5,
+		IPMaxFailures:      20,
+		Lockout:            time.Minute,
+		MaxLockout:         time.Hour,
+	}
+}
+
+// LoginThrottleOptionsFromConfigは設定ファイルの値からログイン試行の制限の設定を返します（未指定の項目は既定値）。
+func LoginThrottleOptionsFromConfig(cfg *config.AuthConfig) LoginThrottleOptions {
+	opts := DefaultLoginThrottleOptions()
+	if cfg == nil {
+		return opts
+	}
+	lt := cfg.Auth.LoginThrottle
+	if lt.WindowMinutes > 0 {
+		opts.Window = time.Duration(lt.WindowMinutes) * time.Minute
+	}
+	if lt.AccountMaxFailures > 0 {
+		opts.AccountMaxFailures = lt.AccountMaxFailures
+	}
+	if lt.IPMaxFailures > 0 {
+		opts.IPMaxFailures = lt.IPMaxFailures
+	}
+	if lt.LockoutSeconds > 0 {
+		opts.Lockout = time.Duration(lt.LockoutSeconds) * time.Second
+	}
+	if lt.MaxLockoutMinutes > 0 {
+		opts.MaxLockout = time.Duration(lt.MaxLockoutMinutes) * time.Minute
+	}
+	return opts
+}
+
+// LoginThrottleはログイン試行の制限を提供します。
+type LoginThrottle struct {
+	store LoginAttemptStore
+	audit LoginLockoutLog
+	opts  LoginThrottleOptions
+	now   func() time.Time
+}
+
+// NewLoginThrottleはLoginThrottleを生成します。
+func NewLoginThrottle(store LoginAttemptStore, audit LoginLockoutLog, opts LoginThrottleOptions) *LoginThrottle {
+	return &LoginThrottle{store: store, audit: audit, opts: opts, now: time.Now}
+}
+
+// Checkはメールアドレス・IPアドレスのログインが禁止されていれば*LoginLockedErrorを返します（パスワードの照合前に呼び出す）。
+func (t *LoginThrottle) Check(email domain.Email, ip string) error {
+	now := t.now()
+	var wait time.Duration
+	for _, key := range []string{accountKey(email), ipKey(ip)} {
+		lock, err := t.store.GetLock(key)
+		if err != nil {
+			return err
+		}
+		if lock != nil && lock.LockedUntil.Sub(now) > wait {
+			wait = lock.LockedUntil.Sub(now)
+		}
+	}
+	if wait > 0 {
+		return &LoginLockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// Failureはログインの失敗を記録し、失敗回数が上限に達したメールアドレス・IPアドレスのログインを禁止します。
+func (t *LoginThrottle) Failure(email domain.Email, ip string) error {
+	now := t.now()
+	since := now.Add(-t.opts.Window)
+	limits := []struct {
+		key string
+		max int
+	}{
+		{accountKey(email), t.opts.AccountMaxFailures},
+		{ipKey(ip), t.opts.IPMaxFailures},
+	}
+	for _, l := range limits {
+		n, err := t.store.AddFailure(l.key, now, since)
+		if err != nil {
+			return err
+		}
+		if n >= l.max {
+			if err := t.lock(l.key, ip, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Successはログインの成功時にメールアドレスの失敗回数と禁止時間の段階を戻します（IPアドレスの失敗回数は戻さない）。
+func (t *LoginThrottle) Success(email domain.Email) error {
+	key := accountKey(email)
+	if err := t.store.ClearFailures(key); err != nil {
+		return err
+	}
+	return t.store.DeleteLock(key)
+}
+
+// Unlockは管理者がメールアドレス・IPアドレス（指定したもののみ）のログインの禁止を解除します。
+func (t *LoginThrottle) Unlock(email domain.Email, ip string, actorID uint) error {
+	var keys []string
+	if strings.TrimSpace(string(email)) != "" {
+		keys = append(keys, accountKey(email))
+	}
+	if strings.TrimSpace(ip) != "" {
+		keys = append(keys, ipKey(ip))
+	}
+	if len(keys) == 0 {
+		return ErrNothingToUnlock
+	}
+	for _, key := range keys {
+		if err := t.store.DeleteLock(key); err != nil {
+			return err
+		}
+		if err := t.store.ClearFailures(key); err != nil {
+			return err
+		}
+		if err := t.audit.Create(&domain.LoginLockoutEvent{
+			Action:    domain.LoginLockoutUnlocked,
+			Key:       key,
+			ActorID:   &actorID,
+			CreatedAt: t.now(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Eventsは禁止・解除の記録を新しい順に返します（email・ipを指定した場合はそのキーのみ）。
+func (t *LoginThrottle) Events(email domain.Email, ip string, limit, offset int) ([]domain.LoginLockoutEvent, error) {
+	key := ""
+	switch {
+	case strings.TrimSpace(string(email)) != "":
+		key = accountKey(email)
+	case strings.TrimSpace(ip) != "":
+		key = ipKey(ip)
+	}
+	return t.audit.List(key, limit, offset)
+}
+
+// キーのログインを禁止し、失敗回数を戻す（禁止時間は連続した禁止の回数に応じて倍）
+func (t *LoginThrottle) lock(key, ip string, now time.Time) error {
+	prev, err := t.store.GetLock(key)
+	if err != nil {
+		return err
+	}
+	level := 1
+	if prev != nil && now.Sub(prev.LockedUntil) < loginLockLevelReset {
+		level = prev.Level + 1
+	}
+	until := now.Add(t.lockoutDuration(level))
+	if err := t.store.SaveLock(&domain.LoginLock{Key: key, Level: level, LockedUntil: until, UpdatedAt: now}); err != nil {
+		return err
+	}
+	if err := t.store.ClearFailures(key); err != nil {
+		return err
+	}
+	log.Printf("login throttle: %s locked until %s (level %d, ip %s)", key, until.Format(time.RFC3339), level, ip)
+	return t.audit.Create(&domain.LoginLockoutEvent{
+		Action:      domain.LoginLockoutLocked,
+		Key:         key,
+		Level:       level,
+		LockedUntil: &until,
+		IP:          ip,
+		CreatedAt:   now,
+	})
+}
+
+// 段階levelの禁止時間（Lockout × 2^(level-1)、MaxLockoutまで）
+func (t *LoginThrottle) lockoutDuration(level int) time.Duration {
+	d := t.opts.Lockout
+	for i := 1; i < level && d < t.opts.MaxLockout; i++ {
+		d *= 2
+	}
+	if d > t.opts.MaxLockout {
+		d = t.opts.MaxLockout
+	}
+	return d
+}
+
+// キー（メールアドレスの最大長（254文字）を超える部分は切り捨て）
+func accountKey(email domain.Email) string {
+	return "account:" + truncateRunes(strings.ToLower(strings.TrimSpace(string(email))), 254)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + truncateRunes(strings.TrimSpace(ip), 45)
+}
+
+// MemoryLoginAttemptStoreはプロセス内のメモリに保存するLoginAttemptStoreです（単一インスタンス用。再起動で消える）。
+type MemoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+	locks    map[string]domain.LoginLock
+	adds     int
+}
+
+// NewMemoryLoginAttemptStoreはMemoryLoginAttemptStoreを生成します。
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{failures: map[string][]time.Time{}, locks: map[string]domain.LoginLock{}}
+}
+
+func (s *MemoryLoginAttemptStore) AddFailure(key string, at, since time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.adds++
+	if s.adds%1000 == 0 {
+		s.sweep(at)
+	}
+	kept := dropBefore(s.failures[key], since)
+	kept = append(kept, at)
+	s.failures[key] = kept
+	return len(kept), nil
+}
+
+func (s *MemoryLoginAttemptStore) ClearFailures(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failures, key)
+	return nil
+}
+
+func (s *MemoryLoginAttemptStore) GetLock(key string) (*domain.LoginLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, ok := s.locks[key]
+	if !ok {
+		return nil, nil
+	}
+	return &lock, nil
+}
+
+func (s *MemoryLoginAttemptStore) SaveLock(lock *domain.LoginLock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locks[lock.Key] = *lock
+	return nil
+}
+
+func (s *MemoryLoginAttemptStore) DeleteLock(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.locks, key)
+	return nil
+}
+
+// 古い失敗・禁止の段階を戻してよい禁止を削除（呼び出し側でロック済み）
+func (s *MemoryLoginAttemptStore) sweep(now time.Time) {
+	cutoff := now.Add(-loginLockLevelReset)
+	for key, times := range s.failures {
+		if len(times) == 0 || times[len(times)-1].Before(cutoff) {
+			delete(s.failures, key)
+		}
+	}
+	for key, lock := range s.locks {
+		if lock.LockedUntil.Before(cutoff) {
+			delete(s.locks, key)
+		}
+	}
+}
+
+// since以前の時刻を除く（timesは古い順）
+func dropBefore(times []time.Time, since time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(since) {
+		i++
+	}
+	return append(times[:0:0], times[i:]...)
+}
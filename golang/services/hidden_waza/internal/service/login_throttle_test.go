@@ -0,0 +1,234 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+)
+
+// テスト用の禁止・解除の記録
+type fakeLoginLockoutLog struct {
+	events []domain.LoginLockoutEvent
+}
+
+func (l *fakeLoginLockoutLog) Create(ev *domain.LoginLockoutEvent) error {
+	l.events = append(l.events, *ev)
+	return nil
+}
+
+func (l *fakeLoginLockoutLog) List(key string, limit, offset int) ([]domain.LoginLockoutEvent, error) {
+	var out []domain.LoginLockoutEvent
+	for i := len(l.events) - 1; i >= 0; i-- {
+		if key == "" || l.events[i].Key == key {
+			out = append(out, l.events[i])
+		}
+	}
+	return out, nil
+}
+
+// 時刻を進められるLoginThrottle（15分間にメールアドレスごと3回・IPアドレスごと5回、1分から最大4分）
+func newTestLoginThrottle() (*LoginThrottle, *fakeLoginLockoutLog, *time.Time) {
+	log := &fakeLoginLockoutLog{}
+	now := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	t := NewLoginThrottle(NewMemoryLoginAttemptStore(), log, LoginThrottleOptions{
+		Window:             15 * time.Minute,
+		AccountMaxFailures: 3,
+		IPMaxFailures:      5,
+		Lockout:            time.Minute,
+		MaxLockout:         4 * time.Minute,
+	})
+	t.now = func() time.Time { return now }
+	return t, log, &now
+}
+
+func fail(t *testing.T, th *LoginThrottle, email domain.Email, ip string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := th.Failure(email, ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// 禁止されていればRetryAfterを、されていなければ0を返す
+func lockedFor(t *testing.T, th *LoginThrottle, email domain.Email, ip string) time.Duration {
+	t.Helper()
+	err := th.Check(email, ip)
+	if err == nil {
+		return 0
+	}
+	var locked *LoginLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("Check: %v", err)
+	}
+	return locked.RetryAfter
+}
+
+func TestLoginThrottleLocksAfterMaxFailures(t *testing.T) {
+	th, log, _ := newTestLoginThrottle()
+	fail(t, th, "User@example.com", "203.0.113.1", 2)
+	if d := lockedFor(t, th, "user@example.com", "203.0.113.1"); d != 0 {
+		t.Fatalf("locked after 2 failures: %v", d)
+	}
+	fail(t, th, "user@example.com", "203.0.113.2", 1)
+	// メールアドレスは大文字・小文字を区別せず、別のIPアドレスからでも禁止される
+	if d := lockedFor(t, th, "USER@example.com", "198.51.100.9"); d != time.Minute {
+		t.Errorf("RetryAfter = %v, want 1m", d)
+	}
+	if d := lockedFor(t, th, "other@example.com", "203.0.113.1"); d != 0 {
+		t.Errorf("other account locked: %v", d)
+	}
+	if len(log.events) != 1 || log.events[0].Action != domain.LoginLockoutLocked ||
+		log.events[0].Key != "account:user@example.com" || log.events[0].Level != 1 || log.events[0].IP != "203.0.113.2" {
+		t.Errorf("events = %+v", log.events)
+	}
+}
+
+// ウィンドウより古い失敗は数えない
+func TestLoginThrottleWindowExpiry(t *testing.T) {
+	th, _, now := newTestLoginThrottle()
+	fail(t, th, "user@example.com", "203.0.113.1", 2)
+	*now = now.Add(15 * time.Minute)
+	fail(t, th, "user@example.com", "203.0.113.1", 2)
+	if d := lockedFor(t, th, "user@example.com", "203.0.113.1"); d != 0 {
+		t.Fatalf("locked with expired failures: %v", d)
+	}
+	*now = now.Add(time.Minute)
+	fail(t, th, "user@example.com", "203.0.113.1", 1)
+	if d := lockedFor(t, th, "user@example.com", "203.0.113.1"); d != time.Minute {
+		t.Errorf("RetryAfter = %v, want 1m", d)
+	}
+	// 禁止時間が過ぎればログインできる
+	*now = now.Add(time.Minute)
+	if d := lockedFor(t, th, "user@example.com", "203.0.113.1"); d != 0 {
+		t.Errorf("still locked after lockout: %v", d)
+	}
+}
+
+// 連続して禁止されるたびに禁止時間が倍になり、MaxLockoutで止まる
+func TestLoginThrottleLockoutDoubling(t *testing.T) {
+	th, log, now := newTestLoginThrottle()
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+		fail(t, th, "user@example.com", "203.0.113.1", 3)
+		if d := lockedFor(t, th, "user@example.com", "203.0.113.1"); d != want {
+			t.Fatalf("lock %d: RetryAfter = %v, want %v", i+1, d, want)
+		}
+		if ev := log.events[len(log.events)-1]; ev.Level != i+1 || !ev.LockedUntil.Equal(now.Add(want)) {
+			t.Errorf("lock %d: event = %+v", i+1, ev)
+		}
+		*now = now.Add(want)
+	}
+}
+
+// 禁止の終了から24時間禁止されなければ最初の禁止時間に戻る
+func TestLoginThrottleLevelReset(t *testing.T) {
+	th, _, now := newTestLoginThrottle()
+	fail(t, th, "user@example.com", "203.0.113.1", 3)
+	*now = now.Add(time.Minute)
+	fail(t, th, "user@example.com", "203.0.113.1", 3)
+	if d := lockedFor(t, th, "user@example.com", "203.0.113.1"); d != 2*time.Minute {
+		t.Fatalf("second lock: RetryAfter = %v, want 2m", d)
+	}
+
+	// 禁止の終了から24時間未満なら倍のまま
+	*now = now.Add(2*time.Minute + 24*time.Hour - time.Second)
+	fail(t, th, "user@example.com", "203.0.113.1", 3)
+	if d := lockedFor(t, th, "user@example.com", "203.0.113.1"); d != 4*time.Minute {
+		t.Fatalf("third lock: RetryAfter = %v, want 4m", d)
+	}
+
+	*now = now.Add(4*time.Minute + 24*time.Hour)
+	fail(t, th, "user@example.com", "203.0.113.1", 3)
+	if d := lockedFor(t, th, "user@example.com", "203.0.113.1"); d != time.Minute {
+		t.Errorf("lock after 24h: RetryAfter = %v, want 1m", d)
+	}
+}
+
+// IPアドレスの禁止は、別々のメールアドレスへの失敗でも数える
+func TestLoginThrottleIPLock(t *testing.T) {
+	th, _, _ := newTestLoginThrottle()
+	for _, email := range []domain.Email{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+		fail(t, th, email, "203.0.113.1", 1)
+	}
+	if d := lockedFor(t, th, "new@example.com", "203.0.113.1"); d != time.Minute {
+		t.Errorf("RetryAfter = %v, want 1m", d)
+	}
+	if d := lockedFor(t, th, "new@example.com", "203.0.113.2"); d != 0 {
+		t.Errorf("other ip locked: %v", d)
+	}
+}
+
+// ログインの成功はメールアドレスの失敗回数と禁止の段階を戻すが、IPアドレスの失敗回数は戻さない
+func TestLoginThrottleSuccess(t *testing.T) {
+	th, _, now := newTestLoginThrottle()
+	fail(t, th, "user@example.com", "203.0.113.1", 3)
+	*now = now.Add(time.Minute)
+	if err := th.Success("user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	fail(t, th, "user@example.com", "203.0.113.1", 1)
+	if d := lockedFor(t, th, "user@example.com", "198.51.100.1"); d != 0 {
+		t.Fatalf("account locked after success: %v", d)
+	}
+	fail(t, th, "user@example.com", "203.0.113.1", 1)
+	// IPアドレスは5回目の失敗で禁止される
+	if d := lockedFor(t, th, "other@example.com", "203.0.113.1"); d != time.Minute {
+		t.Errorf("ip RetryAfter = %v, want 1m", d)
+	}
+	// メールアドレスの段階は戻っている（1分）
+	fail(t, th, "user@example.com", "198.51.100.1", 1)
+	if d := lockedFor(t, th, "user@example.com", "198.51.100.1"); d != time.Minute {
+		t.Errorf("account RetryAfter = %v, want 1m", d)
+	}
+}
+
+func TestLoginThrottleUnlock(t *testing.T) {
+	th, log, _ := newTestLoginThrottle()
+	fail(t, th, "user@example.com", "203.0.113.1", 3)
+	fail(t, th, "other@example.com", "203.0.113.1", 2)
+	if d := lockedFor(t, th, "x@example.com", "203.0.113.1"); d == 0 {
+		t.Fatal("ip not locked")
+	}
+
+	if err := th.Unlock("", " ", 7); !errors.Is(err, ErrNothingToUnlock) {
+		t.Errorf("Unlock without email and ip: %v", err)
+	}
+
+	if err := th.Unlock("User@example.com", "", 7); err != nil {
+		t.Fatal(err)
+	}
+	// メールアドレスのみ解除され、IPアドレスは禁止されたまま
+	if d := lockedFor(t, th, "user@example.com", "198.51.100.1"); d != 0 {
+		t.Errorf("account still locked: %v", d)
+	}
+	if d := lockedFor(t, th, "x@example.com", "203.0.113.1"); d == 0 {
+		t.Error("ip unlocked too")
+	}
+	if err := th.Unlock("", "203.0.113.1", 7); err != nil {
+		t.Fatal(err)
+	}
+	if d := lockedFor(t, th, "x@example.com", "203.0.113.1"); d != 0 {
+		t.Errorf("ip still locked: %v", d)
+	}
+	// 失敗回数も戻る
+	fail(t, th, "user@example.com", "203.0.113.1", 2)
+	if d := lockedFor(t, th, "user@example.com", "203.0.113.1"); d != 0 {
+		t.Errorf("locked after unlock with 2 failures: %v", d)
+	}
+
+	events, err := th.Events("USER@example.com", "", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Action != domain.LoginLockoutUnlocked || events[1].Action != domain.LoginLockoutLocked {
+		t.Fatalf("events = %+v", events)
+	}
+	if events[0].ActorID == nil || *events[0].ActorID != 7 {
+		t.Errorf("unlock actor = %v", events[0].ActorID)
+	}
+	if n := len(log.events); n != 4 {
+		t.Errorf("%d events recorded, want 4 (2 locks, 2 unlocks)", n)
+	}
+}
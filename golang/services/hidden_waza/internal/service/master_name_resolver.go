@@ -0,0 +1,84 @@
+// master_name_resolver.go: スキルのマスタID→表示名の解決（languages/tools/osマスタ＋翻訳）
+package service
+
+import (
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/export"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+)
+
+// MasterNameResolverはスキル種別・マスタIDから表示名を解決します。
+type MasterNameResolver struct {
+	langRepo *repository.LanguageRepository
+	toolRepo *repository.ToolRepository
+	osRepo   *repository.OSRepository
+	trRepo   *repository.TranslationRepository
+}
+
+// NewMasterNameResolverはMasterNameResolverを生成します。
+func NewMasterNameResolver(
+	langRepo *repository.LanguageRepository,
+	toolRepo *repository.ToolRepository,
+	osRepo *repository.OSRepository,
+	trRepo *repository.TranslationRepository,
+) *MasterNameResolver {
+	return &MasterNameResolver{langRepo: langRepo, toolRepo: toolRepo, osRepo: osRepo, trRepo: trRepo}
+}
+
+// Resolveは種別ごとのマスタIDを名称に解決します（locの翻訳があれば翻訳名）。
+// マスタに存在しないIDは結果に含まれません。
+func (r *MasterNameResolver) Resolve(ids map[string][]uint, loc domain.Locale) (export.MasterNames, error) {
+	names := export.MasterNames{
+		"language": {},
+		"tool":     {},
+		"os":       {},
+	}
+
+	langs, err := r.langRepo.FindByIDs(ids["language"])
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range langs {
+		names["language"][l.ID] = l.Name
+	}
+	tools, err := r.toolRepo.FindByIDs(ids["tool"])
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tools {
+		names["tool"][t.ID] = t.Name
+	}
+	osList, err := r.osRepo.FindByIDs(ids["os"])
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range osList {
+		names["os"][o.ID] = o.Name
+	}
+
+	// 翻訳名で上書き（マスタに存在するIDのみ）
+	for skillType, typeIDs := range ids {
+		if _, ok := names[skillType]; !ok {
+			continue
+		}
+		translated, err := r.trRepo.FindMasterNames(skillType, typeIDs, loc)
+		if err != nil {
+			return nil, err
+		}
+		for id, name := range translated {
+			if _, ok := names[skillType][id]; ok {
+				names[skillType][id] = name
+			}
+		}
+	}
+	return names, nil
+}
+
+// ResolveSkillsはスキル一覧が参照するマスタ名称を解決します。
+func (r *MasterNameResolver) ResolveSkills(skills []domain.Skill, loc domain.Locale) (export.MasterNames, error) {
+	ids := map[string][]uint{}
+	for _, sk := range skills {
+		ids[sk.Type] = append(ids[sk.Type], sk.MasterID)
+	}
+	return r.Resolve(ids, loc)
+}
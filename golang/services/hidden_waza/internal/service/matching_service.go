@@ -0,0 +1,205 @@
+/*
+matching_service.go
+
+求人（JobPosting）と職務経歴書（Resume）のマッチングエンジン。
+- 求人→職務経歴書: 全職務経歴書を求人に対してスコアリングし順位付け
+- 職務経歴書→求人: 公開中（published）の全求人を職務経歴書に対してスコアリングし順位付け
+
+スコアリング規則:
+- 要件ごとのスコア（0〜1）は「年数の充足率」と「レベルの充足率」の平均（各充足率は上限1、最低年数0・最低レベル未指定なら1）
+- 要件の重み: 必須=2, 歓迎=1。総合スコア = 100 × Σ(重み×要件スコア) / Σ重み
+- 順位: 必須要件を全て満たすもの → 総合スコアの高い順 → ID順
+*/
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	requiredWeight  = 2.0
+	preferredWeight = 1.0
+
+	// DefaultMatchLimitはマッチング結果の既定件数
+	DefaultMatchLimit = 20
+)
+
+// MatchingServiceは求人と職務経歴書のマッチング処理を提供します。
+type MatchingService struct {
+	jobRepo    *repository.JobPostingRepository
+	resumeRepo *repository.ResumeRepository
+}
+
+// NewMatchingServiceはMatchingServiceを生成します。
+func NewMatchingService(jobRepo *repository.JobPostingRepository, resumeRepo *repository.ResumeRepository) *MatchingService {
+	return &MatchingService{jobRepo: jobRepo, resumeRepo: resumeRepo}
+}
+
+// MatchResumesは指定求人に対して全職務経歴書をスコアリングし、上位limit件を返します。
+func (s *MatchingService) MatchResumes(jobID uint, limit int) ([]domain.JobMatch, error) {
+	job, err := s.jobRepo.GetByID(jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	resumes, err := s.resumeRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]domain.JobMatch, 0, len(resumes))
+	for i := range resumes {
+		matches = append(matches, Match(job, &resumes[i]))
+	}
+	return rankMatches(matches, limit, func(m domain.JobMatch) uint { return m.ResumeID }), nil
+}
+
+// MatchJobsは指定職務経歴書に対して公開中の全求人をスコアリングし、上位limit件を返します。
+func (s *MatchingService) MatchJobs(resumeID uint, limit int) ([]domain.JobMatch, error) {
+	resume, err := s.resumeRepo.GetByID(resumeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	jobs, err := s.jobRepo.FindByStatus(domain.JobStatusPublished)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]domain.JobMatch, 0, len(jobs))
+	for i := range jobs {
+		matches = append(matches, Match(&jobs[i], resume))
+	}
+	return rankMatches(matches, limit, func(m domain.JobMatch) uint { return m.JobPostingID }), nil
+}
+
+// Matchは求人1件と職務経歴書1件のマッチング結果（スコアと要件ごとの判定理由）を算出します。
+func Match(job *domain.JobPosting, resume *domain.Resume) domain.JobMatch {
+	best := bestSkills(resume.Skills)
+	m := domain.JobMatch{
+		JobPostingID:  job.ID,
+		ResumeID:      resume.ID,
+		MeetsRequired: true,
+		Details:       make([]domain.RequirementMatch, 0, len(job.Requirements)),
+	}
+	var weighted, total float64
+	for _, req := range job.Requirements {
+		var skill *domain.Skill
+		if sk, ok := best[skillRef{req.Type, req.MasterID}]; ok {
+			skill = &sk
+		}
+		d := scoreRequirement(req, skill)
+		m.Details = append(m.Details, d)
+
+		w := preferredWeight
+		if req.Required {
+			w = requiredWeight
+			if d.Status != domain.MatchMet {
+				m.MeetsRequired = false
+			}
+		}
+		weighted += w * d.Score
+		total += w
+	}
+	if total > 0 {
+		m.Score = round1(100 * weighted / total)
+	} else {
+		// 要件のない求人は全員100点
+		m.Score = 100
+	}
+	return m
+}
+
+// スキルの参照キー（種別＋マスタID）
+type skillRef struct {
+	skillType string
+	masterID  uint
+}
+
+// 同一スキルが複数登録されている場合は年数・レベルそれぞれ最大の値を採用
+func bestSkills(skills []domain.Skill) map[skillRef]domain.Skill {
+	best := map[skillRef]domain.Skill{}
+	for _, s := range skills {
+		k := skillRef{s.Type, s.MasterID}
+		cur, ok := best[k]
+		if !ok {
+			best[k] = s
+			continue
+		}
+		if s.Years > cur.Years {
+			cur.Years = s.Years
+		}
+		if s.Level.Ordinal() > cur.Level.Ordinal() {
+			cur.Level = s.Level
+		}
+		best[k] = cur
+	}
+	return best
+}
+
+// 要件1件の判定
+func scoreRequirement(req domain.JobRequirement, skill *domain.Skill) domain.RequirementMatch {
+	d := domain.RequirementMatch{
+		Type:     req.Type,
+		MasterID: req.MasterID,
+		Required: req.Required,
+		MinYears: req.MinYears,
+		MinLevel: req.MinLevel,
+		Status:   domain.MatchMissing,
+	}
+	if skill == nil {
+		return d
+	}
+	d.ActualYears = skill.Years
+	d.ActualLevel = skill.Level
+
+	yearsRatio := 1.0
+	if req.MinYears > 0 {
+		yearsRatio = math.Min(1, float64(skill.Years)/float64(req.MinYears))
+	}
+	levelRatio := 1.0
+	if req.MinLevel.IsValid() {
+		levelRatio = math.Min(1, float64(skill.Level.Ordinal())/float64(req.MinLevel.Ordinal()))
+	}
+	d.Score = round2((yearsRatio + levelRatio) / 2)
+	if yearsRatio >= 1 && levelRatio >= 1 {
+		d.Status = domain.MatchMet
+	} else {
+		d.Status = domain.MatchPartial
+	}
+	return d
+}
+
+// 必須充足 → スコア降順 → ID昇順 で並べ替えて上位limit件
+func rankMatches(matches []domain.JobMatch, limit int, id func(domain.JobMatch) uint) []domain.JobMatch {
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.MeetsRequired != b.MeetsRequired {
+			return a.MeetsRequired
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return id(a) < id(b)
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
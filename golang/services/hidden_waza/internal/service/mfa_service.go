@@ -0,0 +1,402 @@
+/*
+mfa_service.go
+
+TOTP（RFC 6238）による2段階認証。
+  - 登録: 秘密鍵を発行し（登録用URI・QRコードを返す）、認証アプリのコードで確認したら有効にする
+    有効にした時点でリカバリーコード（1回のみ使用可能。DBにはSHA-256のハッシュのみ保存）を発行する
+  - ログイン: パスワードの照合後、JWTの代わりに短時間のみ有効なMFAトークン（署名付き）を返し、
+    MFAトークンとTOTPのコード（またはリカバリーコード）の組をJWTと交換する
+  - 管理者は権限ごとに2段階認証を必須にできる（必須の権限のユーザーは未登録ならMFAトークンで登録してからログインする）
+
+MFAトークンは<ユーザーID>.<JWTの世代>.<有効期限>.<署名>で、パスワードの再設定で無効になる。
+*/
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/requohylla/hidden-waza/pkg/config"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/qrcode"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/totp"
+	"gorm.io/gorm"
+)
+
+// 2段階認証の既定値
+const (
+	DefaultMFAIssuer       = "hidden-waza"
+	DefaultMFAChallengeTTL = 5 * time.Minute
+)
+
+const (
+	// 署名の対象に含める用途（メールアドレス確認のトークン等と流用できないようにする）
+	mfaChallengePurpose = "mfa_challenge"
+	// 前後に許容するTOTPの時間ステップ数（端末の時計のずれ）
+	mfaSkew = 1
+	// リカバリーコードの件数・文字数（xxxxx-xxxxxの形式）
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	// 紛らわしい文字（0/o、1/l/i）を除いた文字
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	// QRコードの1モジュールのピクセル数
+	mfaQRScale = 6
+)
+
+var (
+	ErrInvalidMFAChallenge = errors.New("invalid or expired mfa token")
+	ErrInvalidMFACode      = errors.New("invalid mfa code")
+	ErrMFAAlreadyEnabled   = errors.New("mfa is already enabled")
+	ErrMFANotEnabled       = errors.New("mfa is not enabled")
+	ErrMFANotEnrolling     = errors.New("mfa enrollment has not been started")
+	ErrMFARequiredByRole   = errors.New("mfa is required for your role")
+	ErrInvalidRole         = errors.New("invalid role")
+)
+
+// MFAOptionsは2段階認証の設定です。
+type MFAOptions struct {
+	// MFAトークンの署名鍵
+	SigningKey []byte
+	// 認証アプリに表示する発行者名
+	Issuer       string
+	ChallengeTTL time.Duration
+}
+
+// MFAOptionsFromConfigは設定ファイルの値から2段階認証の設定を返します（未指定の項目は既定値）。
+func MFAOptionsFromConfig(cfg *config.AuthConfig) MFAOptions {
+	opts := MFAOptions{Issuer: DefaultMFAIssuer, ChallengeTTL: DefaultMFAChallengeTTL}
+	if cfg == nil {
+		return opts
+	}
+	m := cfg.Auth.MFA
+	if m.Issuer != "" {
+		opts.Issuer = m.Issuer
+	}
+	if m.ChallengeTTLMinutes > 0 {
+		opts.ChallengeTTL = time.Duration(m.ChallengeTTLMinutes) * time.Minute
+	}
+	opts.SigningKey = []byte(cfg.Auth.TokenSigningKey)
+	return opts
+}
+
+// MFAEnrollmentは登録中の秘密鍵です（認証アプリに手入力するSecretか、URI・QRコードを読み取って登録する）。
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+	// URIのQRコード（PNG）
+	QRCode []byte
+}
+
+// MFAStatusはユーザーの2段階認証の状態です。
+type MFAStatus struct {
+	Enabled bool
+	// 権限により必須か
+	Required bool
+	// 未使用のリカバリーコードの件数
+	RecoveryCodesRemaining int
+}
+
+// MFAServiceは2段階認証を提供します。
+type MFAService struct {
+	repo  *repository.MFARepository
+	users *repository.UserRepository
+	opts  MFAOptions
+	now   func() time.Time
+}
+
+// NewMFAServiceはMFAServiceを生成します。
+func NewMFAService(repo *repository.MFARepository, users *repository.UserRepository, opts MFAOptions) *MFAService {
+	return &MFAService{repo: repo, users: users, opts: opts, now: time.Now}
+}
+
+// Requiredはログインに2段階目が必要か（2段階認証が有効、または権限により必須）を返します。
+func (s *MFAService) Required(user *domain.User) (bool, error) {
+	if user.MFAEnabled {
+		return true, nil
+	}
+	return s.repo.IsRoleRequired(user.Role)
+}
+
+// Statusはユーザーの2段階認証の状態を返します。
+func (s *MFAService) Status(userID uint) (*MFAStatus, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	required, err := s.repo.IsRoleRequired(user.Role)
+	if err != nil {
+		return nil, err
+	}
+	status := &MFAStatus{Enabled: user.MFAEnabled, Required: required}
+	if user.MFAEnabled {
+		if status.RecoveryCodesRemaining, err = s.repo.CountRecoveryCodes(userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// NewChallengeはパスワードを照合したユーザーのMFAトークンと有効期限を返します。
+func (s *MFAService) NewChallenge(user *domain.User) (string, time.Time) {
+	expiresAt := s.now().Add(s.opts.ChallengeTTL)
+	payload := fmt.Sprintf("%d.%d.%d", user.ID, user.TokenVersion, expiresAt.Unix())
+	return payload + "." + s.sign(payload), expiresAt
+}
+
+// ParseChallengeはMFAトークンを検証してユーザーを返します。
+// 署名が不正・期限切れ・発行後にパスワードが再設定された場合はErrInvalidMFAChallengeを返します。
+func (s *MFAService) ParseChallenge(token string) (*domain.User, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return nil, ErrInvalidMFAChallenge
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(s.sign(payload))) {
+		return nil, ErrInvalidMFAChallenge
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || userID == 0 {
+		return nil, ErrInvalidMFAChallenge
+	}
+	tv, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+	exp, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || !s.now().Before(time.Unix(exp, 0)) {
+		return nil, ErrInvalidMFAChallenge
+	}
+	user, err := s.users.GetByID(uint(userID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMFAChallenge
+		}
+		return nil, err
+	}
+	if user.TokenVersion != tv {
+		return nil, ErrInvalidMFAChallenge
+	}
+	return user, nil
+}
+
+// BeginEnrollmentは新しい秘密鍵を発行して登録を開始します（登録中の秘密鍵は置き換える）。
+// 2段階認証が有効ならErrMFAAlreadyEnabledを返します。
+func (s *MFAService) BeginEnrollment(userID uint) (*MFAEnrollment, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetPendingSecret(userID, secret); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+	return s.enrollment(user, secret)
+}
+
+// Enrollmentは登録中の秘密鍵（QRコードの再表示用）を返します。
+// 登録を開始していなければErrMFANotEnrolling、2段階認証が有効ならErrMFAAlreadyEnabledを返します。
+func (s *MFAService) Enrollment(userID uint) (*MFAEnrollment, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == nil {
+		return nil, ErrMFANotEnrolling
+	}
+	return s.enrollment(user, *user.MFASecret)
+}
+
+// ConfirmEnrollmentは認証アプリのコードで登録を確認して2段階認証を有効にし、リカバリーコードを返します。
+func (s *MFAService) ConfirmEnrollment(userID uint, code string) ([]string, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == nil {
+		return nil, ErrMFANotEnrolling
+	}
+	step, ok := totp.Validate(*user.MFASecret, code, s.now(), mfaSkew, user.MFALastStep)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	codes, records, err := s.newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Enable(userID, step, records); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verifyはログインの2段階目のコード（TOTPのコードまたはリカバリーコード）を検証します。
+// 一致しない・使用済みならErrInvalidMFACodeを返します。
+func (s *MFAService) Verify(user *domain.User, code string) error {
+	if !user.MFAEnabled || user.MFASecret == nil {
+		return ErrMFANotEnabled
+	}
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(*user.MFASecret, code, s.now(), mfaSkew, user.MFALastStep)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		used, err := s.repo.UseStep(user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+	used, err := s.repo.UseRecoveryCode(user.ID, hashToken(normalizeRecoveryCode(code)), s.now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// Disableはコードを確認して2段階認証を無効にします（権限により必須ならErrMFARequiredByRole）。
+func (s *MFAService) Disable(user *domain.User, code string) error {
+	required, err := s.repo.IsRoleRequired(user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequiredByRole
+	}
+	if err := s.Verify(user, code); err != nil {
+		return err
+	}
+	return s.repo.Disable(user.ID)
+}
+
+// RegenerateRecoveryCodesはコードを確認してリカバリーコードを再発行します（以前のリカバリーコードは無効）。
+func (s *MFAService) RegenerateRecoveryCodes(user *domain.User, code string) ([]string, error) {
+	if err := s.Verify(user, code); err != nil {
+		return nil, err
+	}
+	codes, records, err := s.newRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(user.ID, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RequiredRolesは2段階認証を必須とする権限の一覧を返します。
+func (s *MFAService) RequiredRoles() ([]domain.MFARequiredRole, error) {
+	return s.repo.RequiredRoles()
+}
+
+// RequireRoleは権限に2段階認証を必須とします（不明な権限ならErrInvalidRole）。
+func (s *MFAService) RequireRole(role string, actorID uint) error {
+	if !domain.IsValidRole(role) {
+		return ErrInvalidRole
+	}
+	return s.repo.AddRequiredRole(&domain.MFARequiredRole{Role: role, CreatedBy: &actorID, CreatedAt: s.now()})
+}
+
+// UnrequireRoleは権限の2段階認証を必須としません（設定されていなければErrNotFound）。
+func (s *MFAService) UnrequireRole(role string) error {
+	return notFound(s.repo.RemoveRequiredRole(role))
+}
+
+func (s *MFAService) enrollment(user *domain.User, secret string) (*MFAEnrollment, error) {
+	uri := totp.URI(s.opts.Issuer, string(user.Email), secret)
+	code, err := qrcode.Encode([]byte(uri))
+	if err != nil {
+		return nil, err
+	}
+	png, err := code.PNG(mfaQRScale)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAEnrollment{Secret: secret, URI: uri, QRCode: png}, nil
+}
+
+// リカバリーコード（表示用）とDBに保存するハッシュ
+func (s *MFAService) newRecoveryCodes(userID uint) ([]string, []domain.MFARecoveryCode, error) {
+	now := s.now()
+	codes := make([]string, recoveryCodeCount)
+	records := make([]domain.MFARecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes[i] = code
+		records[i] = domain.MFARecoveryCode{
+			UserID:    userID,
+			CodeHash:  hashToken(normalizeRecoveryCode(code)),
+			CreatedAt: now,
+		}
+	}
+	return codes, records, nil
+}
+
+func (s *MFAService) sign(payload string) string {
+	m := hmac.New(sha256.New, s.opts.SigningKey)
+	m.Write([]byte(mfaChallengePurpose + "." + payload))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+}
+
+// ランダムなリカバリーコード（xxxxx-xxxxx）
+func newRecoveryCode() (string, error) {
+	var b strings.Builder
+	size := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := 0; i < recoveryCodeLength; i++ {
+		if i == recoveryCodeLength/2 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// 入力されたリカバリーコードの表記の揺れ（大文字・区切り・空白）を除く
+func normalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+}
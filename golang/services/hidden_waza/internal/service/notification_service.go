@@ -0,0 +1,144 @@
+/*
+notification_service.go
+
+ユーザーへのメール通知（会員登録・メールアドレスの確認・パスワードの再設定・新しいIPアドレスからのログイン・職務経歴書のverifiedの変更）。
+  - メールはテンプレート（ja/en、ユーザーの言語）で作成し、送信はジョブ（mail.send）として非同期に行う
+    （SMTPサーバーが遅くてもリクエストを待たせず、失敗時はジョブキューの指数バックオフで再試行）
+  - verifiedの変更はアウトボックスのイベントから通知ジョブ（notify.resume_verified）を登録し、ワーカーでメールを作成する
+*/
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/mail"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ジョブ種別
+const (
+	MailSendJobType             = "mail.send"
+	ResumeVerifiedNotifyJobType = "notify.resume_verified"
+)
+
+// メール送信ジョブの最大試行回数（再試行の間隔は最大1時間のため、おおよそ半日まで再送）
+const mailMaxAttempts = 15
+
+// NotificationServiceはユーザーへのメール通知を提供します。
+type NotificationService struct {
+	queue     *JobQueue
+	templates *mail.Templates
+	users     *repository.UserRepository
+	now       func() time.Time
+}
+
+// NewNotificationServiceはNotificationServiceを生成します。
+func NewNotificationService(queue *JobQueue, templates *mail.Templates, users *repository.UserRepository) *NotificationService {
+	return &NotificationService{queue: queue, templates: templates, users: users, now: time.Now}
+}
+
+// SendEmailVerificationはメールアドレスの確認メール（urlは確認用のリンク）を送信します。
+func (s *NotificationService) SendEmailVerification(user *domain.User, url string, expiresAt time.Time) error {
+	return s.send(user, mail.TemplateVerifyEmail, map[string]interface{}{
+		"Username":  user.Username,
+		"URL":       url,
+		"ExpiresAt": expiresAt.Format("2006-01-02 15:04:05 MST"),
+	})
+}
+
+// SendWelcomeは会員登録完了（メールアドレスの確認後）のメールを送信します。
+func (s *NotificationService) SendWelcome(user *domain.User) error {
+	return s.send(user, mail.TemplateWelcome, map[string]interface{}{
+		"Username": user.Username,
+	})
+}
+
+// SendNewLoginAlertは新しいIPアドレスからのログインを通知します。
+func (s *NotificationService) SendNewLoginAlert(user *domain.User, ip, userAgent string, at time.Time) error {
+	return s.send(user, mail.TemplateNewLogin, map[string]interface{}{
+		"Username":  user.Username,
+		"IP":        ip,
+		"UserAgent": userAgent,
+		"Time":      at.Format("2006-01-02 15:04:05 MST"),
+	})
+}
+
+// SendPasswordResetはパスワード再設定のメール（urlは再設定用のリンク）を送信します。
+func (s *NotificationService) SendPasswordReset(user *domain.User, url string, expiresAt time.Time) error {
+	return s.send(user, mail.TemplatePasswordReset, map[string]interface{}{
+		"Username":  user.Username,
+		"URL":       url,
+		"ExpiresAt": expiresAt.Format("2006-01-02 15:04:05 MST"),
+	})
+}
+
+// ResumeVerifiedJobsはverifiedの変更イベントの通知ジョブを返します（WebhookService.OnEventに登録する）。
+func (s *NotificationService) ResumeVerifiedJobs(ev *domain.OutboxEvent) ([]*domain.Job, error) {
+	if ev.Type != domain.EventResumeVerifiedChanged {
+		return nil, nil
+	}
+	job, err := newJob(ResumeVerifiedNotifyJobType, ev.Data, EnqueueOptions{MaxAttempts: mailMaxAttempts}, s.now())
+	if err != nil {
+		return nil, err
+	}
+	return []*domain.Job{job}, nil
+}
+
+// HandleResumeVerifiedはnotify.resume_verifiedジョブのハンドラです。
+func (s *NotificationService) HandleResumeVerified(ctx context.Context, job *domain.Job) error {
+	var data domain.ResumeEventData
+	if err := json.Unmarshal(job.Payload, &data); err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanentJob, err)
+	}
+	user, err := s.users.GetByID(data.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// ユーザーが削除済み
+			return nil
+		}
+		return err
+	}
+	return s.send(user, mail.TemplateResumeVerified, map[string]interface{}{
+		"Username": user.Username,
+		"Title":    data.Title,
+		"Verified": data.Verified,
+	})
+}
+
+// ユーザーの言語でメールを作成して送信ジョブを登録
+func (s *NotificationService) send(user *domain.User, template string, data interface{}) error {
+	loc := user.Locale
+	if !loc.IsValid() {
+		loc = domain.DefaultLocale
+	}
+	msg, err := s.templates.Render(template, loc, string(user.Email), data)
+	if err != nil {
+		return err
+	}
+	_, err = s.queue.Enqueue(MailSendJobType, msg, EnqueueOptions{MaxAttempts: mailMaxAttempts})
+	return err
+}
+
+// NewMailJobHandlerはmail.sendジョブのハンドラを返します（ワーカーで登録）。
+// 不正なアドレス・SMTPの5xx応答は再試行しません。
+func NewMailJobHandler(m mail.Mailer) JobHandler {
+	return func(ctx context.Context, job *domain.Job) error {
+		var msg mail.Message
+		if err := json.Unmarshal(job.Payload, &msg); err != nil {
+			return fmt.Errorf("%w: %v", ErrPermanentJob, err)
+		}
+		if err := m.Send(ctx, &msg); err != nil {
+			if mail.IsPermanent(err) {
+				return fmt.Errorf("%w: %v", ErrPermanentJob, err)
+			}
+			return err
+		}
+		return nil
+	}
+}
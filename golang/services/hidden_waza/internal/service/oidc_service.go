@@ -0,0 +1,345 @@
+/*
+oidc_service.go
+
+外部IdP（OpenID Connect）でのログイン（認可コードフロー + PKCE）。複数のIdPを設定できる。
+  - 認可リクエストのstate（ハッシュ）・nonce・code_verifierはDBに保存し、コールバックで1回のみ使用する
+  - IdPのアカウント（IdP名 + sub）とユーザーの紐付け
+    1. 紐付け済みならそのユーザーでログイン
+    2. 未紐付けで、IdPが確認済みとするメールアドレスのユーザーがいれば紐付けてログイン
+    （ユーザー側のメールアドレスが未確認なら紐付けない。他人が先に登録したアカウントを乗っ取られないように）
+    3. 該当するユーザーがいなければユーザーを作成してログイン（パスワードは設定しない。必要ならパスワードの再設定で設定する）
+  - ログイン中のユーザーは明示的に紐付けを開始でき、その場合はメールアドレスに関係なく紐付ける
+    紐付けのコールバックは開始したユーザーの認証が必要（他人に紐付けのURLを踏ませて、その人のIdPのアカウントを紐付けられないように）
+*/
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/requohylla/hidden-waza/pkg/config"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/oidc"
+	"gorm.io/gorm"
+)
+
+// 認可リクエストの有効期限（IdPでのログインにかかる時間）
+const oidcAuthRequestTTL = 10 * time.Minute
+
+var (
+	ErrUnknownOIDCProvider = errors.New("unknown identity provider")
+	ErrInvalidOIDCState    = errors.New("invalid or expired oidc state")
+	ErrOIDCLoginFailed     = errors.New("oidc login failed")
+	// IdPのディスカバリーに失敗した
+	ErrOIDCProviderUnavailable = errors.New("identity provider unavailable")
+	// IdPのメールアドレスが未確認（メールアドレスでの紐付け・ユーザーの作成ができない）
+	ErrOIDCEmailNotVerified = errors.New("email address is not verified by the identity provider")
+	// 同じメールアドレスの未確認のユーザーがいる（ログインしてから明示的に紐付ける）
+	ErrOIDCEmailConflict = errors.New("an account with this email exists; log in and link the identity provider")
+	// IdPのアカウントが他のユーザーに紐付け済み
+	ErrOIDCIdentityLinked = errors.New("identity is already linked to another account")
+)
+
+// OIDCProviderOptionsは1つのIdPの設定です。
+type OIDCProviderOptions struct {
+	Name        string
+	DisplayName string
+	OIDC        oidc.Config
+	// IdPがemail_verifiedを返さない場合に、メールアドレスを確認済みとして扱う
+	TrustEmail bool
+}
+
+// OIDCProvidersFromConfigは設定ファイルの値からIdPの設定を返します（cfgがnilなら空）。
+func OIDCProvidersFromConfig(cfg *config.OIDCConfig) ([]OIDCProviderOptions, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	seen := map[string]bool{}
+	providers := make([]OIDCProviderOptions, 0, len(cfg.OIDC.Providers))
+	for i, p := range cfg.OIDC.Providers {
+		switch {
+		case p.Name == "" || strings.ContainsAny(p.Name, "/?#"):
+			return nil, fmt.Errorf("oidc.providers[%d]: invalid name %q", i, p.Name)
+		case seen[p.Name]:
+			return nil, fmt.Errorf("oidc.providers[%d]: duplicate name %q", i, p.Name)
+		case p.Issuer == "" || p.ClientID == "" || p.RedirectURL == "":
+			return nil, fmt.Errorf("oidc.providers[%d]: issuer, client_id and redirect_url are required", i)
+		}
+		seen[p.Name] = true
+		displayName := p.DisplayName
+		if displayName == "" {
+			displayName = p.Name
+		}
+		providers = append(providers, OIDCProviderOptions{
+			Name:        p.Name,
+			DisplayName: displayName,
+			OIDC: oidc.Config{
+				Issuer:       p.Issuer,
+				ClientID:     p.ClientID,
+				ClientSecret: p.ClientSecret,
+				RedirectURL:  p.RedirectURL,
+				Scopes:       p.Scopes,
+			},
+			TrustEmail: p.TrustEmail,
+		})
+	}
+	return providers, nil
+}
+
+// OIDCProviderInfoはログイン画面に表示するIdPです。
+type OIDCProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OIDCResultはコールバックの結果です。
+type OIDCResult struct {
+	User     *domain.User
+	Identity *domain.UserIdentity
+	// ログイン中のユーザーが開始した紐付け（ログインではない）
+	Linked bool
+	// IdPでの初回ログインでユーザーを作成した
+	Created bool
+}
+
+type oidcProvider struct {
+	opts   OIDCProviderOptions
+	client *oidc.Provider
+}
+
+// OIDCStoreは認可リクエスト・IdPのアカウントの紐付けの保存先です。
+type OIDCStore interface {
+	CreateAuthRequest(req *domain.OIDCAuthRequest) error
+	// ConsumeAuthRequestは未使用・期限内の認可リクエストを使用済みにして返します（なければgorm.ErrRecordNotFound）。
+	ConsumeAuthRequest(provider, stateHash string, now time.Time) (*domain.OIDCAuthRequest, error)
+	// FindIdentityは紐付けを返します（なければgorm.ErrRecordNotFound）。
+	FindIdentity(provider, subject string) (*domain.UserIdentity, error)
+	ListIdentities(userID uint) ([]domain.UserIdentity, error)
+	CreateIdentity(identity *domain.UserIdentity) error
+	CreateUserWithIdentity(user *domain.User, identity *domain.UserIdentity) error
+	TouchIdentity(id uint, email string, now time.Time) error
+	DeleteIdentity(userID, id uint) error
+}
+
+// OIDCUsersは紐付けの対象のユーザーの取得元です（見つからなければgorm.ErrRecordNotFound）。
+type OIDCUsers interface {
+	GetByID(id uint) (*domain.User, error)
+	FindByEmail(email domain.Email) (*domain.User, error)
+}
+
+// OIDCServiceは外部IdPでのログインを提供します。
+type OIDCService struct {
+	repo      OIDCStore
+	users     OIDCUsers
+	providers map[string]*oidcProvider
+	order     []string
+	now       func() time.Time
+}
+
+// NewOIDCServiceはOIDCServiceを生成します（httpClientがnilなら既定のクライアントでIdPに接続）。
+func NewOIDCService(repo OIDCStore, users OIDCUsers, providers []OIDCProviderOptions, httpClient *http.Client) *OIDCService {
+	s := &OIDCService{repo: repo, users: users, providers: map[string]*oidcProvider{}, now: time.Now}
+	for _, p := range providers {
+		s.providers[p.Name] = &oidcProvider{opts: p, client: oidc.NewProvider(p.OIDC, httpClient)}
+		s.order = append(s.order, p.Name)
+	}
+	return s
+}
+
+// Providersは設定したIdPの一覧を返します（設定ファイルの順）。
+func (s *OIDCService) Providers() []OIDCProviderInfo {
+	infos := make([]OIDCProviderInfo, 0, len(s.order))
+	for _, name := range s.order {
+		infos = append(infos, OIDCProviderInfo{Name: name, DisplayName: s.providers[name].opts.DisplayName})
+	}
+	return infos
+}
+
+// AuthorizeはIdPの認可リクエストのURLを返します。
+// linkUserIDを指定した場合はログインではなく、そのユーザーへの紐付けになります。
+func (s *OIDCService) Authorize(ctx context.Context, provider string, linkUserID *uint) (string, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", ErrUnknownOIDCProvider
+	}
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", err
+	}
+	authURL, err := p.client.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		log.Printf("oidc %s: %v", provider, err)
+		return "", ErrOIDCProviderUnavailable
+	}
+	now := s.now()
+	if err := s.repo.CreateAuthRequest(&domain.OIDCAuthRequest{
+		StateHash:    hashToken(state),
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    now.Add(oidcAuthRequestTTL),
+		CreatedAt:    now,
+	}); err != nil {
+		return "", err
+	}
+	return authURL, nil
+}
+
+// CallbackはIdPからのリダイレクト（認可コード・state）を処理し、ログインするユーザー（または紐付けの結果）を返します。
+// callerIDはコールバックを呼び出した認証済みユーザー（未認証ならnil）で、紐付けの場合は開始したユーザーと一致しなければなりません。
+func (s *OIDCService) Callback(ctx context.Context, provider, state, code string, callerID *uint) (*OIDCResult, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+	now := s.now()
+	req, err := s.repo.ConsumeAuthRequest(provider, hashToken(state), now)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, err
+	}
+	if req.LinkUserID != nil && (callerID == nil || *callerID != *req.LinkUserID) {
+		return nil, ErrInvalidOIDCState
+	}
+	claims, err := p.client.Exchange(ctx, code, req.CodeVerifier, req.Nonce)
+	if err != nil {
+		log.Printf("oidc %s: %v", provider, err)
+		return nil, ErrOIDCLoginFailed
+	}
+
+	identity, err := s.repo.FindIdentity(provider, claims.Subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if req.LinkUserID != nil {
+		return s.link(*req.LinkUserID, identity, provider, claims, now)
+	}
+	if identity != nil {
+		if err := s.repo.TouchIdentity(identity.ID, claims.Email, now); err != nil {
+			return nil, err
+		}
+		user, err := s.users.GetByID(identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return &OIDCResult{User: user, Identity: identity}, nil
+	}
+
+	// 未紐付け: IdPが確認済みとするメールアドレスで紐付け・ユーザーを作成
+	email := domain.Email(strings.TrimSpace(claims.Email))
+	verified := claims.EmailVerified || (p.opts.TrustEmail && claims.RawEmailVerified == nil)
+	if !email.IsValid() || !verified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+	identity = &domain.UserIdentity{
+		Provider:    provider,
+		Subject:     claims.Subject,
+		Email:       string(email),
+		LastLoginAt: &now,
+		CreatedAt:   now,
+	}
+	user, err := s.users.FindByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if user != nil {
+		if !user.IsEmailVerified() {
+			return nil, ErrOIDCEmailConflict
+		}
+		identity.UserID = user.ID
+		if err := s.repo.CreateIdentity(identity); err != nil {
+			return nil, err
+		}
+		return &OIDCResult{User: user, Identity: identity}, nil
+	}
+	user, err = newOIDCUser(claims, email, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateUserWithIdentity(user, identity); err != nil {
+		return nil, err
+	}
+	return &OIDCResult{User: user, Identity: identity, Created: true}, nil
+}
+
+// Identitiesはユーザーに紐付けたIdPのアカウントを返します。
+func (s *OIDCService) Identities(userID uint) ([]domain.UserIdentity, error) {
+	return s.repo.ListIdentities(userID)
+}
+
+// Unlinkはユーザーに紐付けたIdPのアカウントの紐付けを解除します（該当するものがなければErrNotFound）。
+func (s *OIDCService) Unlink(userID, identityID uint) error {
+	return notFound(s.repo.DeleteIdentity(userID, identityID))
+}
+
+// ログイン中のユーザーが開始した紐付け（紐付け済みなら何もしない）
+func (s *OIDCService) link(userID uint, identity *domain.UserIdentity, provider string, claims *oidc.Claims, now time.Time) (*OIDCResult, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if identity != nil {
+		if identity.UserID != userID {
+			return nil, ErrOIDCIdentityLinked
+		}
+		return &OIDCResult{User: user, Identity: identity, Linked: true}, nil
+	}
+	identity = &domain.UserIdentity{
+		UserID:    userID,
+		Provider:  provider,
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateIdentity(identity); err != nil {
+		return nil, err
+	}
+	return &OIDCResult{User: user, Identity: identity, Linked: true}, nil
+}
+
+// IdPでの初回ログインで作成するユーザー（パスワードはランダムな値。メールアドレスは確認済み）
+func newOIDCUser(claims *oidc.Claims, email domain.Email, now time.Time) (*domain.User, error) {
+	password, err := oidc.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := domain.NewPasswordHash(password)
+	if err != nil {
+		return nil, err
+	}
+	username := claims.Name
+	if username == "" {
+		username = claims.PreferredUsername
+	}
+	if username == "" {
+		username, _, _ = strings.Cut(string(email), "@")
+	}
+	nowStr := now.Format("2006-01-02 15:04:05")
+	return &domain.User{
+		Username:        truncateRunes(username, 255),
+		Email:           email,
+		PasswordHash:    hash,
+		Role:            domain.RoleUser,
+		Locale:          domain.DefaultLocale,
+		EmailStatus:     domain.EmailVerified,
+		EmailVerifiedAt: &now,
+		CreatedAt:       nowStr,
+		UpdatedAt:       nowStr,
+	}, nil
+}
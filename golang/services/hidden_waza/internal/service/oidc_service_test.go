@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/oidc"
+	"gorm.io/gorm"
+)
+
+// テスト用のIdP（トークンエンドポイントはclaimsに署名したIDトークンを返す）
+type fakeIdP struct {
+	server *httptest.Server
+	key    *ecdsa.PrivateKey
+
+	mu     sync.Mutex
+	claims jwt.MapClaims
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &fakeIdP{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "EC", "kid": "k1", "crv": "P-256",
+			"x": base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			"y": base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, idp.claims)
+		idp.mu.Unlock()
+		token.Header["kid"] = "k1"
+		s, err := token.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "a", "token_type": "Bearer", "id_token": s})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// setClaimsは次のトークンリクエストで返すIDトークンのクレームを設定する（nilの値はクレームを削除する）
+func (idp *fakeIdP) setClaims(nonce string, override map[string]interface{}) {
+	c := jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"sub":            "sub-1",
+		"aud":            "client-1",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          nonce,
+		"email":          "taro@example.com",
+		"email_verified": true,
+		"name":           "Taro",
+	}
+	for k, v := range override {
+		if v == nil {
+			delete(c, k)
+		} else {
+			c[k] = v
+		}
+	}
+	idp.mu.Lock()
+	idp.claims = c
+	idp.mu.Unlock()
+}
+
+type fakeOIDCStore struct {
+	requests   map[string]*domain.OIDCAuthRequest
+	identities []*domain.UserIdentity
+	users      *fakeOIDCUsers
+	touched    []uint
+}
+
+func (s *fakeOIDCStore) CreateAuthRequest(req *domain.OIDCAuthRequest) error {
+	s.requests[req.StateHash] = req
+	return nil
+}
+
+func (s *fakeOIDCStore) ConsumeAuthRequest(provider, stateHash string, now time.Time) (*domain.OIDCAuthRequest, error) {
+	req, ok := s.requests[stateHash]
+	if !ok || req.Provider != provider || req.UsedAt != nil || !req.ExpiresAt.After(now) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	req.UsedAt = &now
+	return req, nil
+}
+
+func (s *fakeOIDCStore) FindIdentity(provider, subject string) (*domain.UserIdentity, error) {
+	for _, identity := range s.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *fakeOIDCStore) ListIdentities(userID uint) ([]domain.UserIdentity, error) {
+	var list []domain.UserIdentity
+	for _, identity := range s.identities {
+		if identity.UserID == userID {
+			list = append(list, *identity)
+		}
+	}
+	return list, nil
+}
+
+func (s *fakeOIDCStore) CreateIdentity(identity *domain.UserIdentity) error {
+	identity.ID = uint(len(s.identities) + 1)
+	s.identities = append(s.identities, identity)
+	return nil
+}
+
+func (s *fakeOIDCStore) CreateUserWithIdentity(user *domain.User, identity *domain.UserIdentity) error {
+	s.users.add(user)
+	identity.UserID = user.ID
+	return s.CreateIdentity(identity)
+}
+
+func (s *fakeOIDCStore) TouchIdentity(id uint, email string, now time.Time) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+func (s *fakeOIDCStore) DeleteIdentity(userID, id uint) error {
+	for i, identity := range s.identities {
+		if identity.ID == id && identity.UserID == userID {
+			s.identities = append(s.identities[:i], s.identities[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+type fakeOIDCUsers struct {
+	users []*domain.User
+}
+
+func (u *fakeOIDCUsers) add(user *domain.User) {
+	user.ID = uint(len(u.users) + 1)
+	u.users = append(u.users, user)
+}
+
+func (u *fakeOIDCUsers) GetByID(id uint) (*domain.User, error) {
+	for _, user := range u.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (u *fakeOIDCUsers) FindByEmail(email domain.Email) (*domain.User, error) {
+	for _, user := range u.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type oidcTest struct {
+	t     *testing.T
+	idp   *fakeIdP
+	store *fakeOIDCStore
+	users *fakeOIDCUsers
+	svc   *OIDCService
+}
+
+func newOIDCTest(t *testing.T, trustEmail bool) *oidcTest {
+	idp := newFakeIdP(t)
+	users := &fakeOIDCUsers{}
+	// 1: 確認済み、2: 未確認
+	users.add(&domain.User{Username: "hanako", Email: "hanako@example.com", EmailStatus: domain.EmailVerified})
+	users.add(&domain.User{Username: "jiro", Email: "jiro@example.com", EmailStatus: domain.EmailPending})
+	store := &fakeOIDCStore{requests: map[string]*domain.OIDCAuthRequest{}, users: users}
+	svc := NewOIDCService(store, users, []OIDCProviderOptions{{
+		Name:        "test",
+		DisplayName: "Test IdP",
+		OIDC:        oidc.Config{Issuer: idp.server.URL, ClientID: "client-1", RedirectURL: "https://app.example.com/callback"},
+		TrustEmail:  trustEmail,
+	}}, idp.server.Client())
+	return &oidcTest{t: t, idp: idp, store: store, users: users, svc: svc}
+}
+
+// loginは認可リクエストを開始し、IdPがoverrideのクレームのIDトークンを返すようにしてコールバックを呼び出す
+func (o *oidcTest) login(linkUserID, callerID *uint, override map[string]interface{}) (*OIDCResult, error) {
+	o.t.Helper()
+	raw, err := o.svc.Authorize(context.Background(), "test", linkUserID)
+	if err != nil {
+		o.t.Fatalf("Authorize: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		o.t.Fatal(err)
+	}
+	q := u.Query()
+	o.idp.setClaims(q.Get("nonce"), override)
+	return o.svc.Callback(context.Background(), "test", q.Get("state"), "code-1", callerID)
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestOIDCCallbackLogin(t *testing.T) {
+	tests := []struct {
+		name        string
+		trustEmail  bool
+		override    map[string]interface{}
+		wantErr     error
+		wantUserID  uint
+		wantCreated bool
+	}{
+		{name: "new user", wantUserID: 3, wantCreated: true},
+		{name: "email_verified as string", override: map[string]interface{}{"email_verified": "true"}, wantUserID: 3, wantCreated: true},
+		{name: "email_verified=false", override: map[string]interface{}{"email_verified": false}, wantErr: ErrOIDCEmailNotVerified},
+		{name: "email_verified missing", override: map[string]interface{}{"email_verified": nil}, wantErr: ErrOIDCEmailNotVerified},
+		{name: "email_verified missing with trust_email", trustEmail: true, override: map[string]interface{}{"email_verified": nil}, wantUserID: 3, wantCreated: true},
+		// trust_emailでもIdPが未確認とした場合は信用しない
+		{name: "email_verified=false with trust_email", trustEmail: true, override: map[string]interface{}{"email_verified": false}, wantErr: ErrOIDCEmailNotVerified},
+		{name: "no email", override: map[string]interface{}{"email": nil}, wantErr: ErrOIDCEmailNotVerified},
+		{name: "link by email to a verified user", override: map[string]interface{}{"email": "hanako@example.com"}, wantUserID: 1},
+		{name: "email of an unverified user", override: map[string]interface{}{"email": "jiro@example.com"}, wantErr: ErrOIDCEmailConflict},
+		{name: "wrong nonce", override: map[string]interface{}{"nonce": "other"}, wantErr: ErrOIDCLoginFailed},
+		{name: "wrong audience", override: map[string]interface{}{"aud": "client-2"}, wantErr: ErrOIDCLoginFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOIDCTest(t, tt.trustEmail)
+			res, err := o.login(nil, nil, tt.override)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Callback = %v, want %v", err, tt.wantErr)
+				}
+				if len(o.store.identities) != 0 || len(o.users.users) != 2 {
+					t.Errorf("identities = %d, users = %d after a failed login", len(o.store.identities), len(o.users.users))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Callback: %v", err)
+			}
+			if res.User.ID != tt.wantUserID || res.Created != tt.wantCreated || res.Linked {
+				t.Errorf("result = user %d, created %v, linked %v", res.User.ID, res.Created, res.Linked)
+			}
+			identity, err := o.store.FindIdentity("test", "sub-1")
+			if err != nil || identity.UserID != tt.wantUserID {
+				t.Errorf("identity = %+v, %v", identity, err)
+			}
+			if tt.wantCreated {
+				u := res.User
+				if u.Username != "Taro" || u.Email != "taro@example.com" || !u.IsEmailVerified() || u.Role != domain.RoleUser || u.PasswordHash == "" {
+					t.Errorf("created user = %+v", u)
+				}
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackLinkedIdentity(t *testing.T) {
+	o := newOIDCTest(t, false)
+	o.store.CreateIdentity(&domain.UserIdentity{UserID: 2, Provider: "test", Subject: "sub-1"})
+	// 紐付け済みならメールアドレス（未確認・別のアドレス）に関係なくそのユーザーでログイン
+	res, err := o.login(nil, nil, map[string]interface{}{"email": "other@example.com", "email_verified": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.User.ID != 2 || res.Created || res.Linked {
+		t.Errorf("result = user %d, created %v, linked %v", res.User.ID, res.Created, res.Linked)
+	}
+	if len(o.store.touched) != 1 {
+		t.Errorf("TouchIdentity called %d times, want 1", len(o.store.touched))
+	}
+}
+
+func TestOIDCCallbackExplicitLink(t *testing.T) {
+	t.Run("links regardless of email", func(t *testing.T) {
+		o := newOIDCTest(t, false)
+		// 未確認のユーザーでも、IdPのメールアドレスが未確認・別のアドレスでも紐付ける
+		res, err := o.login(uintPtr(2), uintPtr(2), map[string]interface{}{"email": "hanako@example.com", "email_verified": false})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.User.ID != 2 || !res.Linked || res.Created {
+			t.Errorf("result = user %d, created %v, linked %v", res.User.ID, res.Created, res.Linked)
+		}
+		if identity, err := o.store.FindIdentity("test", "sub-1"); err != nil || identity.UserID != 2 {
+			t.Errorf("identity = %+v, %v", identity, err)
+		}
+		// もう一度紐付けても変わらない
+		res, err = o.login(uintPtr(2), uintPtr(2), nil)
+		if err != nil || !res.Linked || len(o.store.identities) != 1 {
+			t.Errorf("second link = %+v, %v (%d identities)", res, err, len(o.store.identities))
+		}
+	})
+	t.Run("caller must be the user who started it", func(t *testing.T) {
+		o := newOIDCTest(t, false)
+		for _, caller := range []*uint{nil, uintPtr(1)} {
+			if _, err := o.login(uintPtr(2), caller, nil); !errors.Is(err, ErrInvalidOIDCState) {
+				t.Errorf("caller %v: Callback = %v, want ErrInvalidOIDCState", caller, err)
+			}
+		}
+		if len(o.store.identities) != 0 {
+			t.Errorf("%d identities created", len(o.store.identities))
+		}
+	})
+	t.Run("identity linked to another user", func(t *testing.T) {
+		o := newOIDCTest(t, false)
+		o.store.CreateIdentity(&domain.UserIdentity{UserID: 1, Provider: "test", Subject: "sub-1"})
+		if _, err := o.login(uintPtr(2), uintPtr(2), nil); !errors.Is(err, ErrOIDCIdentityLinked) {
+			t.Errorf("Callback = %v, want ErrOIDCIdentityLinked", err)
+		}
+	})
+}
+
+func TestOIDCCallbackState(t *testing.T) {
+	o := newOIDCTest(t, false)
+	ctx := context.Background()
+	raw, err := o.svc.Authorize(ctx, "test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(raw)
+	q := u.Query()
+	o.idp.setClaims(q.Get("nonce"), nil)
+
+	if _, err := o.svc.Callback(ctx, "test", "unknown-state", "code-1", nil); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("unknown state: %v", err)
+	}
+	if _, err := o.svc.Callback(ctx, "other", q.Get("state"), "code-1", nil); !errors.Is(err, ErrUnknownOIDCProvider) {
+		t.Errorf("unknown provider: %v", err)
+	}
+	if _, err := o.svc.Callback(ctx, "test", q.Get("state"), "code-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	// stateは1回のみ使用できる
+	if _, err := o.svc.Callback(ctx, "test", q.Get("state"), "code-1", nil); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("reused state: %v", err)
+	}
+	// 期限切れ
+	raw, _ = o.svc.Authorize(ctx, "test", nil)
+	u, _ = url.Parse(raw)
+	o.svc.now = func() time.Time { return time.Now().Add(oidcAuthRequestTTL + time.Second) }
+	if _, err := o.svc.Callback(ctx, "test", u.Query().Get("state"), "code-1", nil); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("expired state: %v", err)
+	}
+}
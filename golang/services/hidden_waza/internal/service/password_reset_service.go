@@ -0,0 +1,148 @@
+/*
+password_reset_service.go
+
+パスワードを忘れたユーザーのパスワード再設定。
+  - 再設定メールのリンクにはランダムなトークンを含め、DBにはSHA-256のハッシュのみを保存する（1回のみ・短時間で失効）
+  - アカウントの有無はレスポンスから分からないようにする（Forgotは常に成功を返す）
+  - 再設定時はユーザーのJWTの世代を増やし、それ以前に発行したJWTをすべて無効にする
+*/
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/requohylla/hidden-waza/pkg/config"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"gorm.io/gorm"
+)
+
+// パスワード再設定の既定値
+const (
+	DefaultPasswordResetURL = "http://localhost:3000/password/reset"
+	DefaultPasswordResetTTL = 30 * time.Minute
+)
+
+// 同じユーザーへの再設定メールの送信間隔（間隔内のリクエストは送信せずに成功を返す）
+const passwordResetCooldown = time.Minute
+
+var (
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+	ErrInvalidPassword   = errors.New("invalid password")
+)
+
+// PasswordResetOptionsはパスワード再設定の設定です。
+type PasswordResetOptions struct {
+	// 再設定メールのリンク先（?token=<トークン>を付与）
+	URL string
+	TTL time.Duration
+}
+
+// PasswordResetOptionsFromConfigは設定ファイルの値からパスワード再設定の設定を返します（未指定の項目は既定値）。
+func PasswordResetOptionsFromConfig(cfg *config.AuthConfig) PasswordResetOptions {
+	opts := PasswordResetOptions{URL: DefaultPasswordResetURL, TTL: DefaultPasswordResetTTL}
+	if cfg == nil {
+		return opts
+	}
+	pr := cfg.Auth.PasswordReset
+	if pr.URL != "" {
+		opts.URL = pr.URL
+	}
+	if pr.TTLMinutes > 0 {
+		opts.TTL = time.Duration(pr.TTLMinutes) * time.Minute
+	}
+	return opts
+}
+
+// PasswordResetServiceはパスワードの再設定を提供します。
+type PasswordResetService struct {
+	repo          *repository.PasswordResetRepository
+	users         *repository.UserRepository
+	notifications *NotificationService
+	opts          PasswordResetOptions
+	now           func() time.Time
+}
+
+// NewPasswordResetServiceはPasswordResetServiceを生成します。
+func NewPasswordResetService(repo *repository.PasswordResetRepository, users *repository.UserRepository, notifications *NotificationService, opts PasswordResetOptions) *PasswordResetService {
+	return &PasswordResetService{repo: repo, users: users, notifications: notifications, opts: opts, now: time.Now}
+}
+
+// Forgotはメールアドレスのユーザーにパスワード再設定のメールを送信します。
+// 該当するユーザーがいない場合・送信間隔内の場合も何もせずnilを返します（アカウントの有無を返さない）。
+func (s *PasswordResetService) Forgot(email domain.Email) error {
+	if !email.IsValid() {
+		return nil
+	}
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	last, err := s.repo.LatestToken(user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	now := s.now()
+	if last != nil && now.Before(last.CreatedAt.Add(passwordResetCooldown)) {
+		return nil
+	}
+
+	token, err := newResetToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := now.Add(s.opts.TTL)
+	if err := s.repo.IssueToken(&domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}); err != nil {
+		return err
+	}
+	return s.notifications.SendPasswordReset(user, s.link(token), expiresAt)
+}
+
+// Resetはトークンを検証してパスワードを変更し、それ以前に発行したJWTを無効にします。
+// パスワードが要件を満たさなければErrInvalidPassword（トークンは使用済みにしない）、
+// トークンが不正・期限切れ・使用済みならErrInvalidResetTokenを返します。
+func (s *PasswordResetService) Reset(token, password string) error {
+	hash, err := domain.NewPasswordHash(password)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPassword, err)
+	}
+	if _, err := s.repo.ResetPassword(hashToken(token), hash, s.now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	return nil
+}
+
+// 再設定メールのリンク
+func (s *PasswordResetService) link(token string) string {
+	sep := "?"
+	if strings.Contains(s.opts.URL, "?") {
+		sep = "&"
+	}
+	return s.opts.URL + sep + "token=" + url.QueryEscape(token)
+}
+
+// ランダムなトークン（32バイト、base64url）
+func newResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,207 @@
+/*
+rate_limiter.go
+
+APIのレート制限。
+  - クライアント（JWTのユーザー / パーソナルアクセストークン / 未認証ならIPアドレス）ごとにトークンバケットで制限する
+  - ルート（HTTPメソッド・登録時のパスのパターン）ごとにポリシーを設定でき、ポリシーごとに別のバケットを使う
+  - バケットの保存先はRateLimitStore（メモリ: 単一インスタンス用 / DB: 複数インスタンス構成用）
+*/
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/requohylla/hidden-waza/pkg/config"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"golang.org/x/time/rate"
+)
+
+// バケットが満杯になるまでの時間の上限（使われていないバケットの削除の基準）
+const maxRateLimitRefill = 24 * time.Hour
+
+// RateLimitStoreはトークンバケットの保存先です。
+type RateLimitStore interface {
+	// Takeはkeyのバケット（容量burst、1秒あたりperSecondずつ補充）からトークンを1つ取り出します。
+	Take(key string, perSecond float64, burst int, now time.Time) (*domain.RateLimitResult, error)
+}
+
+// RateLimitPolicyはルートごとのレート制限です。
+type RateLimitPolicy struct {
+	Name string
+	// HTTPメソッド（空なら全て）
+	Method string
+	// ルートのパスのパターン（末尾の*は前方一致）
+	Path      string
+	PerSecond float64
+	Burst     int
+}
+
+func (p RateLimitPolicy) matches(method, path string) bool {
+	if p.Method != "" && !strings.EqualFold(p.Method, method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(p.Path, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return p.Path == path
+}
+
+// Windowはバケットが空から満杯になるまでの時間を返します（RateLimit-Policyヘッダー用）。
+func (p RateLimitPolicy) Window() time.Duration {
+	return time.Duration(float64(p.Burst) / p.PerSecond * float64(time.Second))
+}
+
+// RateLimitOptionsはレート制限の設定です。
+type RateLimitOptions struct {
+	Enabled bool
+	// Policiesに該当しないルートの制限
+	Default RateLimitPolicy
+	// 上から順に最初に一致したものを使う
+	Policies []RateLimitPolicy
+}
+
+// DefaultRateLimitOptionsは既定の設定（config/rate_limit.yamlと同じ）を返します。
+func DefaultRateLimitOptions() RateLimitOptions {
+	perMinute := func(n float64) float64 { return n / 60 }
+	return RateLimitOptions{
+		Enabled: true,
+		Default: RateLimitPolicy{Name: "default", PerSecond: perMinute(300), Burst: 100},
+		Policies: []RateLimitPolicy{
+			{Name: "resume-list", Method: "GET", Path: "/api/v1/resume", PerSecond: perMinute(10), Burst: 5},
+			{Name: "export", Method: "GET", Path: "/api/v1/resume/:id/export/docx", PerSecond: perMinute(10), Burst: 5},
+			{Name: "analytics", Path: "/api/v1/analytics/*", PerSecond: perMinute(30), Burst: 10},
+			{Name: "auth", Method: "POST", Path: "/api/v1/login*", PerSecond: perMinute(20), Burst: 10},
+			{Name: "auth-mail", Method: "POST", Path: "/api/v1/password/forgot", PerSecond: perMinute(5), Burst: 3},
+			{Name: "signup", Method: "POST", Path: "/api/v1/signup*", PerSecond: perMinute(10), Burst: 5},
+		},
+	}
+}
+
+// RateLimitOptionsFromConfigは設定ファイルの値からレート制限の設定を返します（cfgがnilなら既定値）。
+func RateLimitOptionsFromConfig(cfg *config.RateLimitConfig) (RateLimitOptions, error) {
+	if cfg == nil {
+		return DefaultRateLimitOptions(), nil
+	}
+	rl := cfg.RateLimit
+	opts := RateLimitOptions{Enabled: rl.Enabled == nil || *rl.Enabled}
+	def := rl.Default
+	if def.Name == "" {
+		def.Name = "default"
+	}
+	var err error
+	if opts.Default, err = rateLimitPolicyFromConfig("rate_limit.default", def); err != nil {
+		return opts, err
+	}
+	if opts.Default.Method != "" || opts.Default.Path != "" {
+		return opts, fmt.Errorf("rate_limit.default: method and path are not allowed")
+	}
+	names := map[string]bool{opts.Default.Name: true}
+	for i, pc := range rl.Policies {
+		where := fmt.Sprintf("rate_limit.policies[%d]", i)
+		p, err := rateLimitPolicyFromConfig(where, pc)
+		if err != nil {
+			return opts, err
+		}
+		if p.Path == "" {
+			return opts, fmt.Errorf("%s: path is required", where)
+		}
+		if names[p.Name] {
+			return opts, fmt.Errorf("%s: duplicate name %q", where, p.Name)
+		}
+		names[p.Name] = true
+		opts.Policies = append(opts.Policies, p)
+	}
+	return opts, nil
+}
+
+func rateLimitPolicyFromConfig(where string, pc config.RateLimitPolicyConfig) (RateLimitPolicy, error) {
+	p := RateLimitPolicy{
+		Name:      strings.TrimSpace(pc.Name),
+		Method:    strings.ToUpper(strings.TrimSpace(pc.Method)),
+		Path:      strings.TrimSpace(pc.Path),
+		PerSecond: pc.RequestsPerMinute / 60,
+		Burst:     pc.Burst,
+	}
+	// 名前はバケットのキーに含めるため区切り文字を使えない
+	if p.Name == "" || strings.ContainsAny(p.Name, ": ") {
+		return p, fmt.Errorf("%s: invalid name %q", where, pc.Name)
+	}
+	if p.PerSecond <= 0 || p.Burst < 1 {
+		return p, fmt.Errorf("%s: requests_per_minute and burst must be positive", where)
+	}
+	if p.Window() > maxRateLimitRefill {
+		return p, fmt.Errorf("%s: burst / requests_per_minute must be at most %s", where, maxRateLimitRefill)
+	}
+	return p, nil
+}
+
+// RateLimiterはAPIのレート制限を提供します。
+type RateLimiter struct {
+	store RateLimitStore
+	opts  RateLimitOptions
+	now   func() time.Time
+}
+
+// NewRateLimiterはRateLimiterを生成します。
+func NewRateLimiter(store RateLimitStore, opts RateLimitOptions) *RateLimiter {
+	return &RateLimiter{store: store, opts: opts, now: time.Now}
+}
+
+func (l *RateLimiter) Enabled() bool {
+	return l.opts.Enabled
+}
+
+// Policyはルート（HTTPメソッドと登録時のパスのパターン）に適用するポリシーを返します。
+func (l *RateLimiter) Policy(method, path string) RateLimitPolicy {
+	for _, p := range l.opts.Policies {
+		if p.matches(method, path) {
+			return p
+		}
+	}
+	return l.opts.Default
+}
+
+// Takeはクライアント（"user:<ID>"・"token:<ID>"・"ip:<IPアドレス>"）のpolicyのバケットからトークンを1つ取り出します。
+func (l *RateLimiter) Take(policy RateLimitPolicy, client string) (*domain.RateLimitResult, error) {
+	return l.store.Take(policy.Name+":"+client, policy.PerSecond, policy.Burst, l.now())
+}
+
+// MemoryRateLimitStoreはプロセス内のメモリに保存するRateLimitStoreです（単一インスタンス用。再起動で消える）。
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*rate.Limiter
+	takes   int
+}
+
+// NewMemoryRateLimitStoreはMemoryRateLimitStoreを生成します。
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: map[string]*rate.Limiter{}}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, perSecond float64, burst int, now time.Time) (*domain.RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.takes++
+	if s.takes%1000 == 0 {
+		s.sweep(now)
+	}
+	lim, ok := s.buckets[key]
+	if !ok {
+		lim = rate.NewLimiter(rate.Limit(perSecond), burst)
+		s.buckets[key] = lim
+	}
+	allowed := lim.AllowN(now, 1)
+	return domain.NewRateLimitResult(allowed, lim.TokensAt(now), perSecond, burst), nil
+}
+
+// 満杯に戻ったバケットを削除（新しく作っても同じため。呼び出し側でロック済み）
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for key, lim := range s.buckets {
+		if lim.TokensAt(now) >= float64(lim.Burst()) {
+			delete(s.buckets, key)
+		}
+	}
+}
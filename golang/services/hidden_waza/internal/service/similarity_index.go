@@ -0,0 +1,269 @@
+/*
+similarity_index.go
+
+類似職務経歴書の検索に使うインメモリ索引。
+- 職務経歴書ごとにスキルベクトルと本文の語頻度を保持し、文書頻度（df）は追加・削除のたびに差分で更新
+- TF-IDFの重みは検索時点のdfで計算するため、索引の一部を入れ替えても全体の再構築は不要
+- 各職務経歴書の更新日時（version）を保持し、DB側の更新日時との差分で再読み込み対象を判定
+
+類似度:
+- スキル: 「種別:マスタID」を次元とし、重み = (1 + ln(1 + 年数)) × レベル係数（序数/4、未分類は0.5）のコサイン類似度
+- 本文: 概要・職歴（役職・業務内容）のTF-IDF（tf = 1 + ln(出現数), idf = ln((N + 1) / (df + 1)) + 1）のコサイン類似度
+- 総合: スキル0.6・本文0.4の加重平均（検索元にスキル・本文がない場合はその成分を除いて正規化）
+*/
+package service
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+)
+
+const (
+	skillSimilarityWeight = 0.6
+	textSimilarityWeight  = 0.4
+)
+
+// 索引に保持する職務経歴書1件分
+type similarityDoc struct {
+	version time.Time
+	userID  uint
+	title   string
+	skills  map[string]float64
+	terms   map[string]int
+}
+
+// SimilarityIndexは職務経歴書の類似度検索用の索引です（並行利用可）。
+type SimilarityIndex struct {
+	mu   sync.RWMutex
+	docs map[uint]*similarityDoc
+	df   map[string]int
+	// 次回の差分更新で必ず再読み込みする職務経歴書
+	dirty map[uint]bool
+}
+
+// NewSimilarityIndexは空の索引を生成します。
+func NewSimilarityIndex() *SimilarityIndex {
+	return &SimilarityIndex{
+		docs:  map[uint]*similarityDoc{},
+		df:    map[string]int{},
+		dirty: map[uint]bool{},
+	}
+}
+
+// Putは職務経歴書を索引に追加します（登録済みなら置き換え）。
+func (ix *SimilarityIndex) Put(resume *domain.Resume) {
+	doc := &similarityDoc{
+		version: resume.UpdatedAt,
+		userID:  resume.UserID,
+		title:   resume.Title,
+		skills:  skillVector(resume.Skills),
+		terms:   map[string]int{},
+	}
+	for _, t := range tokenize(resumeText(resume)) {
+		doc.terms[t]++
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(resume.ID)
+	ix.docs[resume.ID] = doc
+	for t := range doc.terms {
+		ix.df[t]++
+	}
+	delete(ix.dirty, resume.ID)
+}
+
+// Removeは職務経歴書を索引から削除します。
+func (ix *SimilarityIndex) Remove(resumeID uint) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(resumeID)
+	delete(ix.dirty, resumeID)
+}
+
+func (ix *SimilarityIndex) remove(resumeID uint) {
+	old, ok := ix.docs[resumeID]
+	if !ok {
+		return
+	}
+	for t := range old.terms {
+		if ix.df[t]--; ix.df[t] <= 0 {
+			delete(ix.df, t)
+		}
+	}
+	delete(ix.docs, resumeID)
+}
+
+// Invalidateは次回の差分更新で職務経歴書を再読み込みさせます
+// （更新日時の精度内で連続更新された場合の取りこぼし対策）。
+func (ix *SimilarityIndex) Invalidate(resumeID uint) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.dirty[resumeID] = true
+}
+
+// StaleはDB側の更新日時と比べて、再読み込みが必要なIDと削除すべきIDを返します。
+func (ix *SimilarityIndex) Stale(versions map[uint]time.Time) (changed, removed []uint) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	for id, v := range versions {
+		doc, ok := ix.docs[id]
+		if !ok || ix.dirty[id] || !doc.version.Equal(v) {
+			changed = append(changed, id)
+		}
+	}
+	for id := range ix.docs {
+		if _, ok := versions[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	return changed, removed
+}
+
+// Similarは指定職務経歴書に類似する職務経歴書を総合スコアの高い順にlimit件返します。
+// 索引に存在しなければokはfalseです。
+func (ix *SimilarityIndex) Similar(resumeID uint, limit int) (results []domain.SimilarResume, ok bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	base, ok := ix.docs[resumeID]
+	if !ok {
+		return nil, false
+	}
+	n := len(ix.docs)
+	baseText := ix.tfidf(base.terms, n)
+
+	skillWeight, textWeight := skillSimilarityWeight, textSimilarityWeight
+	if len(base.skills) == 0 {
+		skillWeight = 0
+	}
+	if len(baseText) == 0 {
+		textWeight = 0
+	}
+
+	results = []domain.SimilarResume{}
+	for id, doc := range ix.docs {
+		if id == resumeID {
+			continue
+		}
+		skillScore := cosine(base.skills, doc.skills)
+		textScore := cosine(baseText, ix.tfidf(doc.terms, n))
+		if skillScore == 0 && textScore == 0 {
+			continue
+		}
+		score := 0.0
+		if total := skillWeight + textWeight; total > 0 {
+			score = 100 * (skillWeight*skillScore + textWeight*textScore) / total
+		}
+		results = append(results, domain.SimilarResume{
+			ResumeID:     id,
+			UserID:       doc.userID,
+			Title:        doc.title,
+			Score:        round1(score),
+			SkillScore:   round2(skillScore),
+			TextScore:    round2(textScore),
+			SharedSkills: sharedSkills(base.skills, doc.skills),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.ResumeID < b.ResumeID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, true
+}
+
+// 語頻度をTF-IDFの重みに変換（nは索引の文書数）
+func (ix *SimilarityIndex) tfidf(terms map[string]int, n int) map[string]float64 {
+	w := make(map[string]float64, len(terms))
+	for t, c := range terms {
+		idf := math.Log(float64(n+1)/float64(ix.df[t]+1)) + 1
+		w[t] = (1 + math.Log(float64(c))) * idf
+	}
+	return w
+}
+
+// スキルベクトル（同一スキルの重複登録は重みの大きい方を採用）
+func skillVector(skills []domain.Skill) map[string]float64 {
+	v := map[string]float64{}
+	for ref, s := range bestSkills(skills) {
+		level := 0.5
+		if s.Level.IsValid() {
+			level = float64(s.Level.Ordinal()) / float64(len(domain.SkillLevels))
+		}
+		years := math.Max(0, float64(s.Years))
+		v[skillKey(ref)] = (1 + math.Log1p(years)) * level
+	}
+	return v
+}
+
+// 類似度計算の対象とする本文（概要・職歴の役職・業務内容）
+func resumeText(resume *domain.Resume) string {
+	parts := []string{resume.Summary}
+	for _, e := range resume.Experiences {
+		parts = append(parts, e.Position, e.Description)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func skillKey(ref skillRef) string {
+	return ref.skillType + ":" + strconv.FormatUint(uint64(ref.masterID), 10)
+}
+
+func parseSkillKey(key string) domain.SharedSkill {
+	skillType, id, _ := strings.Cut(key, ":")
+	masterID, _ := strconv.ParseUint(id, 10, 64)
+	return domain.SharedSkill{Type: skillType, MasterID: uint(masterID)}
+}
+
+// 両方に含まれるスキル（種別・マスタID順）
+func sharedSkills(a, b map[string]float64) []domain.SharedSkill {
+	shared := []domain.SharedSkill{}
+	for key := range a {
+		if _, ok := b[key]; ok {
+			shared = append(shared, parseSkillKey(key))
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if shared[i].Type != shared[j].Type {
+			return shared[i].Type < shared[j].Type
+		}
+		return shared[i].MasterID < shared[j].MasterID
+	})
+	return shared
+}
+
+func cosine(a, b map[string]float64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var dot float64
+	for k, x := range a {
+		dot += x * b[k]
+	}
+	if dot == 0 {
+		return 0
+	}
+	return dot / (norm(a) * norm(b))
+}
+
+func norm(v map[string]float64) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,68 @@
+// similarity_service.go: 類似職務経歴書の推薦（索引の差分更新と検索）
+//
+// 検索のたびにDBの更新日時一覧と索引を突き合わせ、追加・更新された職務経歴書だけを再読み込みし、
+// 削除されたものは索引から除きます（初回は全件読み込み）。
+package service
+
+import (
+	"sync"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+)
+
+// DefaultSimilarLimitは類似職務経歴書の既定件数
+const DefaultSimilarLimit = 10
+
+// SimilarityServiceは類似職務経歴書の推薦を提供します。
+type SimilarityService struct {
+	resumeRepo *repository.ResumeRepository
+	index      *SimilarityIndex
+	// 差分更新の直列化
+	refreshMu sync.Mutex
+}
+
+// NewSimilarityServiceはSimilarityServiceを生成します。
+func NewSimilarityService(resumeRepo *repository.ResumeRepository) *SimilarityService {
+	return &SimilarityService{resumeRepo: resumeRepo, index: NewSimilarityIndex()}
+}
+
+// Similarは指定IDの職務経歴書に類似する職務経歴書を上位limit件返します。
+func (s *SimilarityService) Similar(resumeID uint, limit int) ([]domain.SimilarResume, error) {
+	if err := s.Refresh(); err != nil {
+		return nil, err
+	}
+	results, ok := s.index.Similar(resumeID, limit)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return results, nil
+}
+
+// Refreshは索引をDBの内容に差分更新します。
+func (s *SimilarityService) Refresh() error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	versions, err := s.resumeRepo.Versions()
+	if err != nil {
+		return err
+	}
+	changed, removed := s.index.Stale(versions)
+	for _, id := range removed {
+		s.index.Remove(id)
+	}
+	resumes, err := s.resumeRepo.GetByIDs(changed)
+	if err != nil {
+		return err
+	}
+	for i := range resumes {
+		s.index.Put(&resumes[i])
+	}
+	return nil
+}
+
+// Invalidateは職務経歴書の更新を索引に通知します（次回の検索時に再読み込み）。
+func (s *SimilarityService) Invalidate(resumeID uint) {
+	s.index.Invalidate(resumeID)
+}
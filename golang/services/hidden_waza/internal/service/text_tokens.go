@@ -0,0 +1,69 @@
+// text_tokens.go: 類似度計算用の簡易トークナイザ（外部の形態素解析器を使わない）
+//
+// - 英数字の連続は小文字化した単語（1文字の語・ストップワードは除外）
+// - 漢字・カタカナの連続は文字bigram（1文字だけの連続はその1文字）
+// - ひらがな（助詞・送り仮名が大半）と記号は区切りとして扱う
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+var englishStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "that": true,
+	"this": true, "are": true, "was": true, "were": true, "has": true, "have": true,
+	"in": true, "on": true, "of": true, "to": true, "at": true, "by": true, "an": true,
+	"as": true, "is": true, "be": true, "or": true, "it": true,
+}
+
+type runeClass int
+
+const (
+	classOther runeClass = iota
+	classWord
+	classCJK
+)
+
+func classify(r rune) runeClass {
+	switch {
+	case unicode.Is(unicode.Han, r), unicode.Is(unicode.Katakana, r), r == 'ー':
+		return classCJK
+	case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)),
+		r == '+', r == '#':
+		// "C++" "C#" を1語として扱う
+		return classWord
+	case unicode.IsLetter(r) && !unicode.Is(unicode.Hiragana, r):
+		return classWord
+	}
+	return classOther
+}
+
+// tokenizeは文字列を類似度計算用のトークン列に分割します。
+func tokenize(s string) []string {
+	var tokens []string
+	runes := []rune(strings.ToLower(s))
+	for i := 0; i < len(runes); {
+		class := classify(runes[i])
+		j := i + 1
+		for j < len(runes) && classify(runes[j]) == class {
+			j++
+		}
+		run := runes[i:j]
+		switch class {
+		case classWord:
+			if w := string(run); len(run) > 1 && !englishStopWords[w] {
+				tokens = append(tokens, w)
+			}
+		case classCJK:
+			if len(run) == 1 {
+				tokens = append(tokens, string(run))
+			}
+			for k := 0; k+1 < len(run); k++ {
+				tokens = append(tokens, string(run[k:k+2]))
+			}
+		}
+		i = j
+	}
+	return tokens
+}
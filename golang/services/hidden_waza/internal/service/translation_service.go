@@ -0,0 +1,89 @@
+/*
+translation_service.go
+
+多言語（ja/en）翻訳の登録・取得に関する業務ロジックを集約するサービス。
+- 言語単位での翻訳登録/削除（他言語の翻訳には影響させない）
+- 職歴翻訳の対象職歴が指定Resumeに属しているかの検証
+- マスタ名称の表示言語での解決（翻訳がなければ元の名称）
+*/
+package service
+
+import (
+	"errors"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnsupportedLocaleは対応していない言語が指定された場合のエラー
+	ErrUnsupportedLocale = errors.New("unsupported locale")
+	// ErrInvalidTranslationは翻訳内容が不正な場合のエラー（他Resumeの職歴指定等）
+	ErrInvalidTranslation = errors.New("invalid translation")
+)
+
+// TranslationServiceは翻訳データの登録・解決を提供します。
+type TranslationService struct {
+	resumeRepo *repository.ResumeRepository
+	trRepo     *repository.TranslationRepository
+}
+
+// NewTranslationServiceはTranslationServiceを生成します。
+func NewTranslationService(resumeRepo *repository.ResumeRepository, trRepo *repository.TranslationRepository) *TranslationService {
+	return &TranslationService{resumeRepo: resumeRepo, trRepo: trRepo}
+}
+
+// SetResumeTranslationは指定言語のResume・職歴翻訳を登録/更新します。
+func (s *TranslationService) SetResumeTranslation(resumeID uint, loc domain.Locale, tr domain.ResumeTranslation, exps []domain.ExperienceTranslation) error {
+	if !loc.IsValid() {
+		return ErrUnsupportedLocale
+	}
+	resume, err := s.resumeRepo.GetByID(resumeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	owned := make(map[uint]bool, len(resume.Experiences))
+	for _, e := range resume.Experiences {
+		owned[e.ID] = true
+	}
+	for i := range exps {
+		if !owned[exps[i].ExperienceID] {
+			return ErrInvalidTranslation
+		}
+		exps[i].Locale = loc
+	}
+	tr.ResumeID = resumeID
+	tr.Locale = loc
+	return s.trRepo.UpsertResumeLocale(&tr, exps)
+}
+
+// DeleteResumeTranslationは指定言語のResume・職歴翻訳を削除します。
+func (s *TranslationService) DeleteResumeTranslation(resumeID uint, loc domain.Locale) error {
+	if !loc.IsValid() {
+		return ErrUnsupportedLocale
+	}
+	return s.trRepo.DeleteResumeLocale(resumeID, loc)
+}
+
+// SetMasterTranslationはマスタ名称の翻訳を登録/更新します。
+func (s *TranslationService) SetMasterTranslation(tr domain.MasterTranslation) error {
+	if !tr.Locale.IsValid() {
+		return ErrUnsupportedLocale
+	}
+	if !tr.IsValid() {
+		return ErrInvalidTranslation
+	}
+	return s.trRepo.UpsertMaster(&tr)
+}
+
+// DeleteMasterTranslationはマスタ名称の翻訳を削除します。
+func (s *TranslationService) DeleteMasterTranslation(masterType string, masterID uint, loc domain.Locale) error {
+	if !loc.IsValid() {
+		return ErrUnsupportedLocale
+	}
+	return s.trRepo.DeleteMaster(masterType, masterID, loc)
+}
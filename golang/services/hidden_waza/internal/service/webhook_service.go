@@ -0,0 +1,356 @@
+/*
+webhook_service.go
+
+Webhookの購読管理と配信。
+  - 職務経歴書の変更イベントはResumeRepositoryが同じトランザクションでアウトボックス（outbox_events）に記録する
+  - ワーカーがアウトボックスを定期的に確認し、購読しているWebhookごとに配信（webhook_deliveries）と配信ジョブを登録
+    （OnEventで登録した処理のジョブ（メール通知等）も同じトランザクションで登録）
+  - 配信ジョブ（webhook.deliver）は署名付きのJSONをPOSTし、2xx以外は失敗としてジョブキューの指数バックオフで再試行
+  - 各試行のリクエスト・レスポンスは配信ログ（webhook_delivery_attempts）に記録
+*/
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/domain"
+	"github.com/requohylla/hidden-waza/services/hidden_waza/internal/repository"
+	"gorm.io/gorm"
+)
+
+// WebhookDeliverJobTypeはWebhook配信ジョブの種別です。
+const WebhookDeliverJobType = "webhook.deliver"
+
+const (
+	webhookUserAgent = "hidden-waza-webhook/1.0"
+	// 配信ログに残すレスポンスボディの上限
+	maxWebhookLogBody = 4 << 10
+)
+
+var (
+	ErrInvalidWebhookURL    = errors.New("url must be an absolute http or https url")
+	ErrInvalidWebhookEvents = errors.New("events must be a non-empty list of known event types or \"*\"")
+)
+
+// WebhookOptionsは配信の設定です。
+type WebhookOptions struct {
+	Timeout     time.Duration // 1回の送信のタイムアウト
+	MaxAttempts int           // 配信ジョブの最大試行回数
+	RelayBatch  int           // アウトボックスから1回に取り出すイベント数
+}
+
+// DefaultWebhookOptionsは既定の設定を返します。
+func DefaultWebhookOptions() WebhookOptions {
+	return WebhookOptions{
+		Timeout:     10 * time.Second,
+		MaxAttempts: 8,
+		RelayBatch:  100,
+	}
+}
+
+// webhook.deliverジョブのペイロード
+type webhookDeliverPayload struct {
+	DeliveryID uint `json:"delivery_id"`
+}
+
+// WebhookServiceはWebhookの購読管理と配信を提供します。
+type WebhookService struct {
+	repo   *repository.WebhookRepository
+	opts   WebhookOptions
+	client *http.Client
+	now    func() time.Time
+	hooks  []func(ev *domain.OutboxEvent) ([]*domain.Job, error)
+}
+
+// NewWebhookServiceはWebhookServiceを生成します。
+func NewWebhookService(repo *repository.WebhookRepository, opts WebhookOptions) *WebhookService {
+	return &WebhookService{
+		repo: repo,
+		opts: opts,
+		client: &http.Client{
+			Timeout: opts.Timeout,
+			// リダイレクトは追わず3xxを失敗として扱う（送信先の設定ミスに気づけるように）
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		now: time.Now,
+	}
+}
+
+// CreateSubscriptionは購読を登録します。secretが空なら署名の鍵を生成します。
+func (s *WebhookService) CreateSubscription(sub *domain.WebhookSubscription, events []string) error {
+	if err := normalizeSubscription(sub, events); err != nil {
+		return err
+	}
+	if sub.Secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+		sub.Secret = "whsec_" + hex.EncodeToString(b)
+	}
+	return s.repo.CreateSubscription(sub)
+}
+
+// UpdateSubscriptionは購読を更新します（署名の鍵は変更しない）。
+func (s *WebhookService) UpdateSubscription(sub *domain.WebhookSubscription, events []string) (*domain.WebhookSubscription, error) {
+	if _, err := s.GetSubscription(sub.ID); err != nil {
+		return nil, err
+	}
+	if err := normalizeSubscription(sub, events); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateSubscription(sub); err != nil {
+		return nil, err
+	}
+	return s.GetSubscription(sub.ID)
+}
+
+func (s *WebhookService) GetSubscription(id uint) (*domain.WebhookSubscription, error) {
+	sub, err := s.repo.GetSubscription(id)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return sub, nil
+}
+
+func (s *WebhookService) Subscriptions() ([]domain.WebhookSubscription, error) {
+	return s.repo.Subscriptions()
+}
+
+// DeleteSubscriptionは購読を配信ログとともに削除します。
+func (s *WebhookService) DeleteSubscription(id uint) error {
+	if _, err := s.GetSubscription(id); err != nil {
+		return err
+	}
+	return s.repo.DeleteSubscription(id)
+}
+
+// Deliveriesは購読の配信を新しい順に返します。
+func (s *WebhookService) Deliveries(subscriptionID uint, limit, offset int) ([]domain.WebhookDelivery, error) {
+	if _, err := s.GetSubscription(subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.repo.DeliveriesBySubscription(subscriptionID, limit, offset)
+}
+
+// Deliveryは配信を配信ログとともに返します。
+func (s *WebhookService) Delivery(id uint) (*domain.WebhookDelivery, error) {
+	d, err := s.repo.GetDelivery(id, true)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return d, nil
+}
+
+// Redeliverは配信と同じイベントを新しい配信として再送します。
+func (s *WebhookService) Redeliver(id uint) (*domain.WebhookDelivery, error) {
+	d, err := s.repo.GetDelivery(id, false)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return s.repo.Redeliver(d, s.newDeliverJob)
+}
+
+// OnEventはアウトボックスのイベントごとに登録するジョブを返す処理を追加します（RunRelay前に呼び出す）。
+func (s *WebhookService) OnEvent(fn func(ev *domain.OutboxEvent) ([]*domain.Job, error)) {
+	s.hooks = append(s.hooks, fn)
+}
+
+func (s *WebhookService) eventJobs(ev *domain.OutboxEvent) ([]*domain.Job, error) {
+	var jobs []*domain.Job
+	for _, fn := range s.hooks {
+		j, err := fn(ev)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j...)
+	}
+	return jobs, nil
+}
+
+// RelayOutboxは未配信のイベントがなくなるまで配信を登録し、処理したイベント件数を返します。
+func (s *WebhookService) RelayOutbox(ctx context.Context) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		n, err := s.repo.DispatchOutbox(s.opts.RelayBatch, s.now(), s.newDeliverJob, s.eventJobs)
+		total += n
+		if err != nil || n < s.opts.RelayBatch {
+			return total, err
+		}
+	}
+	return total, ctx.Err()
+}
+
+// RunRelayはctxがキャンセルされるまでintervalごとにアウトボックスを確認します（ワーカーで起動）。
+func (s *WebhookService) RunRelay(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.RelayOutbox(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("webhook relay: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *WebhookService) newDeliverJob(d *domain.WebhookDelivery) (*domain.Job, error) {
+	return newJob(WebhookDeliverJobType, webhookDeliverPayload{DeliveryID: d.ID}, EnqueueOptions{MaxAttempts: s.opts.MaxAttempts}, s.now())
+}
+
+// Deliverはwebhook.deliverジョブのハンドラです。
+// 送信に失敗した場合はエラーを返してジョブキューに再試行させます。
+func (s *WebhookService) Deliver(ctx context.Context, job *domain.Job) error {
+	var p webhookDeliverPayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanentJob, err)
+	}
+	d, err := s.repo.GetDelivery(p.DeliveryID, false)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 購読とともに削除された
+			return nil
+		}
+		return err
+	}
+	if d.Status != domain.DeliveryPending {
+		return nil
+	}
+	sub, err := s.repo.GetSubscription(d.SubscriptionID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if sub == nil || !sub.Active {
+		return s.repo.FailDelivery(d.ID, "subscription is inactive", s.now())
+	}
+	ev, err := s.repo.GetEvent(d.EventID)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(NewEventEnvelope(ev))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanentJob, err)
+	}
+
+	attempt, sendErr := s.send(ctx, sub, d, body)
+	attempt.Attempt = d.Attempts + 1
+	status := domain.DeliveryPending
+	switch {
+	case sendErr == nil:
+		status = domain.DeliverySucceeded
+	case errors.Is(sendErr, ErrPermanentJob) || job.Attempts >= job.MaxAttempts:
+		status = domain.DeliveryFailed
+	}
+	if err := s.repo.RecordAttempt(d, attempt, status, s.now()); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// 署名付きでPOSTし、試行結果（配信ログ）を返す
+func (s *WebhookService) send(ctx context.Context, sub *domain.WebhookSubscription, d *domain.WebhookDelivery, body []byte) (*domain.WebhookDeliveryAttempt, error) {
+	attempt := &domain.WebhookDeliveryAttempt{DeliveryID: d.ID, RequestBody: string(body)}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, fmt.Errorf("%w: %v", ErrPermanentJob, err)
+	}
+	ts := s.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set("X-Webhook-Event", d.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(ts, 10))
+	req.Header.Set("X-Webhook-Signature", SignWebhookPayload(sub.Secret, ts, body))
+	attempt.RequestHeaders = formatHeaders(req.Header)
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	attempt.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookLogBody))
+	attempt.StatusCode = resp.StatusCode
+	attempt.ResponseHeaders = formatHeaders(resp.Header)
+	attempt.ResponseBody = strings.ToValidUTF8(string(b), string(utf8.RuneError))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("unexpected status %d", resp.StatusCode)
+		attempt.Error = err.Error()
+		return attempt, err
+	}
+	return attempt, nil
+}
+
+// 購読の入力を検証して整形する（eventsは重複を除いてカンマ区切りで保存）
+func normalizeSubscription(sub *domain.WebhookSubscription, events []string) error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	seen := map[string]bool{}
+	list := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if e != domain.AllEvents && !domain.IsValidEventType(e) {
+			return ErrInvalidWebhookEvents
+		}
+		if !seen[e] {
+			seen[e] = true
+			list = append(list, e)
+		}
+	}
+	if len(list) == 0 {
+		return ErrInvalidWebhookEvents
+	}
+	if seen[domain.AllEvents] {
+		list = []string{domain.AllEvents}
+	}
+	sort.Strings(list)
+	sub.Events = strings.Join(list, ",")
+	sub.Description = truncateRunes(sub.Description, 255)
+	return nil
+}
+
+// 配信ログ用のヘッダー（"Key: value" の行）
+func formatHeaders(h http.Header) string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		for _, v := range h[k] {
+			fmt.Fprintf(&b, "%s: %s\n", k, v)
+		}
+	}
+	return b.String()
+}
+
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
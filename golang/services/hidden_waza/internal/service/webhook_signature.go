@@ -0,0 +1,39 @@
+// webhook_signature.go: Webhookのペイロード署名（HMAC-SHA256）
+//
+// 署名対象は "<X-Webhook-Timestamp>.<リクエストボディ>" で、X-Webhook-Signature に "sha256=<16進>" として付与します。
+// 受信側は同じ鍵で署名を計算して比較し、タイムスタンプが古すぎるリクエストは拒否してください（リプレイ対策）。
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const webhookSignaturePrefix = "sha256="
+
+// SignWebhookPayloadはタイムスタンプ（Unix秒）とボディの署名（X-Webhook-Signatureの値）を返します。
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignatureは受信したWebhookの署名を検証します（受信側の実装例・テスト用）。
+// タイムスタンプがnowからtolerance以上ずれている場合も失敗にします。
+func VerifyWebhookSignature(secret, timestamp, signature string, body []byte, tolerance time.Duration, now time.Time) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || !strings.HasPrefix(signature, webhookSignaturePrefix) {
+		return false
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return false
+	}
+	expected := SignWebhookPayload(secret, ts, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
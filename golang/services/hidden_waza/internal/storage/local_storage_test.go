@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocalStorage(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := s.Put(ctx, "resumes/1/a", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := s.Open(ctx, "resumes/1/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(body) != "hello" {
+		t.Errorf("Open = %q", body)
+	}
+
+	// sizeと内容の長さが違えば保存しない（一時ファイルも残さない）
+	if err := s.Put(ctx, "resumes/1/b", strings.NewReader("hello"), 4, ""); err == nil {
+		t.Error("Put with wrong size succeeded")
+	}
+	entries, _ := os.ReadDir(filepath.Join(root, "resumes", "1"))
+	if len(entries) != 1 {
+		t.Errorf("files after failed Put: %v", entries)
+	}
+
+	if err := s.Delete(ctx, "resumes/1/a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Open(ctx, "resumes/1/a"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Open deleted: %v", err)
+	}
+	if err := s.Delete(ctx, "resumes/1/a"); err != nil {
+		t.Errorf("Delete missing: %v", err)
+	}
+	if _, err := s.Open(ctx, "../outside"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Open outside root: %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+// s3_storage.go: S3互換オブジェクトストレージ（AWS S3 / MinIO等）
+//
+// REST API（PutObject / GetObject / DeleteObject）をSigV4署名付きで直接呼び出す。
+// MinIO等ではバケットをパスに含める path-style（http://host:9000/bucket/key）を使う。
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Configは S3Storage の接続設定です。
+type S3Config struct {
+	Endpoint     string // 例: "https://s3.ap-northeast-1.amazonaws.com", "http://localhost:9000"
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	UsePathStyle bool
+}
+
+// S3StorageはS3互換ストレージにオブジェクトを保存します。
+type S3Storage struct {
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	creds     sigV4Credentials
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewS3StorageはS3Storageを生成します。
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("s3 storage: invalid endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 storage: bucket is required")
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3Storage{
+		endpoint:  u,
+		bucket:    cfg.Bucket,
+		pathStyle: cfg.UsePathStyle,
+		creds:     sigV4Credentials{accessKey: cfg.AccessKey, secretKey: cfg.SecretKey, region: region},
+		client:    &http.Client{Timeout: 5 * time.Minute},
+		now:       time.Now,
+	}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), io.LimitReader(r, size))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err == ErrObjectNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PresignGetはexpiresの間有効なGetObjectのURLを返します。
+func (s *S3Storage) PresignGet(key string, expires time.Duration, filename string) (string, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	if filename != "" {
+		q := u.Query()
+		q.Set("response-content-disposition", ContentDisposition(filename))
+		u.RawQuery = q.Encode()
+	}
+	return s.creds.presign(http.MethodGet, u, expires, s.now()), nil
+}
+
+// 署名して送信し、2xx以外はエラーにする（404はErrObjectNotFound）
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	s.creds.signRequest(req, s.now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("s3 storage: %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// キーのオブジェクトURL（path-style: endpoint/bucket/key、virtual-hosted: bucket.endpoint/key）
+func (s *S3Storage) objectURL(key string) (*url.URL, error) {
+	if !validKey(key) {
+		return nil, ErrInvalidKey
+	}
+	u := *s.endpoint
+	base := strings.TrimSuffix(u.Path, "/")
+	if s.pathStyle {
+		u.Path = base + "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = base + "/" + key
+	}
+	// 署名に使うエンコード済みパスと送信時のパスを一致させる
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = ""
+	return &u, nil
+}
+
+// ContentDispositionはダウンロード用のContent-Disposition値を返します（RFC 6266、非ASCIIはfilename*）。
+func ContentDisposition(filename string) string {
+	ascii := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+	return "attachment; filename=" + strconv.Quote(ascii) + "; filename*=UTF-8''" + uriEncode(filename, true)
+}
@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// テスト用のS3互換サーバー（path-style、署名を検証する）
+type fakeS3 struct {
+	creds  sigV4Credentials
+	mu     sync.Mutex
+	bucket string
+	// キー（エンコード前）→ 内容
+	objects      map[string]string
+	contentTypes map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !f.validSignature(r) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket+"/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "<Error><Code>NoSuchBucket</Code></Error>")
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[key] = string(body)
+		f.contentTypes[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		io.WriteString(w, body)
+	case http.MethodDelete:
+		if _, ok := f.objects[key]; !ok {
+			// MinIO等は存在しないキーの削除にも204を返すが、404を返す実装もある
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// 受け取ったリクエスト（送信されたエンコード済みパス）から署名を計算し直して照合
+func (f *fakeS3) validSignature(r *http.Request) bool {
+	date, err := time.Parse(amzDateFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	canonical := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		canonicalQuery(r.URL.Query()),
+		"host:" + r.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + r.Header.Get("X-Amz-Date") + "\n",
+		"host;x-amz-content-sha256;x-amz-date",
+		payloadHash,
+	}, "\n")
+	want := sigV4Algorithm +
+		" Credential=" + f.creds.accessKey + "/" + f.creds.scope(date) +
+		", SignedHeaders=host;x-amz-content-sha256;x-amz-date" +
+		", Signature=" + f.creds.signature(date, canonical)
+	return r.Header.Get("Authorization") == want
+}
+
+func newFakeS3(t *testing.T) (*S3Storage, *fakeS3) {
+	t.Helper()
+	fake := &fakeS3{
+		creds:        sigV4Credentials{accessKey: "minio", secretKey: "minio-secret", region: "ap-northeast-1"},
+		bucket:       "attachments",
+		objects:      map[string]string{},
+		contentTypes: map[string]string{},
+	}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	s, err := NewS3Storage(S3Config{
+		Endpoint:     srv.URL,
+		Region:       "ap-northeast-1",
+		Bucket:       "attachments",
+		AccessKey:    "minio",
+		SecretKey:    "minio-secret",
+		UsePathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, fake
+}
+
+func TestS3StoragePutOpenDelete(t *testing.T) {
+	s, fake := newFakeS3(t)
+	ctx := context.Background()
+	// エンコードが必要な文字を含むキーでも、署名したパスと送信したパスが一致する
+	key := "resumes/1/報告書 v2+final.pdf"
+	if err := s.Put(ctx, key, strings.NewReader("%PDF-1.4 body"), 13, "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.objects[key]; got != "%PDF-1.4 body" {
+		t.Errorf("stored %q", got)
+	}
+	if got := fake.contentTypes[key]; got != "application/pdf" {
+		t.Errorf("content type %q", got)
+	}
+
+	rc, err := s.Open(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(body) != "%PDF-1.4 body" {
+		t.Errorf("Open = %q", body)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fake.objects[key]; ok {
+		t.Error("object not deleted")
+	}
+	// 存在しないキーの削除はエラーにしない
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete missing: %v", err)
+	}
+}
+
+// sizeを超える内容は送らない
+func TestS3StoragePutLimitsSize(t *testing.T) {
+	s, fake := newFakeS3(t)
+	if err := s.Put(context.Background(), "resumes/1/a", strings.NewReader("0123456789"), 4, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.objects["resumes/1/a"]; got != "0123" {
+		t.Errorf("stored %q", got)
+	}
+}
+
+func TestS3StorageOpenNotFound(t *testing.T) {
+	s, _ := newFakeS3(t)
+	if _, err := s.Open(context.Background(), "resumes/1/missing"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Open missing: %v", err)
+	}
+}
+
+// 404以外のエラーはステータスと本文を含むエラーにする
+func TestS3StorageErrorResponse(t *testing.T) {
+	s, _ := newFakeS3(t)
+	s.creds.secretKey = "wrong"
+	err := s.Put(context.Background(), "resumes/1/a", strings.NewReader("x"), 1, "")
+	if err == nil || errors.Is(err, ErrObjectNotFound) ||
+		!strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("Put with wrong secret: %v", err)
+	}
+}
+
+func TestS3StorageInvalidKey(t *testing.T) {
+	s, _ := newFakeS3(t)
+	for _, key := range []string{"", "/abs", "a/../b", "a/./b", `a\b`} {
+		if err := s.Put(context.Background(), key, strings.NewReader("x"), 1, ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q): %v", key, err)
+		}
+	}
+}
+
+func TestS3StorageObjectURL(t *testing.T) {
+	virtual, err := NewS3Storage(S3Config{Endpoint: "https://s3.ap-northeast-1.amazonaws.com", Bucket: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := virtual.objectURL("resumes/1/a b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.String(); got != "https://b.s3.ap-northeast-1.amazonaws.com/resumes/1/a%20b" {
+		t.Errorf("virtual-hosted URL = %s", got)
+	}
+
+	pathStyle, err := NewS3Storage(S3Config{Endpoint: "http://localhost:9000/", Bucket: "b", UsePathStyle: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err = pathStyle.objectURL("resumes/1/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.String(); got != "http://localhost:9000/b/resumes/1/a" {
+		t.Errorf("path-style URL = %s", got)
+	}
+
+	for _, cfg := range []S3Config{{Endpoint: "localhost:9000", Bucket: "b"}, {Endpoint: "http://localhost:9000"}} {
+		if _, err := NewS3Storage(cfg); err == nil {
+			t.Errorf("NewS3Storage(%+v) succeeded", cfg)
+		}
+	}
+}
+
+// 期限付きURLにファイル名（Content-Disposition）を含めて署名する
+func TestS3StoragePresignGet(t *testing.T) {
+	s, err := NewS3Storage(S3Config{Endpoint: "http://localhost:9000", Bucket: "b", AccessKey: "k", SecretKey: "s", UsePathStyle: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return exampleTime }
+	signed, err := s.PresignGet("resumes/1/a", 15*time.Minute, "職務経歴書.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("X-Amz-Expires") != "900" || q.Get("X-Amz-Credential") != "k/20130524/us-east-1/s3/aws4_request" {
+		t.Errorf("query = %v", q)
+	}
+	if got := q.Get("response-content-disposition"); got != ContentDisposition("職務経歴書.pdf") {
+		t.Errorf("response-content-disposition = %q", got)
+	}
+	// 署名を除いたクエリから署名を計算し直す
+	sig := q.Get("X-Amz-Signature")
+	q.Del("X-Amz-Signature")
+	canonical := strings.Join([]string{"GET", u.EscapedPath(), canonicalQuery(q), "host:" + u.Host + "\n", "host", unsignedPayload}, "\n")
+	if want := s.creds.signature(exampleTime, canonical); sig != want {
+		t.Errorf("signature = %s, want %s", sig, want)
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	got := ContentDisposition(`職務"経歴書.pdf`)
+	want := `attachment; filename="______.pdf"; filename*=UTF-8''%E8%81%B7%E5%8B%99%22%E7%B5%8C%E6%AD%B4%E6%9B%B8.pdf`
+	if got != want {
+		t.Errorf("ContentDisposition = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+storage.go
+
+添付ファイル等のオブジェクトを保存するストレージの抽象化。
+- [`LocalStorage`](services/hidden_waza/internal/storage/local_storage.go): ローカルファイルシステム
+- [`S3Storage`](services/hidden_waza/internal/storage/s3_storage.go): S3互換オブジェクトストレージ（AWS S3 / MinIO等）
+
+キーは"/"区切りの相対パス（例: "resumes/1/3f2a..."）で、".."や絶対パスは使えません。
+*/
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrObjectNotFoundは指定キーのオブジェクトが存在しない場合のエラー
+	ErrObjectNotFound = errors.New("object not found")
+	// ErrInvalidKeyはキーが不正（空・絶対パス・".."を含む等）な場合のエラー
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Storageはオブジェクトの保存・取得・削除を提供します。
+type Storage interface {
+	// Putはrの内容（sizeバイト）をkeyに保存します。
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Openはkeyの内容を読み出します（存在しなければErrObjectNotFound）。
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Deleteはkeyを削除します（存在しなくてもエラーにしない）。
+	Delete(ctx context.Context, key string) error
+}
+
+// Presignerは期限付きの直接ダウンロードURLを発行できるストレージです（S3互換のみ）。
+type Presigner interface {
+	// PresignGetはexpiresの間有効なダウンロードURLを返します（filenameはダウンロード時のファイル名）。
+	PresignGet(key string, expires time.Duration, filename string) (string, error)
+}
+
+// キーの検証（"/"区切りの相対パスのみ許可）
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	if path.Clean(key) != key {
+		return false
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == ".." || seg == "." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+/*
+totp.go
+
+RFC 6238 TOTP（時間ベースのワンタイムパスワード）。
+Google Authenticator等の認証アプリと互換の次の設定のみ対応します。
+  - HMAC-SHA1、6桁、30秒間隔
+  - 秘密鍵は20バイトの乱数をBase32（パディングなし）で表したもの
+*/
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// 時間の間隔（秒）
+	Period = 30
+	// コードの桁数
+	Digits = 6
+	// 秘密鍵のバイト数（HMAC-SHA1の出力長）
+	secretSize = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecretはランダムな秘密鍵（Base32）を生成します。
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// Stepは時刻tの時間ステップ（Unix秒 / 30）を返します。
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// Codeは秘密鍵と時間ステップからコード（6桁、先頭0埋め）を生成します。
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return "", fmt.Errorf("totp: invalid secret: %w", err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	m := hmac.New(sha1.New, key)
+	m.Write(msg[:])
+	sum := m.Sum(nil)
+	// 動的切り捨て（RFC 4226 5.3）
+	offset := sum[len(sum)-1] & 0x0f
+	v := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, v%1000000), nil
+}
+
+// Validateはコードが時刻tの前後skewステップ以内のいずれかと一致すれば、一致した時間ステップを返します。
+// afterStep以前の時間ステップは一致しても受け付けません（使用済みのコードの再利用を防ぐ）。
+func Validate(secret, code string, t time.Time, skew int, afterStep int64) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false
+	}
+	now := Step(t)
+	for i := -skew; i <= skew; i++ {
+		step := now + int64(i)
+		if step <= afterStep {
+			continue
+		}
+		want, err := Code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// URIは認証アプリに登録するためのURI（otpauth://totp/<発行者>:<アカウント>?...）を返します。
+func URI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(Digits))
+	q.Set("period", fmt.Sprint(Period))
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
@@ -0,0 +1,129 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// RFC 6238 付録Bの秘密鍵（ASCII "12345678901234567890"）のBase32表現
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// RFC 6238 付録BのSHA-1のテストベクタ。付録Bは8桁なので、下6桁と比較する。
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "94287082"},
+	{1111111109, "07081804"},
+	{1111111111, "14050471"},
+	{1234567890, "89005924"},
+	{2000000000, "69279037"},
+	{20000000000, "65353130"},
+}
+
+func TestCodeRFC6238(t *testing.T) {
+	for _, v := range rfcVectors {
+		got, err := Code(rfcSecret, Step(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := v.code[len(v.code)-Digits:]; got != want {
+			t.Errorf("T=%d: code = %s, want %s", v.unix, got, want)
+		}
+	}
+	// 小文字・前後の空白は許容する
+	if got, _ := Code(" gezdgnbvgy3tqojqgezdgnbvgy3tqojq ", Step(time.Unix(59, 0))); got != "287082" {
+		t.Errorf("lower-case secret: code = %s, want 287082", got)
+	}
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code with an invalid secret succeeded")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+	prev, _ := Code(rfcSecret, step-1)
+	cur, _ := Code(rfcSecret, step)
+	next, _ := Code(rfcSecret, step+1)
+	far, _ := Code(rfcSecret, step+2)
+
+	tests := []struct {
+		name      string
+		code      string
+		afterStep int64
+		wantStep  int64
+		wantOK    bool
+	}{
+		{"current", cur, 0, step, true},
+		{"previous step within skew", prev, 0, step - 1, true},
+		{"next step within skew", next, 0, step + 1, true},
+		{"outside skew", far, 0, 0, false},
+		{"surrounding spaces", " " + cur + " ", 0, step, true},
+		{"already used", cur, step, 0, false},
+		{"earlier step already used", prev, step - 1, 0, false},
+		{"later step after used", next, step, step + 1, true},
+		{"wrong length", cur[:Digits-1], 0, 0, false},
+		{"wrong code", "000000", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := Validate(rfcSecret, tt.code, now, 1, tt.afterStep)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("Validate = (%d, %v), want (%d, %v)", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+	if _, ok := Validate("not base32!", cur, now, 1, 0); ok {
+		t.Error("Validate with an invalid secret succeeded")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Error("GenerateSecret returned the same secret twice")
+	}
+	key, err := encoding.DecodeString(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != secretSize {
+		t.Errorf("secret is %d bytes, want %d", len(key), secretSize)
+	}
+}
+
+func TestURI(t *testing.T) {
+	raw := URI("hidden waza", "taro@example.com", rfcSecret)
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Errorf("URI = %s", raw)
+	}
+	if u.Path != "/hidden waza:taro@example.com" {
+		t.Errorf("label = %q", u.Path)
+	}
+	q := u.Query()
+	want := map[string]string{
+		"secret":    rfcSecret,
+		"issuer":    "hidden waza",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+}